@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	react "code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	svelte "code.houdinigraphql.com/packages/houdini-svelte/plugin"
+	svelteConfig "code.houdinigraphql.com/packages/houdini-svelte/plugin/config"
+	"code.houdinigraphql.com/plugins"
+)
+
+// generateProject runs codegen without node. The project is described by a json or yaml
+// config file (see plugins.ConfigFile) and every plugin runs inside this process, so a
+// backend that only has go installed can still check its documents against the schema.
+func generateProject(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	configPath := flags.String("config", ".", "the config file, or the directory that holds it")
+	databasePath := flags.String("database", "", "keep the database at this path instead of in memory")
+	through := flags.String("through", "AfterGenerate", "the last hook to run. Validate only checks the documents")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(plugins.PipelineHooks, *through) {
+		return fmt.Errorf("%s is not a hook of the pipeline", *through)
+	}
+
+	configFile, err := plugins.LoadConfigFile(*configPath)
+	if err != nil {
+		return err
+	}
+
+	// plugins resolve relative paths against the working directory, like they do when the
+	// orchestrator starts them at the root of the project
+	if err := os.Chdir(configFile.RootDir); err != nil {
+		return err
+	}
+
+	host, err := plugins.NewHost(*databasePath)
+	if err != nil {
+		return err
+	}
+	defer host.Close()
+
+	ctx := context.Background()
+	if err := plugins.WriteConfigFile(ctx, host.Database(), configFile); err != nil {
+		return err
+	}
+
+	// the runtimes that get copied into the project come from the installed packages. they're
+	// only needed once the runtime is generated
+	needsRuntime := slices.Index(plugins.PipelineHooks, *through) >=
+		slices.Index(plugins.PipelineHooks, "GenerateRuntime")
+	directory := func(name string) (string, error) {
+		found := packageDirectory(configFile.RootDir, name)
+		if found == "" && needsRuntime {
+			return "", fmt.Errorf(
+				"could not find %s in node_modules. install it or pass -through Validate to only check documents",
+				name,
+			)
+		}
+		return found, nil
+	}
+
+	fs := afero.NewOsFs()
+	core := &plugin.HoudiniCore{}
+	core.SetFilesystem(fs)
+	coreDirectory, err := directory("houdini-core")
+	if err != nil {
+		return err
+	}
+	if err := plugins.HostPlugin[config.PluginConfig](host, core, coreDirectory); err != nil {
+		return err
+	}
+
+	for _, name := range configFile.PluginNames() {
+		pluginDirectory, err := directory(name)
+		if err != nil {
+			return err
+		}
+		if err := addFrameworkPlugin(host, fs, name, pluginDirectory, false); err != nil {
+			return err
+		}
+	}
+
+	if err := host.RunPipeline(ctx, plugins.PipelineOptions{Through: *through}); err != nil {
+		return located(err)
+	}
+
+	if *through == "AfterGenerate" {
+		fmt.Println("🎩 Generated the runtime for", configFile.RootDir)
+	} else {
+		fmt.Printf("🎩 Ran the pipeline through %s for %s\n", *through, configFile.RootDir)
+	}
+	return nil
+}
+
+// addFrameworkPlugin adds the framework plugin with the given name to the host. A database
+// that a build already registered the plugin in only needs it attached.
+func addFrameworkPlugin(host *plugins.Host, fs afero.Fs, name string, directory string, attach bool) error {
+	switch name {
+	case "houdini-react":
+		p := &react.HoudiniReact{}
+		p.SetFilesystem(fs)
+		return hostPlugin[reactConfig.PluginConfig](host, p, directory, attach)
+	case "houdini-svelte":
+		p := &svelte.HoudiniSvelte{}
+		p.SetFilesystem(fs)
+		return hostPlugin[svelteConfig.PluginConfig](host, p, directory, attach)
+	default:
+		return fmt.Errorf("%s can't run without node. only houdini-react and houdini-svelte can", name)
+	}
+}
+
+func hostPlugin[PluginConfig any](
+	host *plugins.Host,
+	p plugins.HoudiniPlugin[PluginConfig],
+	directory string,
+	attach bool,
+) error {
+	if attach {
+		plugins.AttachPlugin(host, p, directory)
+		return nil
+	}
+	return plugins.HostPlugin(host, p, directory)
+}
+
+// located puts the location of every error in front of its message so a ci log points to
+// the document that has to change
+func located(err error) error {
+	var list *plugins.ErrorList
+	if !errors.As(err, &list) {
+		return err
+	}
+
+	messages := []string{}
+	for _, item := range list.GetItems() {
+		message := item.Message
+		if len(item.Locations) > 0 && item.Locations[0].Filepath != "" {
+			location := item.Locations[0]
+			prefix := location.Filepath
+			if location.Line > 0 {
+				prefix = fmt.Sprintf("%s:%d:%d", prefix, location.Line, location.Column)
+			}
+			message = prefix + ": " + message
+		}
+		messages = append(messages, message)
+	}
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+// packageDirectory looks for an installed package the same way node does: in the
+// node_modules of the directory and each of its parents
+func packageDirectory(directory string, name string) string {
+	for {
+		candidate := filepath.Join(directory, "node_modules", name)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			// pnpm links packages into node_modules
+			if resolved, err := filepath.EvalSymlinks(candidate); err == nil {
+				return resolved
+			}
+			return candidate
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return ""
+		}
+		directory = parent
+	}
+}
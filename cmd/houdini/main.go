@@ -0,0 +1,30 @@
+// Command houdini runs codegen without node. Every plugin it knows about is compiled into the
+// binary and hosted in-process, which is why it lives outside of the plugin binaries: those
+// only ever contain their own plugin.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+var commands = plugins.Commands{
+	"generate": generateProject,
+	"watch":    watchProject,
+}
+
+func main() {
+	ran, err := plugins.RunCommand(commands, os.Args[1:])
+	if !ran {
+		err = fmt.Errorf(
+			"usage: houdini generate [-config path] [-database path] [-through hook]\n" +
+				"       houdini watch [-database path] [-debounce duration]",
+		)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
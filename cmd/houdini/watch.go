@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/watch"
+)
+
+// watchProject keeps the generated files up to date until it's interrupted. Every batch of
+// changed files becomes a task that goes through extraction, validation, and generation.
+// It picks up the database a build left behind and hosts the plugins that build registered,
+// so a framework plugin regenerates its files next to houdini-core.
+func watchProject(args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	databasePath := flags.String("database", plugins.DefaultDatabasePath, "the path to the project's database")
+	debounce := flags.Duration("debounce", 50*time.Millisecond, "how long changes have to settle before they're processed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	names, err := watchedPlugins(ctx, *databasePath)
+	if err != nil {
+		return err
+	}
+	host, err := plugins.NewHost(*databasePath)
+	if err != nil {
+		return err
+	}
+	defer host.Close()
+
+	projectConfig, err := host.Database().ProjectConfig(ctx)
+	if err != nil {
+		return err
+	}
+	// plugins resolve relative paths against the working directory, like they do when the
+	// orchestrator starts them at the root of the project
+	if err := os.Chdir(projectConfig.ProjectRoot); err != nil {
+		return err
+	}
+
+	// the runtimes come from the installed packages, the same ones generate copied
+	directory := func(name string) (string, error) {
+		found := packageDirectory(projectConfig.ProjectRoot, name)
+		if found == "" {
+			return "", fmt.Errorf("could not find %s in node_modules", name)
+		}
+		return found, nil
+	}
+
+	fs := afero.NewOsFs()
+	core := &plugin.HoudiniCore{}
+	core.SetFilesystem(fs)
+	coreDirectory, err := directory(core.Name())
+	if err != nil {
+		return err
+	}
+	plugins.AttachPlugin[config.PluginConfig](host, core, coreDirectory)
+	for _, name := range names {
+		pluginDirectory, err := directory(name)
+		if err != nil {
+			return err
+		}
+		if err := addFrameworkPlugin(host, fs, name, pluginDirectory, true); err != nil {
+			return err
+		}
+	}
+
+	walker, err := projectConfig.Walker()
+	if err != nil {
+		return err
+	}
+	// the documents of a dependency can change too. the watcher uses the same packages the
+	// tasks extract from
+	packages, err := core.FragmentPackages(ctx)
+	if err != nil {
+		return err
+	}
+	trees := make([]watch.Tree, 0, len(packages))
+	for _, pkg := range packages {
+		trees = append(trees, watch.Tree{Directory: pkg.Directory, Walker: pkg.Walker()})
+	}
+	watcher, err := watch.New(projectConfig.ProjectRoot, walker, *debounce, trees...)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	fmt.Printf("🎩 Watching %s for changes\n", projectConfig.ProjectRoot)
+
+	tasks := 0
+	return watcher.Run(ctx, func(ctx context.Context, batch watch.Batch) {
+		tasks++
+		files := append(batch.Changed, batch.Deleted...)
+		fmt.Printf("🎩 Detected %d file %s, re-running compiler\n", len(files), plural(len(files), "change", "changes"))
+
+		generated, err := core.RunTask(ctx, fmt.Sprintf("watch-%d", tasks), files, host.TriggerHook)
+		if err != nil {
+			// keep watching so the next save can fix it
+			fmt.Println(located(err))
+			return
+		}
+		fmt.Printf("🎩 Updated %d %s\n", len(generated), plural(len(generated), "file", "files"))
+	})
+}
+
+// watchedPlugins returns the plugins other than houdini-core that took part in building the
+// database
+func watchedPlugins(ctx context.Context, path string) ([]string, error) {
+	db, err := plugins.OpenExistingPool[config.PluginConfig](path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	names := []string{}
+	err = db.StepQuery(ctx, `SELECT name FROM plugins WHERE name != $core ORDER BY name`, map[string]any{
+		"core": (&plugin.HoudiniCore{}).Name(),
+	}, func(row plugins.Row) {
+		names = append(names, row.ColumnText(0))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func plural(count int, singular string, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/inspect"
+	"code.houdinigraphql.com/plugins"
+)
+
+// the binary doubles as a few commands that look at the database left behind by codegen
+var commands = plugins.Commands{
+	"dead-fields": deadFields,
+	"inspect":     inspectProject,
+}
+
+// deadFields prints every schema field, argument, and enum value that no document uses
+func deadFields(args []string) error {
+	flags := flag.NewFlagSet("dead-fields", flag.ExitOnError)
+	databasePath := flags.String("database", plugins.DefaultDatabasePath, "the path to the project's database")
+	asJSON := flags.Bool("json", false, "print the report as json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := plugins.OpenExistingPool[config.PluginConfig](*databasePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := inspect.DeadFields(context.Background(), db)
+	if err != nil {
+		return err
+	}
+	return printReport(report, *asJSON)
+}
+
+// inspectProject answers questions about the project using the database:
+//
+//	inspect document <name>       where a document is defined and what it depends on
+//	inspect field <Type.field>    the documents that select a field
+//	inspect lists                 every list and the operations that target it
+//	inspect selection <name>      the selection of a document with its fragments merged in
+func inspectProject(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	databasePath := flags.String("database", plugins.DefaultDatabasePath, "the path to the project's database")
+	asJSON := flags.Bool("json", false, "print the report as json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// every question but lists needs a subject
+	question, subject := flags.Arg(0), flags.Arg(1)
+	usage := "usage: inspect [-database path] [-json] document <name> | field <Type.field> | lists | selection <name>"
+	switch {
+	case question == "lists" && flags.NArg() == 1:
+	case question != "lists" && flags.NArg() == 2:
+	default:
+		return errors.New(usage)
+	}
+
+	db, err := plugins.OpenExistingPool[config.PluginConfig](*databasePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	var report fmt.Stringer
+	switch question {
+	case "document":
+		report, err = inspect.Document(ctx, db, subject)
+	case "field":
+		report, err = inspect.FieldUsage(ctx, db, subject)
+	case "lists":
+		report, err = inspect.Lists(ctx, db)
+	case "selection":
+		report, err = inspect.Selection(ctx, db, subject)
+	default:
+		return errors.New(usage)
+	}
+	if err != nil {
+		return err
+	}
+	return printReport(report, *asJSON)
+}
+
+func printReport(report fmt.Stringer, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	fmt.Println(report)
+	return nil
+}
@@ -11,6 +11,14 @@ import (
 )
 
 func main() {
+	if ran, err := plugins.RunCommand(commands, os.Args[1:]); ran {
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// run the plugin
 	core := &plugin.HoudiniCore{}
 	core.SetFilesystem(afero.NewOsFs())
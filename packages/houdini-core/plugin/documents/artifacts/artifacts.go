@@ -54,6 +54,28 @@ func GenerateDocumentArtifacts(
 		return nil, plugins.WrapError(err)
 	}
 
+	// artifacts that were generated before (on this machine or any other that shares the
+	// cache directory) don't need to be generated again
+	cache, err := LoadArtifactCache(ctx, db, fs, projectConfig, sortKeys, typeRoots)
+	if err != nil {
+		return nil, plugins.WrapError(err)
+	}
+
+	// the keys have to be computed before the workers start since generating an artifact
+	// can modify the documents that go into them
+	cacheKeys := map[string]string{}
+	if cache != nil {
+		for _, name := range collectedDefinitions.TaskDocuments {
+			if doc, ok := collectedDefinitions.Selections[name]; !ok || doc.Internal {
+				continue
+			}
+			cacheKeys[name], err = cache.Key(collectedDefinitions, name)
+			if err != nil {
+				return nil, plugins.WrapError(err)
+			}
+		}
+	}
+
 	// start consuming names off of the channel
 	for range runtime.NumCPU() {
 		wg.Add(1)
@@ -74,6 +96,18 @@ func GenerateDocumentArtifacts(
 				if doc.Internal {
 					continue
 				}
+
+				// if we've seen this exact document before, we already know its artifact
+				if artifact, ok := cache.Get(cacheKeys[name]); ok {
+					fp, err := writeArtifact(fs, projectConfig, name, artifact)
+					if err != nil {
+						errs.Append(plugins.WrapError(err))
+						continue
+					}
+					filepaths.Append(fp)
+					continue
+				}
+
 				// we need to generate the flattened selection
 				selection, err := FlattenSelection(
 					ctx,
@@ -99,6 +133,8 @@ func GenerateDocumentArtifacts(
 					selection,
 					sortKeys,
 					typeRoots,
+					cache,
+					cacheKeys[name],
 				)
 				if err != nil {
 					errs.Append(plugins.WrapError(err))
@@ -128,6 +164,17 @@ func GenerateDocumentArtifacts(
 		return nil, errs
 	}
 
+	if cache != nil {
+		if logger, err := db.Logger(ctx); err == nil {
+			hits, misses := cache.Stats()
+			logger.Info(
+				plugins.LogLevelSummary,
+				"  artifact cache: %d documents restored, %d generated",
+				hits, misses,
+			)
+		}
+	}
+
 	// if we got this far then we didn't have any errors
 	// before we return the thread safe slice, we need to convert the filepaths to import paths
 	importPaths := []string{}
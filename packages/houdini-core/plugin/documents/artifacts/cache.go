@@ -0,0 +1,308 @@
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/artifacts/typescript"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/collected"
+	"code.houdinigraphql.com/plugins"
+)
+
+// cacheFormat changes whenever the generated artifacts change in a way the rest of a key can't
+// see, so entries written by an older generator are never restored.
+const cacheFormat = "1"
+
+// ArtifactCache keeps generated artifacts on disk, keyed by a hash of everything that goes into
+// them: the printed document and the fragments it spreads, the schema types they touch, the
+// project config and the plugins that are installed. None of it depends on where the project
+// lives, so the directory can be shared between machines. A nil cache is turned off. It's also
+// off while a plugin adds data to the artifacts with PluginData since the key can't see what
+// that data depends on.
+type ArtifactCache struct {
+	fs  afero.Fs
+	dir string
+	// the hash of everything every artifact depends on
+	base []byte
+	// the kind of every type in the schema
+	kinds map[string]string
+
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+// LoadArtifactCache prepares the cache configured for the project. It returns nil when the
+// project doesn't use one.
+func LoadArtifactCache(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	fs afero.Fs,
+	projectConfig plugins.ProjectConfig,
+	sortKeys bool,
+	typeRoots *typescript.RootTypeNames,
+) (*ArtifactCache, error) {
+	dir := projectConfig.ArtifactCacheDirectory()
+	if dir == "" {
+		return nil, nil
+	}
+
+	pluginData := false
+	err := db.StepQuery(ctx, `
+		SELECT 1 FROM plugins
+		WHERE EXISTS (SELECT 1 FROM json_each(plugins.hooks) WHERE value = 'PluginData')
+	`, nil, func(row plugins.Row) {
+		pluginData = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pluginData {
+		return nil, nil
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "format %s\nsort keys %v\n", cacheFormat, sortKeys)
+
+	// a generator built from different sources can produce different artifacts
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(h, "build %s %s\n", info.Main.Path, info.Main.Version)
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+				fmt.Fprintf(h, "%s %s\n", setting.Key, setting.Value)
+			}
+		}
+	}
+
+	// the parts of the config that only describe the machine we're running on stay out of
+	// the key
+	portable := projectConfig
+	portable.ProjectRoot = ""
+	portable.Filepath = ""
+	portable.LogLevel = ""
+	portable.CacheDir = ""
+	if err := writeJSON(h, portable); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(h, typeRoots); err != nil {
+		return nil, err
+	}
+
+	// the plugins decide what's in the config and the runtime the artifacts import
+	err = db.StepQuery(ctx, `
+		SELECT name, hooks, plugin_order, config, version FROM plugins ORDER BY name
+	`, nil, func(row plugins.Row) {
+		fmt.Fprintf(h, "plugin %s %s %s %s %s\n",
+			row.ColumnText(0),
+			row.ColumnText(1),
+			row.ColumnText(2),
+			row.ColumnText(3),
+			row.ColumnText(4),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := map[string]string{}
+	err = db.StepQuery(ctx, "SELECT name, kind FROM types", nil, func(row plugins.Row) {
+		kinds[row.ColumnText(0)] = row.ColumnText(1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArtifactCache{
+		fs:    fs,
+		dir:   filepath.Join(dir, "artifacts"),
+		base:  h.Sum(nil),
+		kinds: kinds,
+	}, nil
+}
+
+// Key computes the cache key of the artifact for the named document. Computing a key reads
+// every document the named one spreads, so keys can't be computed while artifacts are being
+// generated.
+func (c *ArtifactCache) Key(docs *collected.Documents, name string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+
+	h := sha256.New()
+	h.Write(c.base)
+
+	refs := []string{}
+	for ref := range walkReferencedDocs(docs, name) {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	types := map[string]bool{}
+	for _, ref := range refs {
+		doc, ok := docs.Selections[ref]
+		if !ok {
+			fmt.Fprintf(h, "missing document %s\n", ref)
+			continue
+		}
+
+		// the printed document leaves out everything houdini strips before sending it, so
+		// the collected definition goes into the key too
+		fmt.Fprintf(h, "document %s\n%s\n", ref, PrintCollectedDocument(doc, false))
+		if err := writeDocument(h, doc); err != nil {
+			return "", err
+		}
+
+		types[doc.TypeCondition] = true
+		collectSelectionTypes(doc.Selections, types)
+		for _, variable := range doc.Variables {
+			types[variable.Type] = true
+		}
+		for _, input := range findUsedTypes(docs, doc.Variables) {
+			types[input] = true
+		}
+	}
+
+	typeNames := []string{}
+	for name := range types {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+	for _, name := range typeNames {
+		fmt.Fprintf(h, "type %s %s\n", name, c.kinds[name])
+		fmt.Fprintf(h, "  possible %v\n", sortedKeys(docs.PossibleTypes[name]))
+		fmt.Fprintf(h, "  implements %v\n", sortedKeys(docs.Implementations[name]))
+		fmt.Fprintf(h, "  enum %v\n", docs.EnumValues[name])
+		fields := docs.InputTypes[name]
+		fieldNames := make([]string, 0, len(fields))
+		for field := range fields {
+			fieldNames = append(fieldNames, field)
+		}
+		sort.Strings(fieldNames)
+		for _, field := range fieldNames {
+			fmt.Fprintf(h, "  input %s %s\n", field, fields[field])
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get looks up the artifact stored under the key
+func (c *ArtifactCache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	content, err := afero.ReadFile(c.fs, c.path(key))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	return string(content), true
+}
+
+// Put stores an artifact under the key
+func (c *ArtifactCache) Put(key string, artifact string) error {
+	if c == nil {
+		return nil
+	}
+
+	fp := c.path(key)
+	if err := c.fs.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+	// processes that share the directory can write the same entry at the same time. they
+	// write the same content so it doesn't matter who wins, as long as nobody reads half an entry
+	return plugins.WriteFile(c.fs, fp, []byte(artifact), 0644)
+}
+
+// Stats returns the number of lookups that found an artifact and the number that didn't
+func (c *ArtifactCache) Stats() (int, int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *ArtifactCache) path(key string) string {
+	// spread the entries out so no directory gets too big
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// writeDocument adds the collected definition of a document to a hash
+func writeDocument(h hash.Hash, doc *collected.Document) error {
+	marshaled, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := json.Unmarshal(marshaled, &value); err != nil {
+		return err
+	}
+	return writeJSON(h, stripIDs(value))
+}
+
+// stripIDs removes the database ids from a marshaled document. The rows they point to are
+// numbered differently in every database, and the values of those rows are marshaled right
+// next to them anyway. The hash goes too: it's only set once the document has been printed
+// and the key already has the printed document.
+func stripIDs(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			switch key {
+			case "ID", "DefaultValueID", "value", "Hash":
+				delete(v, key)
+			default:
+				v[key] = stripIDs(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = stripIDs(child)
+		}
+	}
+	return value
+}
+
+func collectSelectionTypes(selections []*collected.Selection, types map[string]bool) {
+	for _, selection := range selections {
+		types[selection.FieldType] = true
+		collectSelectionTypes(selection.Children, types)
+	}
+}
+
+func writeJSON(h hash.Hash, value any) error {
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	h.Write(marshaled)
+	h.Write([]byte("\n"))
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,281 @@
+package artifacts_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/artifacts"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/artifacts/typescript"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/collected"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestArtifactCache(t *testing.T) {
+	ctx := context.Background()
+
+	// cacheEntries maps every entry in the cache directory to its content
+	cacheEntries := func(t *testing.T, fs afero.Fs) map[string]string {
+		entries := map[string]string{}
+		err := afero.Walk(fs, "/cache", func(fp string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			content, err := afero.ReadFile(fs, fp)
+			entries[fp] = string(content)
+			return err
+		})
+		require.NoError(t, err)
+		return entries
+	}
+
+	// poison replaces every entry in the cache so we can tell when an artifact came from it
+	poison := func(t *testing.T, fs afero.Fs) {
+		for fp := range cacheEntries(t, fs) {
+			require.NoError(t, afero.WriteFile(fs, fp, []byte("cached"), 0644))
+		}
+	}
+
+	readArtifact := func(t *testing.T, p *plugin.HoudiniCore, name string) string {
+		projectConfig, err := p.DB.ProjectConfig(ctx)
+		require.NoError(t, err)
+		content, err := afero.ReadFile(p.Fs, projectConfig.ArtifactPath(name))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				user: User
+			}
+
+			type User {
+				id: ID!
+				name: String
+				friends: [User!]!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			require.NoError(t, p.AfterExtract(ctx))
+			require.NoError(t, p.Validate(ctx))
+			require.NoError(t, p.AfterValidate(ctx))
+
+			// the first run has nothing to restore so it fills the cache
+			_, err := documents.Generate(ctx, p.DB, p.Fs, true)
+			require.NoError(t, err)
+			entries := cacheEntries(t, p.Fs)
+			require.Len(t, entries, 2)
+			generated := readArtifact(t, p, "UserInfo")
+			stored := []string{}
+			for _, content := range entries {
+				stored = append(stored, content)
+			}
+			require.Contains(t, stored, generated)
+
+			test.Extra["check"].(func(t *testing.T, p *plugin.HoudiniCore, generated string))(
+				t,
+				p,
+				generated,
+			)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "unchanged documents are restored",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.CacheDir = "/cache"
+				},
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore, generated string) {
+						poison(t, p.Fs)
+						projectConfig, err := p.DB.ProjectConfig(ctx)
+						require.NoError(t, err)
+						require.NoError(t, p.Fs.Remove(projectConfig.ArtifactPath("UserInfo")))
+
+						_, err = documents.Generate(ctx, p.DB, p.Fs, true)
+						require.NoError(t, err)
+						require.Equal(t, "cached", readArtifact(t, p, "UserInfo"))
+					},
+				},
+			},
+			{
+				Name: "config changes aren't restored",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.CacheDir = "/cache"
+				},
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore, generated string) {
+						poison(t, p.Fs)
+
+						projectConfig, err := p.DB.ProjectConfig(ctx)
+						require.NoError(t, err)
+						projectConfig.DefaultCachePolicy = "NetworkOnly"
+						p.DB.SetProjectConfig(projectConfig)
+
+						_, err = documents.Generate(ctx, p.DB, p.Fs, true)
+						require.NoError(t, err)
+						artifact := readArtifact(t, p, "UserInfo")
+						require.NotEqual(t, "cached", artifact)
+						require.Contains(t, artifact, `"policy": "NetworkOnly"`)
+					},
+				},
+			},
+			{
+				Name: "the project root isn't part of the key",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.CacheDir = "/cache"
+				},
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore, generated string) {
+						projectConfig, err := p.DB.ProjectConfig(ctx)
+						require.NoError(t, err)
+						before := cacheKey(t, p, projectConfig, "UserInfo", nil)
+
+						projectConfig.ProjectRoot = "/somewhere/else"
+						require.Equal(t, before, cacheKey(t, p, projectConfig, "UserInfo", nil))
+					},
+				},
+			},
+			{
+				Name: "keys cover spread fragments but not database ids",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.CacheDir = "/cache"
+				},
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore, generated string) {
+						projectConfig, err := p.DB.ProjectConfig(ctx)
+						require.NoError(t, err)
+						before := cacheKey(t, p, projectConfig, "UserInfo", nil)
+
+						// the same documents loaded into another database
+						renumbered := cacheKey(t, p, projectConfig, "UserInfo", func(docs *collected.Documents) {
+							for _, doc := range docs.Selections {
+								doc.ID += 100
+							}
+						})
+						require.Equal(t, before, renumbered)
+
+						// a new field in the fragment changes the query's artifact
+						changed := cacheKey(t, p, projectConfig, "UserInfo", func(docs *collected.Documents) {
+							fragment := docs.Selections["UserFields"]
+							fragment.Selections = append(fragment.Selections, &collected.Selection{
+								FieldName: "id",
+								FieldType: "ID",
+								Kind:      "field",
+							})
+						})
+						require.NotEqual(t, before, changed)
+					},
+				},
+			},
+			{
+				Name: "plugins that add data turn the cache off",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.CacheDir = "/cache"
+				},
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore, generated string) {
+						err := p.DB.ExecQuery(ctx, `
+							INSERT INTO plugins (name, hooks, port, plugin_order)
+							VALUES ('data', '["PluginData"]', 0, 'after')
+						`, nil)
+						require.NoError(t, err)
+
+						projectConfig, err := p.DB.ProjectConfig(ctx)
+						require.NoError(t, err)
+						cache, err := artifacts.LoadArtifactCache(ctx, p.DB, p.Fs, projectConfig, true, nil)
+						require.NoError(t, err)
+						require.Nil(t, cache)
+					},
+				},
+			},
+			{
+				Name: "the cache can be turned off",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.CacheDir = "/cache"
+				},
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore, generated string) {
+						poison(t, p.Fs)
+
+						projectConfig, err := p.DB.ProjectConfig(ctx)
+						require.NoError(t, err)
+						projectConfig.CacheDir = ""
+						p.DB.SetProjectConfig(projectConfig)
+
+						_, err = documents.Generate(ctx, p.DB, p.Fs, true)
+						require.NoError(t, err)
+						require.Equal(t, generated, readArtifact(t, p, "UserInfo"))
+					},
+				},
+			},
+		},
+	})
+}
+
+// cacheKey computes the key of a document with the given config, after letting the test
+// change the collected documents
+func cacheKey(
+	t *testing.T,
+	p *plugin.HoudiniCore,
+	projectConfig plugins.ProjectConfig,
+	name string,
+	modify func(docs *collected.Documents),
+) string {
+	ctx := context.Background()
+	conn, err := p.DB.Take(ctx)
+	require.NoError(t, err)
+	defer p.DB.Put(conn)
+
+	docs, err := collected.CollectDocuments(ctx, p.DB, conn, true)
+	require.NoError(t, err)
+	if modify != nil {
+		modify(docs)
+	}
+
+	typeRoots, err := typescript.GetRootTypes(ctx, p.DB, conn)
+	require.NoError(t, err)
+	cache, err := artifacts.LoadArtifactCache(ctx, p.DB, p.Fs, projectConfig, true, typeRoots)
+	require.NoError(t, err)
+
+	key, err := cache.Key(docs, name)
+	require.NoError(t, err)
+	return key
+}
@@ -31,6 +31,8 @@ func writeSelectionDocument(
 	selection []*collected.Selection,
 	sortKeys bool,
 	typeRoots *typescript.RootTypeNames,
+	cache *ArtifactCache,
+	cacheKey string,
 ) (string, error) {
 	// generate the artifact content
 	artifact, err := GenerateSelectionDocument(
@@ -48,6 +50,19 @@ func writeSelectionDocument(
 		return "", err
 	}
 
+	// hold onto the artifact so the next run doesn't have to generate it. a cache we can't
+	// write to only costs the next run some time so it's not worth failing over
+	_ = cache.Put(cacheKey, artifact)
+
+	return writeArtifact(fs, projectConfig, name, artifact)
+}
+
+func writeArtifact(
+	fs afero.Fs,
+	projectConfig plugins.ProjectConfig,
+	name string,
+	artifact string,
+) (string, error) {
 	// compute the filepath to write the artifact to
 	artifactPath := projectConfig.ArtifactPath(name)
 
@@ -58,7 +73,7 @@ func writeSelectionDocument(
 	}
 
 	// write the file to disk
-	err = plugins.WriteFile(fs, artifactPath, []byte(artifact), 0644)
+	err := plugins.WriteFile(fs, artifactPath, []byte(artifact), 0644)
 	if err != nil {
 		return "", err
 	}
@@ -144,6 +159,9 @@ func GenerateSelectionDocument(
 	// @plural marks a fragment as list-shaped (consumed as an array of items)
 	pluralValue := ""
 
+	// track some artifact-level flags
+	flags := &ArtifactFlags{}
+
 	// we need to compute the cache policy for the document
 	cachePolicy := projectConfig.DefaultCachePolicy
 	partial := projectConfig.DefaultPartial
@@ -185,6 +203,12 @@ func GenerateSelectionDocument(
 						return "", err
 					}
 				}
+				if arg.Name == "lifetime" {
+					flags.CacheLifetime, err = strconv.Atoi(arg.Value.Raw)
+					if err != nil {
+						return "", err
+					}
+				}
 			}
 		}
 	}
@@ -193,9 +217,6 @@ func GenerateSelectionDocument(
 	// to detect loading cascades
 	forceLoading := false
 
-	// track some artifact-level flags
-	flags := &ArtifactFlags{}
-
 	var runtimeScalarsBuilder strings.Builder
 	for _, variable := range doc.Variables {
 		for _, directive := range variable.Directives {
@@ -330,6 +351,9 @@ func GenerateSelectionDocument(
 	// @session emits the sessionPath — the result field whose value becomes the session
 	sessionValue := buildSessionArtifact(doc)
 
+	// subscriptions carry the protocol they are sent over
+	transportValue := buildTransportArtifact(projectConfig, doc)
+
 	// we need to track the optimistic keys
 	optimistic := ""
 	if flags.OptimisticKeys {
@@ -489,7 +513,7 @@ const artifact = {
 
     "selection": %s,%s
 
-    "pluginData": %s,%s%s%s%s%s%s%s%s%s%s
+    "pluginData": %s,%s%s%s%s%s%s%s%s%s%s%s
 } as const
 
 export default artifact
@@ -513,6 +537,7 @@ export default artifact
 		pluralValue,
 		endpointValue,
 		sessionValue,
+		transportValue,
 		inputTypes,
 		loadingValue,
 		policyValue,
@@ -695,6 +720,7 @@ func stringifySelection(
 				projectConfig,
 				docs,
 				level,
+				parentType,
 				selection,
 				sortKeys,
 				flags,
@@ -804,6 +830,7 @@ func stringifySelection(
 							projectConfig,
 							docs,
 							level+2,
+							selection.FieldName,
 							field,
 							sortKeys,
 							flags,
@@ -1040,6 +1067,7 @@ func stringifyFieldSelection(
 	projectConfig plugins.ProjectConfig,
 	docs *collected.Documents,
 	level int,
+	parentType string,
 	selection *collected.Selection,
 	sortKeys bool,
 	flags *ArtifactFlags,
@@ -1129,11 +1157,31 @@ func stringifyFieldSelection(
 	loadingCount := 3
 	includeListID := false
 
+	// the field's cache rules come from (in order of precedence) its @cache directive,
+	// the config for the parent type, and the document's @cache directive
+	cacheRules := projectConfig.CacheRules(parentType, selection.FieldName)
+	if cacheRules.Lifetime == 0 && !projectConfig.IdentifiesRecord(parentType, selection.FieldName) {
+		cacheRules.Lifetime = flags.CacheLifetime
+	}
+
 	for _, directive := range selection.Directives {
 		switch directive.Name {
 		case graphql.IncludeListIDDirective:
 			includeListID = true
 			continue
+		case graphql.CacheDirective:
+			for _, arg := range directive.Arguments {
+				switch arg.Name {
+				case "policy":
+					cacheRules.Policy = arg.Value.Raw
+				case "lifetime":
+					if lifetime, err := strconv.Atoi(arg.Value.Raw); err == nil {
+						cacheRules.Lifetime = lifetime
+					}
+				}
+			}
+			// the rules are passed along in their own key
+			continue
 		case graphql.OptimisticKeyDirective:
 			optimisticKey = fmt.Sprintf(`
 %s"optimisticKey": true,`, indent4)
@@ -1371,6 +1419,23 @@ func stringifyFieldSelection(
 		}
 	}
 
+	// only include the cache rules when something has been configured
+	cache := ""
+	if cacheRules.Policy != "" || cacheRules.Lifetime != 0 {
+		cacheValues := []string{}
+		if cacheRules.Policy != "" {
+			cacheValues = append(cacheValues, fmt.Sprintf(`
+%s"policy": "%s"`, indent5, cacheRules.Policy))
+		}
+		if cacheRules.Lifetime != 0 {
+			cacheValues = append(cacheValues, fmt.Sprintf(`
+%s"lifetime": %v`, indent5, cacheRules.Lifetime))
+		}
+		cache = fmt.Sprintf(`
+%s"cache": {%s
+%s},`, indent4, strings.Join(cacheValues, ","), indent4)
+	}
+
 	updateStr := ""
 	if len(updates) > 0 && *selection.Alias != "pageInfo" && *selection.Alias != "__typename" &&
 		(selection.List == nil || (selection.List != nil && !selection.List.Connection)) {
@@ -1396,7 +1461,7 @@ func stringifyFieldSelection(
 
 	result += fmt.Sprintf(`%s"%s": {
 %s"type": "%s",
-%s"keyRaw": %s,%s%s%s%s%s%s%s%s%s%s%s%s%s
+%s"keyRaw": %s,%s%s%s%s%s%s%s%s%s%s%s%s%s%s
 %s},
 `,
 		indent3,
@@ -1406,6 +1471,7 @@ func stringifyFieldSelection(
 		indent4,
 		keyField(selection, paginatedMode, paginatedTargetType),
 		updateStr,
+		cache,
 		nullable,
 		directives,
 		list,
@@ -1847,6 +1913,8 @@ type ArtifactFlags struct {
 	HasLoading      string
 	// document-level operations collected during the walk (eg @refetch)
 	RootOperations []RootOperation
+	// the lifetime set by the document's @cache directive, inherited by every field
+	CacheLifetime int
 }
 
 // RootOperation is a side effect applied after a document's response is written
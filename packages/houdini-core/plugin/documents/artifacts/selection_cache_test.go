@@ -0,0 +1,169 @@
+package artifacts_test
+
+import (
+	"testing"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestCacheRules(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				user: User
+			}
+
+			type User {
+				id: ID!
+				name: String!
+				avatar: String
+				status: String
+			}
+		`,
+		ProjectConfig: plugins.ProjectConfig{
+			TypeConfig: map[string]plugins.TypeConfig{
+				"User": {
+					Keys:  []string{"id"},
+					Cache: plugins.CacheRules{Lifetime: 5000},
+					FieldCache: map[string]plugins.CacheRules{
+						"status": {Policy: "NetworkOnly", Lifetime: 1000},
+					},
+				},
+			},
+		},
+		PerformTest: performArtifactTest,
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "field rules override the type config and the document",
+				Pass: true,
+				Input: []string{
+					`query TestQuery @cache(lifetime: 60000) {
+						user {
+							name
+							status
+							avatar @cache(policy: NoCache)
+						}
+					}`,
+				},
+				Extra: map[string]any{
+					"TestQuery": tests.Dedent(`const artifact = {
+    "name": "TestQuery",
+    "kind": "HoudiniQuery",
+    "hash": "410b9feb1eba1df57b47231463f8597486816e4e72fa2adb6247207f978ac9dd",
+    "raw": ` + "`" + `query TestQuery {
+    user {
+        name
+        status
+        avatar
+        __typename
+        id
+    }
+}
+` + "`" + `,
+
+    "rootType": "Query",
+    "stripVariables": [] as Array<string>,
+
+    "selection": {
+        "fields": {
+            "user": {
+                "type": "User",
+                "keyRaw": "user",
+                "cache": {
+                    "lifetime": 60000
+                },
+                "nullable": true,
+
+                "selection": {
+                    "fields": {
+                        "__typename": {
+                            "type": "String",
+                            "keyRaw": "__typename",
+                        },
+
+                        "avatar": {
+                            "type": "String",
+                            "keyRaw": "avatar",
+                            "cache": {
+                                "policy": "NoCache",
+                                "lifetime": 5000
+                            },
+                            "nullable": true,
+                            "visible": true,
+                        },
+
+                        "id": {
+                            "type": "ID",
+                            "keyRaw": "id",
+                        },
+
+                        "name": {
+                            "type": "String",
+                            "keyRaw": "name",
+                            "cache": {
+                                "lifetime": 5000
+                            },
+                            "visible": true,
+                        },
+
+                        "status": {
+                            "type": "String",
+                            "keyRaw": "status",
+                            "cache": {
+                                "policy": "NetworkOnly",
+                                "lifetime": 1000
+                            },
+                            "nullable": true,
+                            "visible": true,
+                        },
+                    },
+                },
+
+                "visible": true,
+            },
+        },
+    },
+
+    "pluginData": {},
+    "policy": "CacheOrNetwork",
+    "partial": false
+} as const
+
+export default artifact
+
+export type TestQuery = {
+	readonly "input"?: TestQuery$input;
+	readonly "result": TestQuery$result | undefined;
+};
+
+export type TestQuery$result = {
+	readonly user: {
+		readonly name: string;
+		readonly status: string | null;
+		readonly avatar: string | null;
+	} | null;
+};
+
+export type TestQuery$input = null | undefined;
+
+export type TestQuery$unmasked = {
+	readonly user: {
+		readonly __typename: "User";
+		readonly avatar: string | null;
+		readonly id: string;
+		readonly name: string;
+		readonly status: string | null;
+	} | null;
+};
+
+export type TestQuery$artifact = typeof artifact
+
+"HoudiniHash=410b9feb1eba1df57b47231463f8597486816e4e72fa2adb6247207f978ac9dd"`),
+				},
+			},
+		},
+	})
+}
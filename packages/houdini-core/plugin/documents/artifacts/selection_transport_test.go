@@ -0,0 +1,192 @@
+package artifacts_test
+
+import (
+	"testing"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestSubscriptionTransport(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				version: Int
+			}
+
+			type Subscription {
+				newMessage: String
+			}
+		`,
+		PerformTest: performArtifactTest,
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "directive, then config mapping, then config default",
+				Pass: true,
+				Input: []string{
+					`subscription FromDirective @transport(type: "sse") { newMessage }`,
+					`subscription FromConfig { newMessage }`,
+					`subscription FromDefault { newMessage }`,
+				},
+				ProjectConfig: transportConfig,
+				Extra: map[string]any{
+					"FromDirective": tests.Dedent(`const artifact = {
+    "name": "FromDirective",
+    "kind": "HoudiniSubscription",
+    "hash": "0538bb6df83aa99df56dcca3ab9b40b3cda574b07c8f30d9b23f871143b9556c",
+    "raw": ` + "`" + `subscription FromDirective {
+    newMessage
+}
+` + "`" + `,
+
+    "rootType": "Subscription",
+    "stripVariables": [] as Array<string>,
+
+    "selection": {
+        "fields": {
+            "newMessage": {
+                "type": "String",
+                "keyRaw": "newMessage",
+                "nullable": true,
+                "visible": true,
+            },
+        },
+    },
+
+    "pluginData": {},
+
+    "transport": "sse",
+} as const
+
+export default artifact
+
+export type FromDirective = {
+	readonly "input"?: FromDirective$input;
+	readonly "result": FromDirective$result | undefined;
+};
+
+export type FromDirective$result = {
+	readonly newMessage: string | null;
+};
+
+export type FromDirective$input = null | undefined;
+
+export type FromDirective$unmasked = {
+	readonly newMessage: string | null;
+};
+
+export type FromDirective$artifact = typeof artifact
+
+"HoudiniHash=0538bb6df83aa99df56dcca3ab9b40b3cda574b07c8f30d9b23f871143b9556c"`),
+					"FromConfig": tests.Dedent(`const artifact = {
+    "name": "FromConfig",
+    "kind": "HoudiniSubscription",
+    "hash": "40dbbe3ae9c925ee826c2f7e4d4ceeec1214e761e3993b4fba9189537bbb1cb9",
+    "raw": ` + "`" + `subscription FromConfig {
+    newMessage
+}
+` + "`" + `,
+
+    "rootType": "Subscription",
+    "stripVariables": [] as Array<string>,
+
+    "selection": {
+        "fields": {
+            "newMessage": {
+                "type": "String",
+                "keyRaw": "newMessage",
+                "nullable": true,
+                "visible": true,
+            },
+        },
+    },
+
+    "pluginData": {},
+
+    "transport": "multipart",
+} as const
+
+export default artifact
+
+export type FromConfig = {
+	readonly "input"?: FromConfig$input;
+	readonly "result": FromConfig$result | undefined;
+};
+
+export type FromConfig$result = {
+	readonly newMessage: string | null;
+};
+
+export type FromConfig$input = null | undefined;
+
+export type FromConfig$unmasked = {
+	readonly newMessage: string | null;
+};
+
+export type FromConfig$artifact = typeof artifact
+
+"HoudiniHash=40dbbe3ae9c925ee826c2f7e4d4ceeec1214e761e3993b4fba9189537bbb1cb9"`),
+					"FromDefault": tests.Dedent(`const artifact = {
+    "name": "FromDefault",
+    "kind": "HoudiniSubscription",
+    "hash": "83b8a785b5f37851115a36adf17a9942f5ee5a15e2b4a82dc62abdcf847cccc9",
+    "raw": ` + "`" + `subscription FromDefault {
+    newMessage
+}
+` + "`" + `,
+
+    "rootType": "Subscription",
+    "stripVariables": [] as Array<string>,
+
+    "selection": {
+        "fields": {
+            "newMessage": {
+                "type": "String",
+                "keyRaw": "newMessage",
+                "nullable": true,
+                "visible": true,
+            },
+        },
+    },
+
+    "pluginData": {},
+
+    "transport": "graphql-ws",
+} as const
+
+export default artifact
+
+export type FromDefault = {
+	readonly "input"?: FromDefault$input;
+	readonly "result": FromDefault$result | undefined;
+};
+
+export type FromDefault$result = {
+	readonly newMessage: string | null;
+};
+
+export type FromDefault$input = null | undefined;
+
+export type FromDefault$unmasked = {
+	readonly newMessage: string | null;
+};
+
+export type FromDefault$artifact = typeof artifact
+
+"HoudiniHash=83b8a785b5f37851115a36adf17a9942f5ee5a15e2b4a82dc62abdcf847cccc9"`),
+				},
+			},
+		},
+	})
+}
+
+// transportConfig sets a default transport and maps two of the subscriptions to another one
+func transportConfig(config *plugins.ProjectConfig) {
+	config.SubscriptionTransport = plugins.SubscriptionTransportGraphQLWS
+	config.SubscriptionTransports = map[string]plugins.SubscriptionTransport{
+		"FromConfig":    plugins.SubscriptionTransportMultipart,
+		"FromDirective": plugins.SubscriptionTransportMultipart,
+	}
+}
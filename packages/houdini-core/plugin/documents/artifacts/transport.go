@@ -0,0 +1,39 @@
+package artifacts
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/collected"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// buildTransportArtifact returns the top-level `"transport"` entry for a subscription's
+// compiled artifact, or "" when nothing picks one (the client then uses its default handler).
+// @transport on the document wins over the config file's per-operation mapping, which wins
+// over the config file's default.
+func buildTransportArtifact(projectConfig plugins.ProjectConfig, doc *collected.Document) string {
+	if doc.Kind != "subscription" {
+		return ""
+	}
+
+	transport := projectConfig.TransportFor(doc.Name)
+	for _, directive := range doc.Directives {
+		if directive.Name != graphql.TransportDirective {
+			continue
+		}
+		for _, arg := range directive.Arguments {
+			if arg.Name == "type" && arg.Value != nil {
+				transport = arg.Value.Raw
+			}
+		}
+	}
+	if transport == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+
+    "transport": %s,`, strconv.Quote(transport))
+}
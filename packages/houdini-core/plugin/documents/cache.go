@@ -0,0 +1,209 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// ValidateCacheRules checks the cache rules that can be attached to individual fields:
+//   - @cache on a field only accepts a policy and a lifetime (partial is a query-wide setting)
+//   - lifetimes are literal, positive numbers of milliseconds
+//   - the cache rules in the types config point to real types and fields and use a known policy
+func ValidateCacheRules(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	errs *plugins.ErrorList,
+) {
+	// look at every argument passed to @cache, on documents and on fields
+	query := `
+		SELECT
+			'field',
+			sda.name,
+			av.kind,
+			av.raw,
+			rd.filepath,
+			av.row,
+			av.column
+		FROM selection_directives sd
+			JOIN selection_directive_arguments sda ON sda.parent = sd.id
+			JOIN argument_values av ON av.id = sda.value
+			JOIN documents d ON d.id = sda.document
+			JOIN raw_documents rd ON rd.id = d.raw_document
+		WHERE sd.directive = $cache_directive
+			AND (rd.current_task = $task_id OR $task_id IS NULL)
+
+		UNION ALL
+
+		SELECT
+			'document',
+			dda.name,
+			av.kind,
+			av.raw,
+			rd.filepath,
+			av.row,
+			av.column
+		FROM document_directives dd
+			JOIN document_directive_arguments dda ON dda.parent = dd.id
+			JOIN argument_values av ON av.id = dda.value
+			JOIN documents d ON d.id = dd.document
+			JOIN raw_documents rd ON rd.id = d.raw_document
+		WHERE dd.directive = $cache_directive
+			AND (rd.current_task = $task_id OR $task_id IS NULL)
+	`
+	err := db.StepQuery(ctx, query, map[string]any{
+		"cache_directive": graphql.CacheDirective,
+	}, func(row plugins.Row) {
+		location := []*plugins.ErrorLocation{{
+			Filepath: row.ColumnText(4),
+			Line:     row.ColumnInt(5),
+			Column:   row.ColumnInt(6),
+		}}
+
+		target, name, kind, raw := row.ColumnText(0), row.ColumnText(1), row.ColumnText(2), row.ColumnText(3)
+		switch name {
+		case "partial":
+			if target == "field" {
+				errs.Append(&plugins.Error{
+					Message: fmt.Sprintf(
+						"@%s(partial:) can only be used on a query",
+						graphql.CacheDirective,
+					),
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+			}
+
+		case "lifetime":
+			// the lifetime is baked into the artifact so it can't come from a variable
+			if kind != "Int" {
+				errs.Append(&plugins.Error{
+					Message: fmt.Sprintf(
+						"@%s(lifetime:) must be a number of milliseconds",
+						graphql.CacheDirective,
+					),
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+				return
+			}
+			if lifetime, err := strconv.Atoi(raw); err != nil || lifetime <= 0 {
+				errs.Append(&plugins.Error{
+					Message: fmt.Sprintf(
+						"@%s(lifetime:) must be a positive number of milliseconds, found %s",
+						graphql.CacheDirective,
+						raw,
+					),
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+			}
+		}
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// the rest of the checks look at the config file
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// the config only matters if it defines cache rules
+	configured := []string{}
+	for name, typeConfig := range projectConfig.TypeConfig {
+		if typeConfig.Cache != (plugins.CacheRules{}) || len(typeConfig.FieldCache) > 0 {
+			configured = append(configured, name)
+		}
+	}
+	if len(configured) == 0 {
+		return
+	}
+	sort.Strings(configured)
+
+	// load the types, fields, and policies we can refer to
+	types := map[string]bool{}
+	err = db.StepQuery(ctx, `SELECT name FROM types WHERE internal = false`, nil, func(row plugins.Row) {
+		types[row.ColumnText(0)] = true
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	fields := map[string]bool{}
+	err = db.StepQuery(ctx, `SELECT id FROM type_fields WHERE internal = false`, nil, func(row plugins.Row) {
+		fields[row.ColumnText(0)] = true
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	policies := map[string]bool{}
+	err = db.StepQuery(ctx, `SELECT value FROM enum_values WHERE parent = 'CachePolicy'`, nil, func(row plugins.Row) {
+		policies[row.ColumnText(0)] = true
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	location := []*plugins.ErrorLocation{{Filepath: projectConfig.Filepath}}
+	checkRules := func(owner string, rules plugins.CacheRules) {
+		if rules.Policy != "" && !policies[rules.Policy] {
+			errs.Append(&plugins.Error{
+				Message:   fmt.Sprintf("unknown cache policy %q for %s", rules.Policy, owner),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+		}
+		if rules.Lifetime < 0 {
+			errs.Append(&plugins.Error{
+				Message: fmt.Sprintf(
+					"the cache lifetime for %s must be a positive number of milliseconds",
+					owner,
+				),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+		}
+	}
+
+	for _, name := range configured {
+		typeConfig := projectConfig.TypeConfig[name]
+		if !types[name] {
+			errs.Append(&plugins.Error{
+				Message:   fmt.Sprintf("cache rules were configured for unknown type %s", name),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+			continue
+		}
+		checkRules(name, typeConfig.Cache)
+
+		fieldNames := []string{}
+		for field := range typeConfig.FieldCache {
+			fieldNames = append(fieldNames, field)
+		}
+		sort.Strings(fieldNames)
+		for _, field := range fieldNames {
+			id := fmt.Sprintf("%s.%s", name, field)
+			if !fields[id] {
+				errs.Append(&plugins.Error{
+					Message:   fmt.Sprintf("cache rules were configured for unknown field %s", id),
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+				continue
+			}
+			checkRules(id, typeConfig.FieldCache[field])
+		}
+	}
+}
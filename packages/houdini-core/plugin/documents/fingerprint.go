@@ -0,0 +1,184 @@
+package documents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// racyWindow is how recently a file can change before we stop trusting its mtime. A file
+// written again within the resolution of the filesystem's clock can keep its mtime and size
+// while its contents change, so fingerprints of files that changed this recently are stored
+// without an mtime: the next walk has to hash them before skipping them.
+const racyWindow = 2 * time.Second
+
+// fingerprintVersion identifies what extraction produced the raw documents a fingerprint
+// vouches for. Bump it whenever a change to the extractor can turn the same file into
+// different documents: rows recorded with another version are ignored so every file is
+// scanned again.
+const fingerprintVersion = 1
+
+// fingerprint is what a file looked like the last time its documents were extracted
+type fingerprint struct {
+	ModTime int64
+	Size    int64
+	Hash    string
+	// the number of raw documents the file produced
+	Documents int
+}
+
+// fingerprintCache decides which files a walk can skip. A file is skipped without being
+// opened when its mtime and size match its fingerprint, and without being scanned when its
+// contents hash to the same value. It is shared by every extraction worker.
+type fingerprintCache struct {
+	mu sync.Mutex
+	// the fingerprints recorded by the last extraction
+	known map[string]fingerprint
+	// the files whose raw documents are still current
+	skipped map[string]bool
+	// the fingerprints to record once the documents of this extraction are written
+	updates map[string]fingerprint
+	// the files whose fingerprint has to go: they couldn't be read
+	forgotten map[string]bool
+	// the files whose fingerprint was recorded by another version of the extractor
+	outdated map[string]bool
+	// every file the walk found
+	walked map[string]bool
+
+	hits   int
+	misses int
+}
+
+// loadFingerprints reads the fingerprint of every file. A fingerprint only counts when it was
+// recorded by this version of the extractor and the file still has the raw documents it
+// produced, so rows that something else removed (or a database that was reset) never lead to
+// a skipped file.
+func loadFingerprints[PluginConfig any](
+	ctx context.Context,
+	db plugins.DatabasePool[PluginConfig],
+) (*fingerprintCache, error) {
+	cache := &fingerprintCache{
+		known:     map[string]fingerprint{},
+		skipped:   map[string]bool{},
+		updates:   map[string]fingerprint{},
+		forgotten: map[string]bool{},
+		outdated:  map[string]bool{},
+		walked:    map[string]bool{},
+	}
+	err := db.StepQuery(ctx, `
+		SELECT
+			f.filepath, f.mtime, f.size, f.hash, f.documents,
+			(SELECT COUNT(*) FROM raw_documents rd WHERE rd.filepath = f.filepath),
+			f.version
+		FROM file_fingerprints f
+	`, nil, func(row plugins.Row) {
+		if row.ColumnInt(6) != fingerprintVersion {
+			cache.outdated[row.ColumnText(0)] = true
+			return
+		}
+		if row.ColumnInt(4) != row.ColumnInt(5) {
+			return
+		}
+		cache.known[row.ColumnText(0)] = fingerprint{
+			ModTime:   row.ColumnInt64(1),
+			Size:      row.ColumnInt64(2),
+			Hash:      row.ColumnText(3),
+			Documents: row.ColumnInt(4),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// process sends the documents of a file on ch unless the file is unchanged since the last
+// extraction.
+func (c *fingerprintCache) process(fs afero.Fs, fp string, ch chan DiscoveredDocument) *plugins.Error {
+	c.mu.Lock()
+	c.walked[fp] = true
+	c.mu.Unlock()
+
+	info, err := fs.Stat(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		// a file that's gone has no documents. its rows are stale
+		c.forget(fp)
+		return nil
+	}
+	if err != nil {
+		c.forget(fp)
+		return ProcessFile(fs, fp, ch)
+	}
+	modTime, size := info.ModTime().UnixNano(), info.Size()
+
+	c.mu.Lock()
+	known, ok := c.known[fp]
+	c.mu.Unlock()
+	if ok && known.ModTime != 0 && known.ModTime == modTime && known.Size == size {
+		c.hit(fp, nil)
+		return nil
+	}
+
+	f, err := fs.Open(fp)
+	if err != nil {
+		c.forget(fp)
+		return plugins.WrapError(fmt.Errorf("failed to open file: %w", err))
+	}
+	defer f.Close()
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		c.forget(fp)
+		return plugins.WrapError(fmt.Errorf("failed to read file: %w", err))
+	}
+
+	sum := sha256.Sum256(raw)
+	current := fingerprint{
+		ModTime: modTime,
+		Size:    size,
+		Hash:    hex.EncodeToString(sum[:]),
+	}
+	if time.Since(info.ModTime()) < racyWindow {
+		current.ModTime = 0
+	}
+
+	// touched but not changed: the documents are the same so only the mtime is new
+	if ok && known.Hash == current.Hash {
+		current.Documents = known.Documents
+		c.hit(fp, &current)
+		return nil
+	}
+
+	c.mu.Lock()
+	c.misses++
+	c.updates[fp] = current
+	c.mu.Unlock()
+
+	processContent(fp, raw, ch)
+	return nil
+}
+
+func (c *fingerprintCache) hit(fp string, update *fingerprint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+	c.skipped[fp] = true
+	if update != nil {
+		c.updates[fp] = *update
+	}
+}
+
+func (c *fingerprintCache) forget(fp string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgotten[fp] = true
+}
@@ -0,0 +1,229 @@
+package documents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins/glob"
+)
+
+// FragmentPackage is a dependency of the project that ships its own documents (a design
+// system's fragments, for example). A package opts in with a houdini.fragments field in its
+// package.json that holds one or more globs relative to the package:
+//
+//	{ "name": "design-system", "houdini": { "fragments": "src/**/*.gql" } }
+//
+// Its documents are extracted along with the project's so they're validated against the
+// project's schema and their artifacts end up in the project's runtime.
+type FragmentPackage struct {
+	Name string
+	// the real directory of the package relative to the project root. Linked workspace
+	// packages usually live outside of the project so this can start with ..
+	Directory string
+	walker    *glob.Walker
+}
+
+// Matches returns true if the file (relative to the project root) is one of the package's
+// documents
+func (p FragmentPackage) Matches(fp string) bool {
+	rel, err := filepath.Rel(filepath.FromSlash(p.Directory), filepath.FromSlash(fp))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return p.walker.Matches(filepath.ToSlash(rel))
+}
+
+// Walker returns the walker that finds the package's documents. Its paths are relative to the
+// package's directory.
+func (p FragmentPackage) Walker() *glob.Walker {
+	return p.walker
+}
+
+// Contains returns true if the file (relative to the project root) is inside of the package
+func (p FragmentPackage) Contains(fp string) bool {
+	return strings.HasPrefix(fp, p.Directory+"/")
+}
+
+// Walk calls onFile with the path (relative to the project root) of every document in the
+// package
+func (p FragmentPackage) Walk(
+	ctx context.Context,
+	fs afero.Fs,
+	root string,
+	onFile func(string) error,
+) error {
+	directory := filepath.Join(root, filepath.FromSlash(p.Directory))
+	return p.walker.Walk(ctx, fs, directory, func(fp string) error {
+		return onFile(filepath.ToSlash(filepath.Join(p.Directory, filepath.FromSlash(fp))))
+	})
+}
+
+// packageJSON is the part of a package.json that we care about
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Houdini              struct {
+		Fragments json.RawMessage `json:"fragments"`
+	} `json:"houdini"`
+}
+
+// FragmentPackages looks at the dependencies of the project for packages with documents.
+// Packages are found the same way node finds them (in the node_modules of the project
+// and each of its parents) and dependencies that aren't installed are skipped.
+func FragmentPackages(fs afero.Fs, root string) ([]FragmentPackage, error) {
+	project, err := readPackageJSON(fs, filepath.Join(root, "package.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, dependencies := range []map[string]string{
+		project.Dependencies,
+		project.DevDependencies,
+		project.PeerDependencies,
+		project.OptionalDependencies,
+	} {
+		for name := range dependencies {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := []FragmentPackage{}
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+
+		directory := findPackage(fs, root, name)
+		if directory == "" {
+			continue
+		}
+		manifest := filepath.Join(directory, "package.json")
+		dependency, err := readPackageJSON(fs, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if len(dependency.Houdini.Fragments) == 0 {
+			continue
+		}
+
+		// like include, the field can be a single glob or a list of them
+		patterns := []string{}
+		if err := json.Unmarshal(dependency.Houdini.Fragments, &patterns); err != nil {
+			var single string
+			if err := json.Unmarshal(dependency.Houdini.Fragments, &single); err != nil {
+				return nil, fmt.Errorf("%s: houdini.fragments should be a glob or a list of globs", manifest)
+			}
+			patterns = []string{single}
+		}
+
+		walker := glob.NewWalker()
+		for _, pattern := range patterns {
+			if err := walker.AddInclude(pattern); err != nil {
+				return nil, fmt.Errorf("%s: %w", manifest, err)
+			}
+		}
+		// the package's own dependencies are not part of it
+		if err := walker.AddExclude("**/node_modules"); err != nil {
+			return nil, err
+		}
+		// workspace packages are linked into node_modules. errors should point to the
+		// files that have to change and a package linked twice should be read once
+		walker.FollowSymlinks()
+
+		real, err := glob.RealPath(fs, directory)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, real)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, FragmentPackage{
+			Name:      name,
+			Directory: filepath.ToSlash(rel),
+			walker:    walker,
+		})
+	}
+
+	return result, nil
+}
+
+// findPackage returns the directory of an installed package or an empty string if it
+// can't be found
+func findPackage(fs afero.Fs, directory string, name string) string {
+	for {
+		candidate := filepath.Join(directory, "node_modules", filepath.FromSlash(name))
+		if _, err := fs.Stat(filepath.Join(candidate, "package.json")); err == nil {
+			return candidate
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return ""
+		}
+		directory = parent
+	}
+}
+
+func readPackageJSON(fs afero.Fs, fp string) (packageJSON, error) {
+	result := packageJSON{}
+	contents, err := afero.ReadFile(fs, fp)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(contents, &result); err != nil {
+		return result, fmt.Errorf("could not parse %s: %w", fp, err)
+	}
+	return result, nil
+}
+
+// projectFs reads files relative to the project root. Unlike afero.BasePathFs it can read
+// files outside of the root (the documents of a linked package). Everything else goes
+// through a read-only view of the project.
+type projectFs struct {
+	afero.Fs
+	fs   afero.Fs
+	root string
+}
+
+func newProjectFs(fs afero.Fs, root string) afero.Fs {
+	return projectFs{
+		Fs:   afero.NewReadOnlyFs(afero.NewBasePathFs(fs, root)),
+		fs:   fs,
+		root: root,
+	}
+}
+
+// like afero.BasePathFs, every name is relative to the root (even one that starts with /)
+func (p projectFs) resolve(name string) string {
+	return filepath.Join(p.root, name)
+}
+
+func (p projectFs) Open(name string) (afero.File, error) {
+	return p.fs.Open(p.resolve(name))
+}
+
+func (p projectFs) Stat(name string) (os.FileInfo, error) {
+	return p.fs.Stat(p.resolve(name))
+}
+
+func (p projectFs) Name() string {
+	return "projectFs"
+}
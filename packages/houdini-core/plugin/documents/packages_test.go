@@ -0,0 +1,174 @@
+package documents_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+// writeFiles writes every file in the map (path -> content)
+func writeFiles(t *testing.T, fs afero.Fs, files map[string]string) {
+	t.Helper()
+	for fp, content := range files {
+		require.NoError(t, afero.WriteFile(fs, fp, []byte(content), 0644))
+	}
+}
+
+func TestFragmentPackages(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFiles(t, fs, map[string]string{
+		"/repo/apps/web/package.json": `{
+			"dependencies": { "design-system": "workspace:*", "@acme/icons": "1.0.0", "react": "19.0.0" },
+			"devDependencies": { "missing": "1.0.0", "design-system": "workspace:*" }
+		}`,
+		// installed next to the project
+		"/repo/apps/web/node_modules/design-system/package.json": `{
+			"name": "design-system",
+			"houdini": { "fragments": "src/**/*.gql" }
+		}`,
+		// hoisted to the root of the monorepo
+		"/repo/node_modules/@acme/icons/package.json": `{
+			"name": "@acme/icons",
+			"houdini": { "fragments": ["lib/*.graphql", "fragments/**/*.gql"] }
+		}`,
+		// doesn't have any documents
+		"/repo/node_modules/react/package.json": `{ "name": "react" }`,
+	})
+
+	packages, err := documents.FragmentPackages(fs, "/repo/apps/web")
+	require.NoError(t, err)
+	require.Len(t, packages, 2)
+
+	require.Equal(t, "@acme/icons", packages[0].Name)
+	require.Equal(t, "../../node_modules/@acme/icons", packages[0].Directory)
+	require.True(t, packages[0].Matches("../../node_modules/@acme/icons/lib/Icon.graphql"))
+	require.True(t, packages[0].Matches("../../node_modules/@acme/icons/fragments/nested/Icon.gql"))
+	require.False(t, packages[0].Matches("../../node_modules/@acme/icons/lib/nested/Icon.graphql"))
+	require.False(t, packages[0].Matches("src/Icon.graphql"))
+
+	require.Equal(t, "design-system", packages[1].Name)
+	require.Equal(t, "node_modules/design-system", packages[1].Directory)
+	require.True(t, packages[1].Matches("node_modules/design-system/src/Avatar.gql"))
+	require.True(t, packages[1].Contains("node_modules/design-system/README.md"))
+	require.False(t, packages[1].Contains("node_modules/design-system-extras/src/Avatar.gql"))
+
+	// a project without a package.json doesn't have any dependencies
+	packages, err = documents.FragmentPackages(fs, "/elsewhere")
+	require.NoError(t, err)
+	require.Empty(t, packages)
+
+	// the field has to hold globs
+	writeFiles(t, fs, map[string]string{
+		"/repo/node_modules/react/package.json": `{ "name": "react", "houdini": { "fragments": 1 } }`,
+	})
+	_, err = documents.FragmentPackages(fs, "/repo/apps/web")
+	require.ErrorContains(t, err, "houdini.fragments should be a glob or a list of globs")
+}
+
+func TestWalk_fragmentPackages(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := plugins.NewTestPool[config.PluginConfig]()
+	require.NoError(t, err)
+	defer db.Close()
+
+	conn, err := db.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tests.WriteDatabaseSchema(conn))
+	db.Put(conn)
+
+	db.SetProjectConfig(plugins.ProjectConfig{
+		ProjectRoot:    "/repo/apps/web",
+		RuntimeDir:     ".houdini",
+		Include:        []string{"src/**/*.gql"},
+		Exclude:        []string{},
+		RuntimeScalars: map[string]string{},
+	})
+
+	fs := afero.NewMemMapFs()
+	writeFiles(t, fs, map[string]string{
+		"/repo/apps/web/package.json":   `{ "dependencies": { "design-system": "workspace:*" } }`,
+		"/repo/apps/web/src/viewer.gql": `query Viewer { viewer { ...Avatar } }`,
+		"/repo/node_modules/design-system/package.json": `{
+			"name": "design-system",
+			"houdini": { "fragments": "src/**/*.gql" }
+		}`,
+		"/repo/node_modules/design-system/src/avatar.gql":               `fragment Avatar on User { avatar }`,
+		"/repo/node_modules/design-system/src/README.md":                `fragment NotADocument on User { id }`,
+		"/repo/node_modules/design-system/node_modules/other/src/x.gql": `fragment Other on User { id }`,
+	})
+
+	rawDocuments := func() map[string]string {
+		result := map[string]string{}
+		err := db.StepQuery(ctx, `SELECT filepath, content FROM raw_documents`, nil, func(row plugins.Row) {
+			result[row.ColumnText(0)] = row.ColumnText(1)
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	packages, err := documents.FragmentPackages(fs, "/repo/apps/web")
+	require.NoError(t, err)
+
+	// the package's documents are found next to the project's with paths that point to them
+	require.NoError(t, documents.Walk(ctx, db, fs, packages))
+	require.Equal(t, map[string]string{
+		"src/viewer.gql": `query Viewer { viewer { ...Avatar } }`,
+		"../../node_modules/design-system/src/avatar.gql": `fragment Avatar on User { avatar }`,
+	}, rawDocuments())
+
+	// changes to a package's documents are picked up on their own
+	writeFiles(t, fs, map[string]string{
+		"/repo/node_modules/design-system/src/avatar.gql": `fragment Avatar on User { avatar(size: 10) }`,
+	})
+	require.NoError(t, documents.ExtractFromFilepaths(ctx, db, fs, packages, []string{
+		"/repo/node_modules/design-system/src/avatar.gql",
+		"/repo/node_modules/design-system/src/README.md",
+	}))
+	require.Equal(t, map[string]string{
+		"src/viewer.gql": `query Viewer { viewer { ...Avatar } }`,
+		"../../node_modules/design-system/src/avatar.gql": `fragment Avatar on User { avatar(size: 10) }`,
+	}, rawDocuments())
+}
+
+// the core plugin reads the packages once and hands the same list to every hook
+func TestHoudiniCore_FragmentPackages(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := plugins.NewTestPool[config.PluginConfig]()
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetProjectConfig(plugins.ProjectConfig{ProjectRoot: "/project"})
+
+	fs := afero.NewMemMapFs()
+	writeFiles(t, fs, map[string]string{
+		"/project/package.json": `{ "dependencies": { "design-system": "1.0.0" } }`,
+		"/project/node_modules/design-system/package.json": `{
+			"name": "design-system",
+			"houdini": { "fragments": "src/**/*.gql" }
+		}`,
+	})
+
+	core := &plugin.HoudiniCore{}
+	core.SetDatabase(db)
+	core.SetFilesystem(fs)
+
+	packages, err := core.FragmentPackages(ctx)
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+
+	// node_modules isn't read again
+	require.NoError(t, fs.Remove("/project/node_modules/design-system/package.json"))
+	packages, err = core.FragmentPackages(ctx)
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+	require.Equal(t, "design-system", packages[0].Name)
+}
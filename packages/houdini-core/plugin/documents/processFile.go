@@ -26,6 +26,12 @@ func ProcessFile(fs afero.Fs, fp string, ch chan DiscoveredDocument) *plugins.Er
 		return plugins.WrapError(fmt.Errorf("failed to read file: %w", err))
 	}
 
+	processContent(fp, raw, ch)
+	return nil
+}
+
+// processContent sends each GraphQL document in the contents of a file on ch
+func processContent(fp string, raw []byte, ch chan DiscoveredDocument) {
 	// For .graphql/.gql files, send the whole file as a single document.
 	if strings.HasSuffix(fp, ".graphql") || strings.HasSuffix(fp, ".gql") {
 		ch <- DiscoveredDocument{
@@ -34,7 +40,7 @@ func ProcessFile(fs afero.Fs, fp string, ch chan DiscoveredDocument) *plugins.Er
 			OffsetRow:    0,
 			OffsetColumn: 0,
 		}
-		return nil
+		return
 	}
 
 	original := string(raw)
@@ -100,8 +106,6 @@ func ProcessFile(fs afero.Fs, fp string, ch chan DiscoveredDocument) *plugins.Er
 			OffsetColumn: col,
 		}
 	}
-
-	return nil
 }
 
 // stripComments replaces JS/TS comment content with spaces, preserving all byte
@@ -27,6 +27,7 @@ type sessionUsage struct {
 //   - it only sits on mutation documents
 //   - it carries a `path`, which must resolve to an object in the mutation's selection set —
 //     that object's fields become App.Session
+//   - mutations that write the same session key agree on its type
 func ValidateSessionDirective(
 	ctx context.Context,
 	db plugins.DatabasePool[config.PluginConfig],
@@ -145,6 +146,8 @@ func ValidateSessionDirective(
 
 		validateSessionPath(usage, selectionsByDocument[usage.documentID], location, errs)
 	}
+
+	validateSessionKeyTypes(ctx, db, errs)
 }
 
 // validateSessionPath walks the dotted @session path through the mutation's selection tree
@@ -195,3 +198,54 @@ func validateSessionPath(
 		})
 	}
 }
+
+// validateSessionKeyTypes reports session keys that two @session mutations fill with
+// different types. The generated Session type has a single type for every key, and code that
+// reads the session can't tell which mutation wrote it. Only conflicts that involve a mutation
+// in the current task are reported.
+func validateSessionKeyTypes(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	errs *plugins.ErrorList,
+) {
+	writes, err := plugins.LoadSessionWrites(ctx, db)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// the first mutation to write a key decides its type
+	type keyWriter struct {
+		write plugins.SessionWrite
+		field plugins.SessionField
+	}
+	writers := map[string]keyWriter{}
+	for _, write := range writes {
+		for _, field := range write.Fields {
+			first, ok := writers[field.Name]
+			if !ok {
+				writers[field.Name] = keyWriter{write: write, field: field}
+				continue
+			}
+			if first.field.TypeString() == field.TypeString() {
+				continue
+			}
+			if !first.write.InTask && !write.InTask {
+				continue
+			}
+			errs.Append(&plugins.Error{
+				Message: fmt.Sprintf(
+					"session key %q is a %s in %q but a %s in %q",
+					field.Name, first.field.TypeString(), first.write.Mutation,
+					field.TypeString(), write.Mutation,
+				),
+				Detail: "every @session mutation that writes a key has to agree on its type",
+				Kind:   plugins.ErrorKindValidation,
+				Locations: []*plugins.ErrorLocation{
+					{Filepath: first.write.Filepath, Line: first.write.Row, Column: first.write.Column},
+					{Filepath: write.Filepath, Line: write.Row, Column: write.Column},
+				},
+			})
+		}
+	}
+}
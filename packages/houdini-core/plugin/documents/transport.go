@@ -0,0 +1,171 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// ValidateSubscriptionTransport checks the ways a subscription can pick its transport:
+//   - @transport only sits on subscriptions and names a known transport with a literal string
+//   - the transports in the config file are known
+//   - every operation in the config file's mapping is a subscription in the project
+func ValidateSubscriptionTransport(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	errs *plugins.ErrorList,
+) {
+	known := strings.Join(plugins.KnownSubscriptionTransports, ", ")
+
+	// look at every usage of the directive along with its argument (if there is one)
+	query := `
+		SELECT
+			d.kind,
+			d.name,
+			rd.filepath,
+			dd.row,
+			dd.column,
+			av.kind,
+			av.raw
+		FROM document_directives dd
+			JOIN documents d ON d.id = dd.document
+			JOIN raw_documents rd ON rd.id = d.raw_document
+			LEFT JOIN document_directive_arguments dda ON dda.parent = dd.id AND dda.name = 'type'
+			LEFT JOIN argument_values av ON av.id = dda.value
+		WHERE dd.directive = $transport_directive
+			AND (rd.current_task = $task_id OR $task_id IS NULL)
+	`
+	err := db.StepQuery(ctx, query, map[string]any{
+		"transport_directive": graphql.TransportDirective,
+	}, func(row plugins.Row) {
+		docKind, docName := row.ColumnText(0), row.ColumnText(1)
+		location := []*plugins.ErrorLocation{{
+			Filepath: row.ColumnText(2),
+			Line:     row.ColumnInt(3),
+			Column:   row.ColumnInt(4),
+		}}
+
+		if docKind != "subscription" {
+			errs.Append(&plugins.Error{
+				Message: fmt.Sprintf(
+					"@%s can only be used on a subscription, but %q is a %s",
+					graphql.TransportDirective, docName, docKind,
+				),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+			return
+		}
+
+		// a missing argument is reported by the required argument check. the transport
+		// is baked into the artifact so it can't come from a variable
+		kind, raw := row.ColumnText(5), row.ColumnText(6)
+		if kind == "" {
+			return
+		}
+		if kind != "String" {
+			errs.Append(&plugins.Error{
+				Message: fmt.Sprintf(
+					"@%s(type:) on %q must be a string literal",
+					graphql.TransportDirective, docName,
+				),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+			return
+		}
+		if !slices.Contains(plugins.KnownSubscriptionTransports, raw) {
+			errs.Append(&plugins.Error{
+				Message: fmt.Sprintf(
+					"unknown transport %q for subscription %q",
+					raw, docName,
+				),
+				Detail:    fmt.Sprintf("valid transports are: %s", known),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+		}
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// the rest of the checks look at the config file
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	if projectConfig.SubscriptionTransport == "" && len(projectConfig.SubscriptionTransports) == 0 {
+		return
+	}
+	location := []*plugins.ErrorLocation{{Filepath: projectConfig.Filepath}}
+
+	if projectConfig.SubscriptionTransport != "" &&
+		!slices.Contains(plugins.KnownSubscriptionTransports, projectConfig.SubscriptionTransport) {
+		errs.Append(&plugins.Error{
+			Message:   fmt.Sprintf("unknown subscription transport %q", projectConfig.SubscriptionTransport),
+			Detail:    fmt.Sprintf("valid transports are: %s", known),
+			Kind:      plugins.ErrorKindValidation,
+			Locations: location,
+		})
+	}
+	if len(projectConfig.SubscriptionTransports) == 0 {
+		return
+	}
+
+	// the operations in the mapping have to exist. we look at every document (not just the ones
+	// in the current task) since the config applies to the whole project
+	kinds := map[string]string{}
+	err = db.StepQuery(ctx, `SELECT name, kind FROM documents`, nil, func(row plugins.Row) {
+		kinds[row.ColumnText(0)] = row.ColumnText(1)
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	operations := []string{}
+	for name := range projectConfig.SubscriptionTransports {
+		operations = append(operations, name)
+	}
+	sort.Strings(operations)
+	for _, name := range operations {
+		transport := projectConfig.SubscriptionTransports[name]
+		if !slices.Contains(plugins.KnownSubscriptionTransports, transport) {
+			errs.Append(&plugins.Error{
+				Message:   fmt.Sprintf("unknown transport %q for subscription %q", transport, name),
+				Detail:    fmt.Sprintf("valid transports are: %s", known),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+		}
+
+		kind, ok := kinds[name]
+		if !ok {
+			errs.Append(&plugins.Error{
+				Message:   fmt.Sprintf("a transport was configured for unknown subscription %q", name),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+			continue
+		}
+		if kind != "subscription" {
+			errs.Append(&plugins.Error{
+				Message: fmt.Sprintf(
+					"a transport was configured for %q but it is a %s, not a subscription",
+					name, kind,
+				),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: location,
+			})
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// usageDocument is a user-written document that the usage checks consider
+type usageDocument struct {
+	name           string
+	kind           string
+	filepath       string
+	row            int
+	column         int
+	componentField bool
+	inTask         bool
+}
+
+// usageSpread is a single place a fragment is consumed: either a spread or the selection
+// of a component field that resolves to the fragment
+type usageSpread struct {
+	fragment string
+	filepath string
+	row      int
+	column   int
+}
+
+// ValidateDocumentUsage looks for documents that nothing in the project consumes and for
+// fragments that live far away from the components that use them:
+//   - fragments that are never spread (directly or through a component field)
+//   - operations defined in standalone .graphql/.gql files that no other file mentions
+//   - fragments that are only spread from files outside of their own directory tree
+//
+// The checks are driven by the documentUsage config value: "warn" logs each finding and
+// "error" reports them as validation errors. When it's unset, nothing is checked.
+func ValidateDocumentUsage[PluginConfig any](
+	ctx context.Context,
+	db plugins.DatabasePool[PluginConfig],
+	fs afero.Fs,
+	packages []FragmentPackage,
+	errs *plugins.ErrorList,
+) {
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	if projectConfig.DocumentUsage == plugins.DocumentUsageOff {
+		return
+	}
+
+	logger, err := db.Logger(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// every finding goes through the same place so the config value decides its severity
+	report := func(finding *plugins.Error) {
+		if projectConfig.DocumentUsage == plugins.DocumentUsageError {
+			errs.Append(finding)
+			return
+		}
+		location := ""
+		if len(finding.Locations) > 0 {
+			loc := finding.Locations[0]
+			location = fmt.Sprintf(" (%s:%d:%d)", loc.Filepath, loc.Line, loc.Column)
+		}
+		logger.Warn("%s%s", finding.Message, location)
+	}
+
+	// the usage graph has to be global: a document in the current task can be consumed by a
+	// file that isn't part of the task. we only report on the task's documents though.
+	userDocuments := []usageDocument{}
+	err = db.StepQuery(ctx, `
+		SELECT
+			documents.name,
+			documents.kind,
+			raw_documents.filepath,
+			raw_documents.offset_line,
+			raw_documents.offset_column,
+			component_fields.id IS NOT NULL AS component_field,
+			(raw_documents.current_task = $task_id OR $task_id IS NULL) AS in_task
+		FROM documents
+			JOIN raw_documents ON raw_documents.id = documents.raw_document
+			LEFT JOIN component_fields ON component_fields.fragment = documents.name
+		WHERE documents.generated = false
+			AND documents.internal = false
+	`, nil, func(row plugins.Row) {
+		// the documents of a package are shared with other projects so this one doesn't
+		// have to use all of them
+		for _, pkg := range packages {
+			if pkg.Contains(row.ColumnText(2)) {
+				return
+			}
+		}
+		userDocuments = append(userDocuments, usageDocument{
+			name:           row.ColumnText(0),
+			kind:           row.ColumnText(1),
+			filepath:       row.ColumnText(2),
+			row:            row.ColumnInt(3),
+			column:         row.ColumnInt(4),
+			componentField: row.ColumnBool(5),
+			inTask:         row.ColumnBool(6),
+		})
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// look up every place a fragment is consumed. component fields count as a use of the
+	// fragment that backs them (the same rule the document dependencies follow)
+	spreads := map[string][]usageSpread{}
+	err = db.StepQuery(ctx, `
+		SELECT
+			COALESCE(component_fields.fragment, selections.fragment_ref, selections.field_name),
+			raw_documents.filepath,
+			selection_refs.row,
+			selection_refs.column
+		FROM selections
+			JOIN selection_refs ON selection_refs.child_id = selections.id
+			JOIN documents ON documents.id = selection_refs.document
+			JOIN raw_documents ON raw_documents.id = documents.raw_document
+			LEFT JOIN component_fields ON selections.type = component_fields.type_field
+		WHERE selections.kind = 'fragment'
+			OR (selections.kind = 'field' AND component_fields.id IS NOT NULL)
+	`, nil, func(row plugins.Row) {
+		spread := usageSpread{
+			fragment: row.ColumnText(0),
+			filepath: row.ColumnText(1),
+			row:      row.ColumnInt(2),
+			column:   row.ColumnInt(3),
+		}
+		spreads[spread.fragment] = append(spreads[spread.fragment], spread)
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	// operations in standalone files need a file that refers to them by name
+	standalone := []usageDocument{}
+
+	for _, doc := range userDocuments {
+		if !doc.inTask {
+			continue
+		}
+
+		location := &plugins.ErrorLocation{
+			Filepath: doc.filepath,
+			Line:     doc.row,
+			Column:   doc.column,
+		}
+
+		if doc.kind != "fragment" {
+			if isStandaloneDocument(doc.filepath) && !isRouteDocument(doc.filepath) {
+				standalone = append(standalone, doc)
+			}
+			continue
+		}
+
+		uses := spreads[doc.name]
+		if len(uses) == 0 {
+			report(&plugins.Error{
+				Message:   fmt.Sprintf("fragment %s is never used", doc.name),
+				Kind:      plugins.ErrorKindValidation,
+				Locations: []*plugins.ErrorLocation{location},
+			})
+			continue
+		}
+
+		// component fields are designed to be selected from anywhere
+		if doc.componentField {
+			continue
+		}
+
+		// a fragment is colocated if at least one of its consumers lives in the same tree
+		colocated := false
+		for _, use := range uses {
+			if sameDirectoryTree(doc.filepath, use.filepath) {
+				colocated = true
+				break
+			}
+		}
+		if colocated {
+			continue
+		}
+
+		locations := []*plugins.ErrorLocation{location}
+		for _, use := range uses {
+			locations = append(locations, &plugins.ErrorLocation{
+				Filepath: use.filepath,
+				Line:     use.row,
+				Column:   use.column,
+			})
+		}
+		report(&plugins.Error{
+			Message: fmt.Sprintf(
+				"fragment %s is only used outside of %s",
+				doc.name,
+				path.Dir(doc.filepath),
+			),
+			Detail:    "move the fragment next to the component that uses it",
+			Kind:      plugins.ErrorKindValidation,
+			Locations: locations,
+		})
+	}
+
+	// if there are no standalone operations then we don't need to look at any files
+	if len(standalone) == 0 {
+		return
+	}
+
+	referenced, err := referencedDocumentNames(ctx, projectConfig, fs, standalone)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	for _, doc := range standalone {
+		if referenced[doc.name] {
+			continue
+		}
+		report(&plugins.Error{
+			Message: fmt.Sprintf("%s %s is never referenced", doc.kind, doc.name),
+			Kind:    plugins.ErrorKindValidation,
+			Locations: []*plugins.ErrorLocation{
+				{Filepath: doc.filepath, Line: doc.row, Column: doc.column},
+			},
+		})
+	}
+}
+
+// referencedDocumentNames walks the project's included files and returns the names of the
+// given documents that show up in a file other than the one that defines them
+func referencedDocumentNames(
+	ctx context.Context,
+	projectConfig plugins.ProjectConfig,
+	fs afero.Fs,
+	docs []usageDocument,
+) (map[string]bool, error) {
+	walker, err := projectConfig.Walker()
+	if err != nil {
+		return nil, err
+	}
+
+	// sort the names so the result doesn't depend on the database order
+	names := []string{}
+	definedIn := map[string]string{}
+	for _, doc := range docs {
+		names = append(names, doc.name)
+		definedIn[doc.name] = doc.filepath
+	}
+	sort.Strings(names)
+
+	// files behind a link can live outside of the root
+	rootedFs := newProjectFs(fs, projectConfig.ProjectRoot)
+
+	// the walker visits files concurrently so the result has to be guarded
+	referenced := plugins.ThreadSafeSlice[string]{}
+	err = walker.Walk(ctx, fs, projectConfig.ProjectRoot, func(fp string) error {
+		// graphql files can't import a document
+		if isStandaloneDocument(fp) {
+			return nil
+		}
+
+		contents, err := afero.ReadFile(rootedFs, fp)
+		if err != nil {
+			return err
+		}
+		source := string(contents)
+
+		for _, name := range names {
+			if definedIn[name] == fp {
+				continue
+			}
+			if referencesDocument(source, name) {
+				referenced.Append(name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]bool{}
+	for _, name := range referenced.GetItems() {
+		result[name] = true
+	}
+	return result, nil
+}
+
+// referencesDocument returns true if the source mentions the document name as an identifier.
+// The generated names that wrap a document (MyQueryStore, GQL_MyQuery, ...) count too.
+func referencesDocument(source string, name string) bool {
+	for offset := 0; ; {
+		index := strings.Index(source[offset:], name)
+		if index == -1 {
+			return false
+		}
+		start := offset + index
+		end := start + len(name)
+		offset = end
+
+		// the character before the name can't continue an identifier (underscores are
+		// allowed so that prefixed names like GQL_MyQuery still count)
+		if start > 0 && isIdentifierCharacter(source[start-1]) {
+			continue
+		}
+
+		// the same goes for the character after it, unless it's the store suffix
+		if end < len(source) && isIdentifierCharacter(source[end]) &&
+			!strings.HasPrefix(source[end:], "Store") {
+			continue
+		}
+
+		return true
+	}
+}
+
+func isIdentifierCharacter(c byte) bool {
+	return c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isStandaloneDocument returns true if the file holds nothing but graphql
+func isStandaloneDocument(filepath string) bool {
+	return strings.HasSuffix(filepath, ".graphql") || strings.HasSuffix(filepath, ".gql")
+}
+
+// isRouteDocument returns true for the route convention files (+page.gql, +layout.gql, ...)
+// that a router consumes without an import
+func isRouteDocument(filepath string) bool {
+	return strings.HasPrefix(path.Base(filepath), "+")
+}
+
+// sameDirectoryTree returns true if one of the files lives in the directory of the other
+func sameDirectoryTree(a string, b string) bool {
+	dirA := path.Dir(a)
+	dirB := path.Dir(b)
+	return isWithinDirectory(dirA, dirB) || isWithinDirectory(dirB, dirA)
+}
+
+func isWithinDirectory(dir string, parent string) bool {
+	if parent == "." || parent == dir {
+		return true
+	}
+	return strings.HasPrefix(dir, parent+"/")
+}
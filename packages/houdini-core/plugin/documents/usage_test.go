@@ -0,0 +1,164 @@
+package documents_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestValidateDocumentUsage(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				users: [User!]!
+			}
+
+			type User {
+				name: String!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			// the setup validates with the checks turned off so we can flip them on here,
+			// after the files that refer to the documents have been written
+			projectConfig, err := p.DB.ProjectConfig(context.Background())
+			require.NoError(t, err)
+			if mode, ok := test.Extra["usage"].(string); ok {
+				projectConfig.DocumentUsage = mode
+			}
+			p.DB.SetProjectConfig(projectConfig)
+
+			if files, ok := test.Extra["files"].(map[string]string); ok {
+				for name, contents := range files {
+					err := afero.WriteFile(p.Fs, filepath.Join("/project", name), []byte(contents), 0644)
+					require.NoError(t, err)
+				}
+			}
+
+			packages, err := p.FragmentPackages(context.Background())
+			require.NoError(t, err)
+
+			errs := &plugins.ErrorList{}
+			documents.ValidateDocumentUsage(context.Background(), p.DB, p.Fs, packages, errs)
+
+			if test.Pass {
+				require.Equal(t, 0, errs.Len(), errs.Error())
+				return
+			}
+			require.NotEqual(t, 0, errs.Len())
+			require.Contains(t, errs.Error(), test.Extra["error"])
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "unused fragment",
+				Pass: false,
+				Extra: map[string]any{
+					"usage": plugins.DocumentUsageError,
+					"error": "fragment UserInfo is never used",
+				},
+				Filepaths: []string{"src/UserInfo.gql"},
+				Input: []string{
+					`fragment UserInfo on User { name }`,
+				},
+			},
+			{
+				Name:      "unused fragments are ignored without the config value",
+				Pass:      true,
+				Filepaths: []string{"src/UserInfo.gql"},
+				Input: []string{
+					`fragment UserInfo on User { name }`,
+				},
+			},
+			{
+				Name:      "warnings don't fail validation",
+				Pass:      true,
+				Extra:     map[string]any{"usage": plugins.DocumentUsageWarn},
+				Filepaths: []string{"src/UserInfo.gql"},
+				Input: []string{
+					`fragment UserInfo on User { name }`,
+				},
+			},
+			{
+				Name:      "fragment spread from a parent directory",
+				Pass:      true,
+				Extra:     map[string]any{"usage": plugins.DocumentUsageError},
+				Filepaths: []string{"src/routes/+page.gql", "src/routes/users/UserInfo.gql"},
+				Input: []string{
+					`query AllUsers { users { ...UserInfo } }`,
+					`fragment UserInfo on User { name }`,
+				},
+			},
+			{
+				Name: "fragment only spread from another directory tree",
+				Pass: false,
+				Extra: map[string]any{
+					"usage": plugins.DocumentUsageError,
+					"error": "fragment UserInfo is only used outside of src/lib",
+				},
+				Filepaths: []string{"src/routes/+page.gql", "src/lib/UserInfo.gql"},
+				Input: []string{
+					`query AllUsers { users { ...UserInfo } }`,
+					`fragment UserInfo on User { name }`,
+				},
+			},
+			{
+				Name: "standalone operation that nothing references",
+				Pass: false,
+				Extra: map[string]any{
+					"usage": plugins.DocumentUsageError,
+					"error": "query AllUsers is never referenced",
+				},
+				Filepaths: []string{"src/queries/AllUsers.gql"},
+				Input: []string{
+					`query AllUsers { users { name } }`,
+				},
+			},
+			{
+				Name:      "standalone operation referenced through its store",
+				Pass:      true,
+				Filepaths: []string{"src/queries/AllUsers.gql"},
+				Input: []string{
+					`query AllUsers { users { name } }`,
+				},
+				Extra: map[string]any{
+					"usage": plugins.DocumentUsageError,
+					"files": map[string]string{
+						"src/routes/+page.ts": `import { AllUsersStore } from '$houdini'`,
+					},
+				},
+			},
+			{
+				Name:      "a longer identifier is not a reference",
+				Pass:      false,
+				Filepaths: []string{"src/queries/AllUsers.gql"},
+				Input: []string{
+					`query AllUsers { users { name } }`,
+				},
+				Extra: map[string]any{
+					"usage": plugins.DocumentUsageError,
+					"error": "query AllUsers is never referenced",
+					"files": map[string]string{
+						"src/routes/+page.ts": `const AllUsersCount = 1`,
+					},
+				},
+			},
+			{
+				Name:      "route documents are consumed by the router",
+				Pass:      true,
+				Extra:     map[string]any{"usage": plugins.DocumentUsageError},
+				Filepaths: []string{"src/routes/+page.gql"},
+				Input: []string{
+					`query AllUsers { users { name } }`,
+				},
+			},
+		},
+	})
+}
@@ -4,22 +4,25 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sync"
 
 	"github.com/spf13/afero"
 	"golang.org/x/sync/errgroup"
 
-	"code.houdinigraphql.com/plugins/glob"
 	"code.houdinigraphql.com/plugins"
 )
 
 // Walk is responsible for walking down the project directory structure and
 // extracting the raw graphql documents from the files. These files will be parsed in a
-// later step to allow for other plugins to find additional documents we don't know about
+// later step to allow for other plugins to find additional documents we don't know about.
+// The documents of the packages are extracted along with the project's.
 func Walk[PluginConfig any](
 	ctx context.Context,
 	db plugins.DatabasePool[PluginConfig],
 	fs afero.Fs,
+	packages []FragmentPackage,
 ) error {
 	// load the project config
 	config, err := db.ProjectConfig(ctx)
@@ -28,18 +31,9 @@ func Walk[PluginConfig any](
 	}
 
 	// build a glob walker that we can use to find all of the files
-	walker := glob.NewWalker()
-	for _, pattern := range config.Include {
-		err = walker.AddInclude(pattern)
-		if err != nil {
-			return err
-		}
-	}
-	for _, pattern := range config.Exclude {
-		err = walker.AddExclude(pattern)
-		if err != nil {
-			return err
-		}
+	walker, err := config.Walker()
+	if err != nil {
+		return err
 	}
 
 	// we might also need to include static runtimes
@@ -68,15 +62,21 @@ func Walk[PluginConfig any](
 	}
 
 	// The walker returns paths relative to config.ProjectRoot, but ProcessFile opens them
-	// from the filesystem. Use a BasePathFs so that opening a relative path correctly
+	// from the filesystem. Use a rooted filesystem so that opening a relative path correctly
 	// resolves against the project root on any afero backend (including MemMapFs in tests).
-	rootedFs := afero.NewBasePathFs(fs, config.ProjectRoot)
+	// Documents from a linked package can live outside of the root.
+	rootedFs := newProjectFs(fs, config.ProjectRoot)
 
 	// and extract the documents that the walker finds. a full walk sees every
 	// included file, so any leftover row is stale regardless of which file it
 	// came from
 	return extractDocuments(ctx, db, rootedFs, func(filePathsCh chan string) error {
-		return walker.Walk(ctx, fs, config.ProjectRoot, func(fp string) error {
+		// a package inside of the project could also match the include patterns
+		var sent sync.Map
+		send := func(fp string) error {
+			if _, seen := sent.LoadOrStore(fp, true); seen {
+				return nil
+			}
 			// in case the context is canceled, stop early.
 			select {
 			case filePathsCh <- fp:
@@ -84,7 +84,17 @@ func Walk[PluginConfig any](
 			case <-ctx.Done():
 				return ctx.Err()
 			}
-		})
+		}
+
+		if err := walker.Walk(ctx, fs, config.ProjectRoot, send); err != nil {
+			return err
+		}
+		for _, pkg := range packages {
+			if err := pkg.Walk(ctx, fs, config.ProjectRoot, send); err != nil {
+				return fmt.Errorf("%s: %w", pkg.Name, err)
+			}
+		}
+		return nil
 	}, func(string) bool { return true })
 }
 
@@ -92,6 +102,7 @@ func ExtractFromFilepaths[PluginConfig any](
 	ctx context.Context,
 	db plugins.DatabasePool[PluginConfig],
 	fs afero.Fs,
+	packages []FragmentPackage,
 	files []string,
 ) error {
 	// load the project config
@@ -100,22 +111,13 @@ func ExtractFromFilepaths[PluginConfig any](
 		return err
 	}
 	// build a glob walker that we can use to find all of the files
-	walker := glob.NewWalker()
-	for _, pattern := range config.Include {
-		err = walker.AddInclude(pattern)
-		if err != nil {
-			return err
-		}
-	}
-	for _, pattern := range config.Exclude {
-		err = walker.AddExclude(pattern)
-		if err != nil {
-			return err
-		}
+	walker, err := config.Walker()
+	if err != nil {
+		return err
 	}
 
 	root := config.ProjectRoot
-	rootedFs := afero.NewBasePathFs(fs, root)
+	rootedFs := newProjectFs(fs, root)
 
 	// only rows belonging to the walked files can be considered stale — every
 	// other file's rows simply weren't rediscovered because we didn't look
@@ -128,10 +130,26 @@ func ExtractFromFilepaths[PluginConfig any](
 			if err != nil {
 				return err
 			}
-			if !walker.Matches(rel) {
+			rel = filepath.ToSlash(rel)
+			if walker.Matches(rel) {
+				// a full walk wouldn't have found the file either
+				ignored, err := walker.Ignores(fs, root, rel, false)
+				if err != nil {
+					return err
+				}
+				if ignored {
+					continue
+				}
+				// and it would have reported it by its real path
+				rel, err = walker.ReportedPath(fs, root, rel)
+				if err != nil {
+					return err
+				}
+			} else if !slices.ContainsFunc(packages, func(pkg FragmentPackage) bool {
+				return pkg.Matches(rel)
+			}) {
 				continue
 			}
-			rel = filepath.ToSlash(rel)
 			included[rel] = true
 			select {
 			case filePathsCh <- rel:
@@ -153,6 +171,12 @@ func extractDocuments[PluginConfig any](
 	// the document wasn't rediscovered) — deleted files and changed contents
 	stale func(string) bool,
 ) error {
+	// files that haven't changed since the last extraction are skipped
+	cache, err := loadFingerprints(ctx, db)
+	if err != nil {
+		return err
+	}
+
 	// channels for file paths and discovered documents
 	filePathsCh := make(chan string, 100000)
 	resultsCh := make(chan DiscoveredDocument, 100000)
@@ -191,7 +215,7 @@ func extractDocuments[PluginConfig any](
 
 	// file processing workers
 	var procWG sync.WaitGroup
-	for range 1 {
+	for range runtime.GOMAXPROCS(0) {
 		procWG.Add(1)
 		go func() {
 			defer procWG.Done()
@@ -204,7 +228,7 @@ func extractDocuments[PluginConfig any](
 						return // channel closed
 					}
 					// process the file
-					if err := ProcessFile(fs, fp, resultsCh); err != nil {
+					if err := cache.process(fs, fp, resultsCh); err != nil {
 						errs.Append(err)
 					}
 				case <-ctx.Done():
@@ -272,6 +296,31 @@ func extractDocuments[PluginConfig any](
 		}
 		defer insertComponentField.Finalize()
 
+		upsertFingerprint, err := conn.Prepare(`
+      INSERT INTO file_fingerprints (filepath, mtime, size, hash, documents, version)
+      VALUES ($filepath, $mtime, $size, $hash, $documents, $version)
+      ON CONFLICT (filepath) DO UPDATE SET
+        mtime = excluded.mtime,
+        size = excluded.size,
+        hash = excluded.hash,
+        documents = excluded.documents,
+        version = excluded.version
+    `)
+		if err != nil {
+			errs.Append(plugins.WrapError(fmt.Errorf("failed to prepare statement: %w", err)))
+			return nil
+		}
+		defer upsertFingerprint.Finalize()
+
+		deleteFingerprint, err := conn.Prepare(`
+      DELETE FROM file_fingerprints WHERE filepath = $filepath
+    `)
+		if err != nil {
+			errs.Append(plugins.WrapError(fmt.Errorf("failed to prepare statement: %w", err)))
+			return nil
+		}
+		defer deleteFingerprint.Finalize()
+
 		// before we start consuming new documents let's look at the current state of the raw_documents table
 		// and build up a mapping from filepath -> content -> id
 		// if there are entries left in this mapping then we need to delete the IDs
@@ -297,8 +346,13 @@ func extractDocuments[PluginConfig any](
 			unknown[filepath][doc] = id
 		})
 
+		// the number of documents each scanned file produced, for its fingerprint
+		counts := map[string]int{}
+
 		// consume discovered documents from resultsCh and write them to the database.
 		for doc := range resultsCh {
+			counts[doc.FilePath]++
+
 			// we discovered a document, remove it from the list of unknowns
 			if _, ok := unknown[doc.FilePath]; ok {
 				docID := KnownDoc{
@@ -344,8 +398,9 @@ func extractDocuments[PluginConfig any](
 		// produces it — the file was deleted or its contents changed. remove the
 		// stale rows (and their documents) so they stop participating in
 		// validation and generation.
+		// files the cache skipped weren't scanned, so their rows are still current.
 		for path, docs := range unknown {
-			if !stale(path) {
+			if !stale(path) || cache.skipped[path] {
 				continue
 			}
 			for _, id := range docs {
@@ -360,6 +415,45 @@ func extractDocuments[PluginConfig any](
 			}
 		}
 
+		// the workers are done by now so the cache is settled. record what every scanned
+		// file looks like, and drop the fingerprints of files that are gone: a file that
+		// comes back with the same mtime and size must still be scanned
+		for path, print := range cache.updates {
+			if !cache.skipped[path] {
+				print.Documents = counts[path]
+			}
+			err := db.ExecStatement(upsertFingerprint, map[string]any{
+				"filepath":  path,
+				"mtime":     print.ModTime,
+				"size":      print.Size,
+				"hash":      print.Hash,
+				"documents": print.Documents,
+				"version":   fingerprintVersion,
+			})
+			if err != nil {
+				errs.Append(plugins.WrapError(fmt.Errorf("failed to record file fingerprint: %v", err)))
+				return nil
+			}
+		}
+		for path := range cache.known {
+			if cache.forgotten[path] || (stale(path) && !cache.walked[path]) {
+				if err := db.ExecStatement(deleteFingerprint, map[string]any{"filepath": path}); err != nil {
+					errs.Append(plugins.WrapError(fmt.Errorf("failed to delete file fingerprint: %v", err)))
+					return nil
+				}
+			}
+		}
+		// fingerprints from another version of the extractor that weren't just replaced
+		for path := range cache.outdated {
+			if _, ok := cache.updates[path]; ok {
+				continue
+			}
+			if err := db.ExecStatement(deleteFingerprint, map[string]any{"filepath": path}); err != nil {
+				errs.Append(plugins.WrapError(fmt.Errorf("failed to delete file fingerprint: %v", err)))
+				return nil
+			}
+		}
+
 		// we're done
 		return nil
 	})
@@ -381,6 +475,14 @@ func extractDocuments[PluginConfig any](
 		return errs
 	}
 
+	if logger, err := db.Logger(ctx); err == nil {
+		logger.Info(
+			plugins.LogLevelSummary,
+			"  document extraction: %d files unchanged, %d scanned",
+			cache.hits, cache.misses,
+		)
+	}
+
 	// if we got here, everything completed successfully.
 	return nil
 }
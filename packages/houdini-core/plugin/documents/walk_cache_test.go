@@ -0,0 +1,166 @@
+package documents_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+// a full walk skips the files that haven't changed since the last one
+func TestWalk_fingerprints(t *testing.T) {
+	ctx := context.Background()
+
+	// files written just now can't be trusted by their mtime, so every file gets one from
+	// an hour ago
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	write := func(t *testing.T, fs afero.Fs, fp string, content string, mtime time.Time) {
+		require.NoError(t, afero.WriteFile(fs, fp, []byte(content), 0644))
+		require.NoError(t, fs.Chtimes(fp, mtime, mtime))
+	}
+
+	setup := func(t *testing.T) (plugins.DatabasePool[config.PluginConfig], afero.Fs) {
+		db, err := plugins.NewTestPool[config.PluginConfig]()
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		conn, err := db.Take(ctx)
+		require.NoError(t, err)
+		require.NoError(t, tests.WriteDatabaseSchema(conn))
+		db.Put(conn)
+
+		db.SetProjectConfig(plugins.ProjectConfig{
+			ProjectRoot:    "/project",
+			RuntimeDir:     ".houdini",
+			Include:        []string{"**/*.graphql"},
+			Exclude:        []string{},
+			RuntimeScalars: map[string]string{},
+			LogLevel:       "QUIET",
+		})
+
+		fs := afero.NewMemMapFs()
+		require.NoError(t, fs.MkdirAll("/project", 0755))
+		write(t, fs, "/project/a.graphql", "query A { a }", past)
+		write(t, fs, "/project/b.graphql", "query B { b }", past)
+
+		// the first walk scans everything and records the fingerprints
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+		return db, fs
+	}
+
+	query := func(t *testing.T, db plugins.DatabasePool[config.PluginConfig], sql string) map[string]string {
+		got := map[string]string{}
+		require.NoError(t, db.StepQuery(ctx, sql, nil, func(row plugins.Row) {
+			got[row.ColumnText(0)] = row.ColumnText(1)
+		}))
+		return got
+	}
+	rawDocuments := func(t *testing.T, db plugins.DatabasePool[config.PluginConfig]) map[string]string {
+		return query(t, db, "SELECT filepath, content FROM raw_documents")
+	}
+	mtimes := func(t *testing.T, db plugins.DatabasePool[config.PluginConfig]) map[string]string {
+		return query(t, db, "SELECT filepath, mtime FROM file_fingerprints")
+	}
+
+	t.Run("unchanged files are not opened", func(t *testing.T) {
+		db, fs := setup(t)
+
+		// same size and mtime: the walk has no reason to look inside, so it doesn't see the
+		// new document
+		write(t, fs, "/project/a.graphql", "query Z { z }", past)
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+
+		require.Equal(t, map[string]string{
+			"a.graphql": "query A { a }",
+			"b.graphql": "query B { b }",
+		}, rawDocuments(t, db))
+	})
+
+	t.Run("changed files are scanned again", func(t *testing.T) {
+		db, fs := setup(t)
+
+		write(t, fs, "/project/a.graphql", "query Z { z }", past.Add(time.Minute))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+
+		require.Equal(t, map[string]string{
+			"a.graphql": "query Z { z }",
+			"b.graphql": "query B { b }",
+		}, rawDocuments(t, db))
+	})
+
+	t.Run("touched files only get a new mtime", func(t *testing.T) {
+		db, fs := setup(t)
+		before := mtimes(t, db)
+
+		touched := past.Add(time.Minute)
+		require.NoError(t, fs.Chtimes("/project/a.graphql", touched, touched))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+
+		after := mtimes(t, db)
+		require.NotEqual(t, before["a.graphql"], after["a.graphql"])
+		require.Equal(t, before["b.graphql"], after["b.graphql"])
+		require.Equal(t, map[string]string{
+			"a.graphql": "query A { a }",
+			"b.graphql": "query B { b }",
+		}, rawDocuments(t, db))
+	})
+
+	t.Run("a fingerprint doesn't count when its documents are gone", func(t *testing.T) {
+		db, fs := setup(t)
+
+		conn, err := db.Take(ctx)
+		require.NoError(t, err)
+		stmt, err := conn.Prepare("DELETE FROM raw_documents WHERE filepath = 'a.graphql'")
+		require.NoError(t, err)
+		require.NoError(t, db.ExecStatement(stmt, nil))
+		stmt.Finalize()
+		db.Put(conn)
+
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+		require.Equal(t, map[string]string{
+			"a.graphql": "query A { a }",
+			"b.graphql": "query B { b }",
+		}, rawDocuments(t, db))
+	})
+
+	t.Run("fingerprints from another extractor version don't count", func(t *testing.T) {
+		db, fs := setup(t)
+		current := query(t, db, "SELECT filepath, version FROM file_fingerprints")
+
+		conn, err := db.Take(ctx)
+		require.NoError(t, err)
+		stmt, err := conn.Prepare("UPDATE file_fingerprints SET version = version - 1")
+		require.NoError(t, err)
+		require.NoError(t, db.ExecStatement(stmt, nil))
+		stmt.Finalize()
+		db.Put(conn)
+
+		// same size and mtime, but the old fingerprint can't vouch for it anymore
+		write(t, fs, "/project/a.graphql", "query Z { z }", past)
+		require.NoError(t, fs.Remove("/project/b.graphql"))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+
+		require.Equal(t, map[string]string{"a.graphql": "query Z { z }"}, rawDocuments(t, db))
+		require.Equal(t, map[string]string{"a.graphql": current["a.graphql"]}, query(
+			t, db, "SELECT filepath, version FROM file_fingerprints",
+		))
+	})
+
+	t.Run("deleted files lose their fingerprint", func(t *testing.T) {
+		db, fs := setup(t)
+
+		require.NoError(t, fs.Remove("/project/b.graphql"))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
+
+		require.Equal(t, map[string]string{"a.graphql": "query A { a }"}, rawDocuments(t, db))
+		require.NotContains(t, mtimes(t, db), "b.graphql")
+	})
+}
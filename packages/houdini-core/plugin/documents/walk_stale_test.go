@@ -70,7 +70,7 @@ func TestExtractDocuments_staleRows(t *testing.T) {
 	t.Run("a filepath-scoped extraction processes every file it is given", func(t *testing.T) {
 		db, fs := setup(t)
 
-		err := documents.ExtractFromFilepaths(ctx, db, fs, []string{
+		err := documents.ExtractFromFilepaths(ctx, db, fs, nil, []string{
 			"/project/a.graphql",
 			"/project/b.graphql",
 		})
@@ -84,7 +84,7 @@ func TestExtractDocuments_staleRows(t *testing.T) {
 
 	t.Run("a full walk deletes rows for deleted files and changed contents", func(t *testing.T) {
 		db, fs := setup(t)
-		require.NoError(t, documents.Walk(ctx, db, fs))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
 
 		// a documents row hanging off the soon-to-be-stale raw document has to
 		// disappear with it (this connection doesn't enforce foreign keys)
@@ -102,7 +102,7 @@ func TestExtractDocuments_staleRows(t *testing.T) {
 		require.NoError(t, fs.Remove("/project/b.graphql"))
 		require.NoError(t, afero.WriteFile(fs, "/project/a.graphql", []byte("query A2 { a }"), 0644))
 
-		require.NoError(t, documents.Walk(ctx, db, fs))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
 
 		require.Equal(t, map[string][]string{
 			"a.graphql": {"query A2 { a }"},
@@ -122,14 +122,14 @@ func TestExtractDocuments_staleRows(t *testing.T) {
 
 	t.Run("a scoped extraction leaves unwalked files' rows alone", func(t *testing.T) {
 		db, fs := setup(t)
-		require.NoError(t, documents.Walk(ctx, db, fs))
+		require.NoError(t, documents.Walk(ctx, db, fs, nil))
 
 		// b's file is gone from disk, but this run never looks at it — its rows
 		// simply weren't rediscovered and must survive
 		require.NoError(t, fs.Remove("/project/b.graphql"))
 		require.NoError(t, afero.WriteFile(fs, "/project/a.graphql", []byte("query A2 { a }"), 0644))
 
-		err := documents.ExtractFromFilepaths(ctx, db, fs, []string{"/project/a.graphql"})
+		err := documents.ExtractFromFilepaths(ctx, db, fs, nil, []string{"/project/a.graphql"})
 		require.NoError(t, err)
 
 		require.Equal(t, map[string][]string{
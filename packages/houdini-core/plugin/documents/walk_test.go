@@ -2,7 +2,9 @@ package documents_test
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -62,7 +64,7 @@ func TestWalk_staticRuntimeIsDiscovered(t *testing.T) {
 		0644,
 	))
 
-	require.NoError(t, documents.Walk(ctx, db, fs))
+	require.NoError(t, documents.Walk(ctx, db, fs, nil))
 
 	// The file should have been inserted into raw_documents.
 	conn, err = db.Take(ctx)
@@ -78,3 +80,106 @@ func TestWalk_staticRuntimeIsDiscovered(t *testing.T) {
 	require.True(t, hasRow)
 	require.Equal(t, 1, stmt.ColumnInt(0), "static runtime file should be discovered by Walk")
 }
+
+func TestWalk_ignoreFilesAndSymlinks(t *testing.T) {
+	table := []struct {
+		name           string
+		readIgnore     bool
+		followSymlinks bool
+		expected       []string
+		// files that change after the walk, relative to the project
+		changed []string
+	}{
+		{
+			name:     "off by default",
+			expected: []string{"src/drafts/draft.graphql", "src/index.graphql"},
+		},
+		{
+			name:       "ignore files",
+			readIgnore: true,
+			expected:   []string{"src/index.graphql"},
+			changed:    []string{"src/drafts/draft.graphql"},
+		},
+		{
+			name:           "symlinks",
+			followSymlinks: true,
+			expected: []string{
+				"../shared/fragments.graphql",
+				"src/drafts/draft.graphql",
+				"src/index.graphql",
+			},
+			changed: []string{"src/shared/fragments.graphql"},
+		},
+	}
+
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			dir := t.TempDir()
+			root := filepath.Join(dir, "project")
+			files := map[string]string{
+				"project/.gitignore":               "src/drafts\n",
+				"project/src/index.graphql":        "query Index { version }",
+				"project/src/drafts/draft.graphql": "query Draft { version }",
+				"shared/fragments.graphql":         "fragment Shared on User { id }",
+			}
+			for fp, content := range files {
+				full := filepath.Join(dir, filepath.FromSlash(fp))
+				require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+				require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+			}
+			if err := os.Symlink(filepath.Join(dir, "shared"), filepath.Join(root, "src", "shared")); err != nil {
+				t.Skipf("symlinks aren't supported: %v", err)
+			}
+
+			db, err := plugins.NewTestPool[config.PluginConfig]()
+			require.NoError(t, err)
+			defer db.Close()
+
+			conn, err := db.Take(ctx)
+			require.NoError(t, err)
+			require.NoError(t, tests.WriteDatabaseSchema(conn))
+			db.Put(conn)
+
+			db.SetProjectConfig(plugins.ProjectConfig{
+				ProjectRoot:     root,
+				RuntimeDir:      ".houdini",
+				Include:         []string{"src/**/*.graphql"},
+				Exclude:         []string{},
+				RuntimeScalars:  map[string]string{},
+				ReadIgnoreFiles: row.readIgnore,
+				FollowSymlinks:  row.followSymlinks,
+			})
+
+			fs := afero.NewOsFs()
+			require.NoError(t, documents.Walk(ctx, db, fs, nil))
+			require.Equal(t, row.expected, rawDocumentPaths(t, db))
+
+			// a change to one of the files ends up in the same place a full walk puts it
+			if len(row.changed) == 0 {
+				return
+			}
+			changed := []string{}
+			for _, fp := range row.changed {
+				full := filepath.Join(root, filepath.FromSlash(fp))
+				require.NoError(t, os.WriteFile(full, []byte("fragment Changed on User { id }"), 0644))
+				changed = append(changed, full)
+			}
+			require.NoError(t, documents.ExtractFromFilepaths(ctx, db, fs, nil, changed))
+			require.Equal(t, row.expected, rawDocumentPaths(t, db))
+		})
+	}
+}
+
+func rawDocumentPaths(t *testing.T, db plugins.DatabasePool[config.PluginConfig]) []string {
+	t.Helper()
+
+	paths := []string{}
+	err := db.StepQuery(context.Background(), "SELECT filepath FROM raw_documents", nil, func(row plugins.Row) {
+		paths = append(paths, row.ColumnText(0))
+	})
+	require.NoError(t, err)
+	sort.Strings(paths)
+	return paths
+}
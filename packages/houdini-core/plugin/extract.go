@@ -14,10 +14,16 @@ func (p *HoudiniCore) ExtractDocuments(
 	ctx context.Context,
 	input plugins.ExtractDocumentsInput,
 ) error {
+	// dependencies can ship documents of their own
+	packages, err := p.FragmentPackages(ctx)
+	if err != nil {
+		return err
+	}
+
 	// if we were given a specific path to extract, do that
 	if len(input.Filepaths) > 0 {
-		return documents.ExtractFromFilepaths(ctx, p.DB, p.Fs, input.Filepaths)
+		return documents.ExtractFromFilepaths(ctx, p.DB, p.Fs, packages, input.Filepaths)
 	}
 	// there is no task id, just walk the full filesystem
-	return documents.Walk(ctx, p.DB, p.Fs)
+	return documents.Walk(ctx, p.DB, p.Fs, packages)
 }
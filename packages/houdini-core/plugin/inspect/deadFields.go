@@ -0,0 +1,288 @@
+package inspect
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// DeadFieldsReport lists the parts of the schema that no document in the project selects.
+// Every document counts, including the ones houdini generates (pagination queries, list
+// operations, ...) so the report reflects what the client can actually send.
+type DeadFieldsReport struct {
+	Types []DeadType `json:"types"`
+}
+
+// DeadType groups the unused definitions of a single schema type
+type DeadType struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Fields     []string `json:"fields,omitempty"`
+	Arguments  []string `json:"arguments,omitempty"`
+	EnumValues []string `json:"enumValues,omitempty"`
+}
+
+// DeadFields compares the schema tables against the selections of every document and
+// returns the fields, arguments and enum values that are never referenced.
+//
+// A few rules keep the report honest:
+//   - a field selected on an abstract type counts for every member (and the other way around)
+//   - input objects and enums used as the type of a variable are entirely in use since
+//     the client can send any value at runtime
+//   - enums returned by a selected field are in use: the client receives every value
+func DeadFields[PluginConfig any](
+	ctx context.Context,
+	db plugins.DatabasePool[PluginConfig],
+) (DeadFieldsReport, error) {
+	report := DeadFieldsReport{Types: []DeadType{}}
+
+	// start with the types that belong to the user's schema
+	kinds := map[string]string{}
+	err := db.StepQuery(ctx, `
+		SELECT name, kind FROM types
+		WHERE internal = false
+			AND built_in = false
+			AND name NOT LIKE '\_\_%' ESCAPE '\'
+	`, nil, func(row plugins.Row) {
+		kinds[row.ColumnText(0)] = row.ColumnText(1)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// abstract types and their members are used interchangeably
+	related := map[string][]string{}
+	err = db.StepQuery(ctx, `SELECT type, member FROM possible_types`, nil, func(row plugins.Row) {
+		abstract, member := row.ColumnText(0), row.ColumnText(1)
+		related[abstract] = append(related[abstract], member)
+		related[member] = append(related[member], abstract)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// every field that a document selects (only selections that are still attached to a document)
+	selectedFields := map[string]bool{}
+	err = db.StepQuery(ctx, `
+		SELECT DISTINCT selections.type
+		FROM selections
+			JOIN selection_refs ON selection_refs.child_id = selections.id
+		WHERE selections.kind = 'field'
+	`, nil, func(row plugins.Row) {
+		selectedFields[row.ColumnText(0)] = true
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// every argument that a document passes
+	passedArguments := map[string]bool{}
+	err = db.StepQuery(ctx, `SELECT DISTINCT field_argument FROM selection_arguments`, nil, func(row plugins.Row) {
+		passedArguments[row.ColumnText(0)] = true
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// enum values that show up as literals
+	literalValues := map[string]bool{}
+	err = db.StepQuery(ctx, `
+		SELECT DISTINCT expected_type, raw FROM argument_values WHERE kind = 'Enum'
+	`, nil, func(row plugins.Row) {
+		literalValues[row.ColumnText(0)+"."+row.ColumnText(1)] = true
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// input fields that show up in object literals
+	literalInputFields := map[string]bool{}
+	err = db.StepQuery(ctx, `
+		SELECT DISTINCT argument_values.expected_type, argument_value_children.name
+		FROM argument_value_children
+			JOIN argument_values ON argument_values.id = argument_value_children.parent
+		WHERE argument_values.kind = 'Object'
+	`, nil, func(row plugins.Row) {
+		literalInputFields[row.ColumnText(0)+"."+row.ColumnText(1)] = true
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// input types (and enums) that are used as the type of a variable
+	variableTypes := []string{}
+	err = db.StepQuery(ctx, `SELECT DISTINCT type FROM document_variables`, nil, func(row plugins.Row) {
+		variableTypes = append(variableTypes, row.ColumnText(0))
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// now we can walk the fields in the schema
+	type field struct {
+		id     string
+		parent string
+		name   string
+		typ    string
+	}
+	fields := []field{}
+	err = db.StepQuery(ctx, `
+		SELECT id, parent, name, type FROM type_fields
+		WHERE internal = false
+			AND document IS NULL
+			AND name NOT LIKE '\_\_%' ESCAPE '\'
+	`, nil, func(row plugins.Row) {
+		fields = append(fields, field{
+			id:     row.ColumnText(0),
+			parent: row.ColumnText(1),
+			name:   row.ColumnText(2),
+			typ:    row.ColumnText(3),
+		})
+	})
+	if err != nil {
+		return report, err
+	}
+	inputFields := map[string][]field{}
+	for _, f := range fields {
+		if kinds[f.parent] == "INPUT" {
+			inputFields[f.parent] = append(inputFields[f.parent], f)
+		}
+	}
+
+	// any type the client can send freely is entirely in use, along with every input type
+	// that can be reached from it
+	openTypes := map[string]bool{}
+	var open func(name string)
+	open = func(name string) {
+		if openTypes[name] {
+			return
+		}
+		openTypes[name] = true
+		for _, f := range inputFields[name] {
+			open(f.typ)
+		}
+	}
+	for _, name := range variableTypes {
+		open(name)
+	}
+
+	// a field is selected if it was selected on its type or on a related one
+	isSelected := func(parent string, name string) bool {
+		if selectedFields[parent+"."+name] {
+			return true
+		}
+		for _, other := range related[parent] {
+			if selectedFields[other+"."+name] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// collect the unused definitions grouped by their type
+	byType := map[string]*DeadType{}
+	entry := func(name string) *DeadType {
+		if _, ok := byType[name]; !ok {
+			byType[name] = &DeadType{Name: name, Kind: kinds[name]}
+		}
+		return byType[name]
+	}
+
+	for _, f := range fields {
+		kind, ok := kinds[f.parent]
+		if !ok {
+			continue
+		}
+
+		if kind == "INPUT" {
+			if !openTypes[f.parent] && !literalInputFields[f.id] {
+				entry(f.parent).Fields = append(entry(f.parent).Fields, f.name)
+			}
+			continue
+		}
+
+		if !isSelected(f.parent, f.name) {
+			entry(f.parent).Fields = append(entry(f.parent).Fields, f.name)
+			continue
+		}
+
+		// selected enums can hold any of their values
+		if kinds[f.typ] == "ENUM" {
+			openTypes[f.typ] = true
+		}
+	}
+
+	// arguments follow the same rules as fields
+	err = db.StepQuery(ctx, `
+		SELECT type_fields.parent, type_fields.name, type_field_arguments.name
+		FROM type_field_arguments
+			JOIN type_fields ON type_fields.id = type_field_arguments.field
+		WHERE type_fields.internal = false
+			AND type_fields.document IS NULL
+	`, nil, func(row plugins.Row) {
+		parent, fieldName, argName := row.ColumnText(0), row.ColumnText(1), row.ColumnText(2)
+		if _, ok := kinds[parent]; !ok {
+			return
+		}
+
+		passed := passedArguments[parent+"."+fieldName+"."+argName]
+		for _, other := range related[parent] {
+			passed = passed || passedArguments[other+"."+fieldName+"."+argName]
+		}
+		if !passed {
+			entry(parent).Arguments = append(entry(parent).Arguments, fieldName+"("+argName+")")
+		}
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// and enum values that are never sent or received
+	err = db.StepQuery(ctx, `SELECT parent, value FROM enum_values`, nil, func(row plugins.Row) {
+		parent, value := row.ColumnText(0), row.ColumnText(1)
+		if _, ok := kinds[parent]; !ok || openTypes[parent] || literalValues[parent+"."+value] {
+			return
+		}
+		entry(parent).EnumValues = append(entry(parent).EnumValues, value)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// sort everything so the report is stable
+	for _, typ := range byType {
+		sort.Strings(typ.Fields)
+		sort.Strings(typ.Arguments)
+		sort.Strings(typ.EnumValues)
+		report.Types = append(report.Types, *typ)
+	}
+	sort.Slice(report.Types, func(i, j int) bool {
+		return report.Types[i].Name < report.Types[j].Name
+	})
+
+	return report, nil
+}
+
+// String renders the report for a terminal
+func (r DeadFieldsReport) String() string {
+	if len(r.Types) == 0 {
+		return "every field, argument, and enum value is used"
+	}
+
+	var out strings.Builder
+	for _, typ := range r.Types {
+		out.WriteString(typ.Name + "\n")
+		if len(typ.Fields) > 0 {
+			out.WriteString("  fields: " + strings.Join(typ.Fields, ", ") + "\n")
+		}
+		if len(typ.Arguments) > 0 {
+			out.WriteString("  arguments: " + strings.Join(typ.Arguments, ", ") + "\n")
+		}
+		if len(typ.EnumValues) > 0 {
+			out.WriteString("  values: " + strings.Join(typ.EnumValues, ", ") + "\n")
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
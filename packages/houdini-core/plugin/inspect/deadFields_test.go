@@ -0,0 +1,142 @@
+package inspect_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/inspect"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestDeadFields(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			interface Node {
+				id: ID!
+			}
+
+			enum Role {
+				ADMIN
+				MEMBER
+				GUEST
+			}
+
+			enum Sort {
+				NAME
+				EMAIL
+			}
+
+			enum Status {
+				ACTIVE
+				DISABLED
+			}
+
+			input UserFilter {
+				role: Role
+				name: String
+				nested: NestedFilter
+			}
+
+			input NestedFilter {
+				deep: String
+			}
+
+			type Query {
+				node(id: ID!): Node
+				users(filter: UserFilter, sort: Sort, limit: Int): [User!]!
+				search(filter: UserFilter): [User!]!
+				friends(first: Int, after: String, last: Int, before: String): UserConnection!
+			}
+
+			type User implements Node {
+				id: ID!
+				name: String!
+				email: String
+				status: Status
+				avatar(size: Int): String
+			}
+
+			type UserConnection {
+				edges: [UserEdge!]!
+				pageInfo: PageInfo!
+			}
+
+			type UserEdge {
+				cursor: String!
+				node: User
+			}
+
+			type PageInfo {
+				hasNextPage: Boolean!
+				hasPreviousPage: Boolean!
+				startCursor: String
+				endCursor: String
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			// generate the pagination documents so their selections count
+			require.NoError(t, p.AfterValidate(context.Background()))
+
+			report, err := inspect.DeadFields(context.Background(), p.DB)
+			require.NoError(t, err)
+			require.Equal(t, test.Extra["report"], report)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "lists unused definitions grouped by type",
+				Pass: true,
+				Input: []string{
+					`query AllUsers {
+						users(sort: NAME, filter: { name: "foo" }) {
+							name
+							status
+						}
+						node(id: "1") {
+							id
+						}
+					}`,
+					`query Search($filter: UserFilter) {
+						search(filter: $filter) {
+							name
+						}
+					}`,
+					`query Friends {
+						friends(first: 10) @paginate {
+							edges {
+								node {
+									name
+								}
+							}
+						}
+					}`,
+				},
+				Extra: map[string]any{
+					"report": inspect.DeadFieldsReport{
+						Types: []inspect.DeadType{
+							{
+								Name:      "Query",
+								Kind:      "OBJECT",
+								Arguments: []string{"users(limit)"},
+							},
+							{
+								Name:       "Sort",
+								Kind:       "ENUM",
+								EnumValues: []string{"EMAIL"},
+							},
+							{
+								Name:      "User",
+								Kind:      "OBJECT",
+								Fields:    []string{"avatar", "email"},
+								Arguments: []string{"avatar(size)"},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
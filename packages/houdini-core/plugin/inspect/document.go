@@ -0,0 +1,121 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// DocumentReport describes where a document is defined and how it's connected to the rest
+// of the project
+type DocumentReport struct {
+	Name          string `json:"name"`
+	Kind          string `json:"kind"`
+	TypeCondition string `json:"typeCondition,omitempty"`
+	// generated documents are created by houdini (list operations, pagination queries, ...)
+	Generated bool   `json:"generated"`
+	Filepath  string `json:"filepath,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	// the fragments (and component fields) that the document spreads
+	DependsOn []string `json:"dependsOn"`
+	// the documents that spread this one
+	UsedBy []string `json:"usedBy"`
+}
+
+// Document looks up a single document by name
+func Document[PluginConfig any](
+	ctx context.Context,
+	db plugins.DatabasePool[PluginConfig],
+	name string,
+) (DocumentReport, error) {
+	report := DocumentReport{DependsOn: []string{}, UsedBy: []string{}}
+
+	var id int64
+	found := false
+	err := db.StepQuery(ctx, `
+		SELECT
+			documents.id,
+			documents.kind,
+			COALESCE(documents.type_condition, ''),
+			documents.generated,
+			COALESCE(raw_documents.filepath, ''),
+			COALESCE(raw_documents.offset_line, 0),
+			COALESCE(raw_documents.offset_column, 0)
+		FROM documents
+			LEFT JOIN raw_documents ON raw_documents.id = documents.raw_document
+		WHERE documents.name = $name
+	`, map[string]any{"name": name}, func(row plugins.Row) {
+		found = true
+		id = row.ColumnInt64(0)
+		report.Name = name
+		report.Kind = row.ColumnText(1)
+		report.TypeCondition = row.ColumnText(2)
+		report.Generated = row.ColumnBool(3)
+		report.Filepath = row.ColumnText(4)
+		report.Line = row.ColumnInt(5)
+		report.Column = row.ColumnInt(6)
+	})
+	if err != nil {
+		return report, err
+	}
+	if !found {
+		return report, fmt.Errorf("could not find a document named %s", name)
+	}
+
+	err = db.StepQuery(ctx, `
+		SELECT DISTINCT depends_on FROM document_dependencies WHERE document = $id
+	`, map[string]any{"id": id}, func(row plugins.Row) {
+		report.DependsOn = append(report.DependsOn, row.ColumnText(0))
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = db.StepQuery(ctx, `
+		SELECT DISTINCT documents.name FROM document_dependencies
+			JOIN documents ON documents.id = document_dependencies.document
+		WHERE document_dependencies.depends_on = $name
+	`, map[string]any{"name": name}, func(row plugins.Row) {
+		report.UsedBy = append(report.UsedBy, row.ColumnText(0))
+	})
+	if err != nil {
+		return report, err
+	}
+
+	sort.Strings(report.DependsOn)
+	sort.Strings(report.UsedBy)
+	return report, nil
+}
+
+// String renders the report for a terminal
+func (r DocumentReport) String() string {
+	var out strings.Builder
+
+	kind := r.Kind
+	if r.TypeCondition != "" {
+		kind += " on " + r.TypeCondition
+	}
+	if r.Generated {
+		kind += ", generated"
+	}
+	out.WriteString(fmt.Sprintf("%s (%s)\n", r.Name, kind))
+
+	if r.Filepath != "" {
+		location := r.Filepath
+		if r.Line > 0 {
+			location += fmt.Sprintf(":%d:%d", r.Line, r.Column)
+		}
+		out.WriteString("  defined in: " + location + "\n")
+	}
+	if len(r.DependsOn) > 0 {
+		out.WriteString("  depends on: " + strings.Join(r.DependsOn, ", ") + "\n")
+	}
+	if len(r.UsedBy) > 0 {
+		out.WriteString("  used by: " + strings.Join(r.UsedBy, ", ") + "\n")
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,71 @@
+package inspect_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/inspect"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestDocument(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				user: User
+			}
+
+			type User {
+				id: ID!
+				name: String
+				friends: [User!]!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			report, err := inspect.Document(context.Background(), p.DB, test.Extra["document"].(string))
+			if !test.Pass {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.Extra["report"], report)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "reports where the document lives and what it's connected to",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name friends { ...FriendFields } }`,
+					`fragment FriendFields on User { name }`,
+				},
+				Filepaths: []string{"src/userInfo.gql", "src/userFields.gql", "src/friendFields.gql"},
+				Extra: map[string]any{
+					"document": "UserFields",
+					"report": inspect.DocumentReport{
+						Name:          "UserFields",
+						Kind:          "fragment",
+						TypeCondition: "User",
+						Filepath:      "src/userFields.gql",
+						DependsOn:     []string{"FriendFields"},
+						UsedBy:        []string{"UserInfo"},
+					},
+				},
+			},
+			{
+				Name: "unknown documents",
+				Pass: false,
+				Input: []string{
+					`query UserInfo { user { name } }`,
+				},
+				Extra: map[string]any{
+					"document": "Missing",
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,131 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// FieldUsageReport lists the documents that select a field of the schema
+type FieldUsageReport struct {
+	Field     string               `json:"field"`
+	Documents []FieldUsageDocument `json:"documents"`
+}
+
+// FieldUsageDocument is a single document that selects the field
+type FieldUsageDocument struct {
+	Document  string `json:"document"`
+	Kind      string `json:"kind"`
+	Generated bool   `json:"generated"`
+	Filepath  string `json:"filepath,omitempty"`
+	// the type the field was selected on when it's not the one that was asked about
+	// (an interface the type implements, or a member of the union/interface)
+	Via string `json:"via,omitempty"`
+}
+
+// FieldUsage returns every document that selects the given field. The field is passed as
+// Type.field. Like DeadFields, selecting the field on an abstract type counts for every
+// member (and the other way around).
+func FieldUsage[PluginConfig any](
+	ctx context.Context,
+	db plugins.DatabasePool[PluginConfig],
+	field string,
+) (FieldUsageReport, error) {
+	report := FieldUsageReport{Field: field, Documents: []FieldUsageDocument{}}
+
+	parent, name, ok := strings.Cut(field, ".")
+	if !ok || parent == "" || name == "" {
+		return report, fmt.Errorf("%s should look like Type.field", field)
+	}
+
+	exists := false
+	err := db.StepQuery(ctx, `SELECT 1 FROM type_fields WHERE id = $id`, map[string]any{
+		"id": field,
+	}, func(row plugins.Row) {
+		exists = true
+	})
+	if err != nil {
+		return report, err
+	}
+	if !exists {
+		return report, fmt.Errorf("%s is not a field in the schema", field)
+	}
+
+	// the field could have been selected on any of the related types
+	coordinates := []string{field}
+	err = db.StepQuery(ctx, `
+		SELECT member FROM possible_types WHERE type = $parent
+		UNION
+		SELECT type FROM possible_types WHERE member = $parent
+	`, map[string]any{"parent": parent}, func(row plugins.Row) {
+		coordinates = append(coordinates, row.ColumnText(0)+"."+name)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	seen := map[string]bool{}
+	for _, coordinate := range coordinates {
+		err = db.StepQuery(ctx, `
+			SELECT DISTINCT
+				documents.name,
+				documents.kind,
+				documents.generated,
+				COALESCE(raw_documents.filepath, '')
+			FROM selections
+				JOIN selection_refs ON selection_refs.child_id = selections.id
+				JOIN documents ON documents.id = selection_refs.document
+				LEFT JOIN raw_documents ON raw_documents.id = documents.raw_document
+			WHERE selections.kind = 'field' AND selections.type = $coordinate
+		`, map[string]any{"coordinate": coordinate}, func(row plugins.Row) {
+			document := row.ColumnText(0)
+			if seen[document] {
+				return
+			}
+			seen[document] = true
+
+			usage := FieldUsageDocument{
+				Document:  document,
+				Kind:      row.ColumnText(1),
+				Generated: row.ColumnBool(2),
+				Filepath:  row.ColumnText(3),
+			}
+			if coordinate != field {
+				usage.Via = coordinate
+			}
+			report.Documents = append(report.Documents, usage)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	sort.Slice(report.Documents, func(i, j int) bool {
+		return report.Documents[i].Document < report.Documents[j].Document
+	})
+	return report, nil
+}
+
+// String renders the report for a terminal
+func (r FieldUsageReport) String() string {
+	if len(r.Documents) == 0 {
+		return fmt.Sprintf("no document selects %s", r.Field)
+	}
+
+	var out strings.Builder
+	out.WriteString(r.Field + "\n")
+	for _, usage := range r.Documents {
+		line := fmt.Sprintf("  %s (%s)", usage.Document, usage.Kind)
+		if usage.Filepath != "" {
+			line += " " + usage.Filepath
+		}
+		if usage.Via != "" {
+			line += " via " + usage.Via
+		}
+		out.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
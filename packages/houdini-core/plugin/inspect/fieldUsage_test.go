@@ -0,0 +1,106 @@
+package inspect_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/inspect"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestFieldUsage(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			interface Node {
+				id: ID!
+			}
+
+			type Query {
+				user: User
+				node(id: ID!): Node
+				version: Int
+			}
+
+			type User implements Node {
+				id: ID!
+				name: String
+				email: String
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			report, err := inspect.FieldUsage(context.Background(), p.DB, test.Extra["field"].(string))
+			if !test.Pass {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.Extra["report"], report)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "documents that select the field",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+					`query Version { version }`,
+				},
+				Filepaths: []string{"src/userInfo.gql", "src/userFields.gql", "src/version.gql"},
+				Extra: map[string]any{
+					"field": "User.name",
+					"report": inspect.FieldUsageReport{
+						Field: "User.name",
+						Documents: []inspect.FieldUsageDocument{
+							{Document: "UserFields", Kind: "fragment", Filepath: "src/userFields.gql"},
+						},
+					},
+				},
+			},
+			{
+				Name: "selections on an interface count for its members",
+				Pass: true,
+				Input: []string{
+					`query Node { node(id: "1") { id } }`,
+				},
+				Filepaths: []string{"src/node.gql"},
+				Extra: map[string]any{
+					"field": "User.id",
+					"report": inspect.FieldUsageReport{
+						Field: "User.id",
+						Documents: []inspect.FieldUsageDocument{
+							{Document: "Node", Kind: "query", Filepath: "src/node.gql", Via: "Node.id"},
+						},
+					},
+				},
+			},
+			{
+				Name: "unused fields",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { name } }`,
+				},
+				Extra: map[string]any{
+					"field": "User.email",
+					"report": inspect.FieldUsageReport{
+						Field:     "User.email",
+						Documents: []inspect.FieldUsageDocument{},
+					},
+				},
+			},
+			{
+				Name: "fields that aren't in the schema",
+				Pass: false,
+				Input: []string{
+					`query UserInfo { user { name } }`,
+				},
+				Extra: map[string]any{
+					"field": "User.avatar",
+				},
+			},
+		},
+	})
+}
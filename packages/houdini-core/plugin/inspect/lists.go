@@ -0,0 +1,150 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// ListsReport lists every @list and @paginate field in the project
+type ListsReport struct {
+	Lists []List `json:"lists"`
+}
+
+// List describes a single list and what can be used to update it
+type List struct {
+	Name string `json:"name"`
+	// the document that marks the field and the field itself
+	Document string `json:"document"`
+	Field    string `json:"field"`
+	// the type of the records in the list
+	Type string `json:"type"`
+	// the type that owns the field: the root type for queries, the fragment's type otherwise
+	TargetType string `json:"targetType"`
+	Connection bool   `json:"connection"`
+	Paginate   string `json:"paginate,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+	Embedded   bool   `json:"embedded"`
+	// the fragments and directives houdini generated for the list
+	Operations []string `json:"operations"`
+}
+
+// Lists returns every list in the project along with the operations that target it
+func Lists[PluginConfig any](
+	ctx context.Context,
+	db plugins.DatabasePool[PluginConfig],
+) (ListsReport, error) {
+	report := ListsReport{Lists: []List{}}
+
+	err := db.StepQuery(ctx, `
+		SELECT
+			COALESCE(discovered_lists.name, ''),
+			documents.name,
+			COALESCE(selections.alias, selections.field_name),
+			discovered_lists.node_type,
+			discovered_lists.target_type,
+			discovered_lists.connection,
+			COALESCE(discovered_lists.paginate, ''),
+			discovered_lists.mode,
+			discovered_lists.embedded
+		FROM discovered_lists
+			JOIN documents ON documents.id = discovered_lists.document
+			JOIN selections ON selections.id = discovered_lists.list_field
+	`, nil, func(row plugins.Row) {
+		report.Lists = append(report.Lists, List{
+			Name:       row.ColumnText(0),
+			Document:   row.ColumnText(1),
+			Field:      row.ColumnText(2),
+			Type:       row.ColumnText(3),
+			TargetType: row.ColumnText(4),
+			Connection: row.ColumnBool(5),
+			Paginate:   row.ColumnText(6),
+			Mode:       row.ColumnText(7),
+			Embedded:   row.ColumnBool(8),
+			Operations: []string{},
+		})
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for i, list := range report.Lists {
+		// a paginated field doesn't need a name so it doesn't get any fragments
+		candidates := []string{}
+		if list.Name != "" {
+			for _, suffix := range []string{
+				graphql.ListOperationSuffixInsert,
+				graphql.ListOperationSuffixRemove,
+				graphql.ListOperationSuffixToggle,
+				graphql.ListOperationSuffixUpsert,
+				graphql.ListOperationSuffixUpdate,
+			} {
+				candidates = append(candidates, list.Name+suffix)
+			}
+		}
+		names, err := json.Marshal(candidates)
+		if err != nil {
+			return report, err
+		}
+
+		err = db.StepQuery(ctx, `
+			SELECT name FROM documents WHERE name IN (SELECT value FROM json_each($names))
+			UNION
+			SELECT '@' || name FROM directives WHERE name = $delete
+		`, map[string]any{
+			"names":  string(names),
+			"delete": list.Type + graphql.ListOperationSuffixDelete,
+		}, func(row plugins.Row) {
+			report.Lists[i].Operations = append(report.Lists[i].Operations, row.ColumnText(0))
+		})
+		if err != nil {
+			return report, err
+		}
+		sort.Strings(report.Lists[i].Operations)
+	}
+
+	sort.Slice(report.Lists, func(i, j int) bool {
+		if report.Lists[i].Name != report.Lists[j].Name {
+			return report.Lists[i].Name < report.Lists[j].Name
+		}
+		return report.Lists[i].Document < report.Lists[j].Document
+	})
+	return report, nil
+}
+
+// String renders the report for a terminal
+func (r ListsReport) String() string {
+	if len(r.Lists) == 0 {
+		return "the project doesn't have any lists"
+	}
+
+	var out strings.Builder
+	for _, list := range r.Lists {
+		name := list.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		out.WriteString(fmt.Sprintf("%s: %s.%s in %s\n", name, list.TargetType, list.Field, list.Document))
+
+		details := []string{"type: " + list.Type}
+		if list.Connection {
+			details = append(details, "connection")
+		}
+		if list.Paginate != "" {
+			details = append(details, "paginate: "+list.Paginate)
+		}
+		if list.Mode != "" {
+			details = append(details, "mode: "+list.Mode)
+		}
+		out.WriteString("  " + strings.Join(details, ", ") + "\n")
+		if len(list.Operations) > 0 {
+			out.WriteString("  operations: " + strings.Join(list.Operations, ", ") + "\n")
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
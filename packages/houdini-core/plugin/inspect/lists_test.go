@@ -0,0 +1,93 @@
+package inspect_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/inspect"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestLists(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				users: [User!]!
+				user: User
+			}
+
+			type User {
+				id: ID!
+				name: String
+				friends: [User!]!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			// the list operations are generated after validation
+			require.NoError(t, p.AfterValidate(context.Background()))
+
+			report, err := inspect.Lists(context.Background(), p.DB)
+			require.NoError(t, err)
+			require.Equal(t, test.Extra["report"], report)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "lists and the operations that target them",
+				Pass: true,
+				Input: []string{
+					`query AllUsers { users @list(name: "All_Users") { name } }`,
+					`fragment UserFriends on User { friends @list(name: "User_Friends") { name } }`,
+				},
+				Extra: map[string]any{
+					"report": inspect.ListsReport{
+						Lists: []inspect.List{
+							{
+								Name:       "All_Users",
+								Document:   "AllUsers",
+								Field:      "users",
+								Type:       "User",
+								TargetType: "Query",
+								Mode:       "Infinite",
+								Operations: []string{
+									"@User_delete",
+									"All_Users_insert",
+									"All_Users_remove",
+									"All_Users_toggle",
+									"All_Users_update",
+									"All_Users_upsert",
+								},
+							},
+							{
+								Name:       "User_Friends",
+								Document:   "UserFriends",
+								Field:      "friends",
+								Type:       "User",
+								TargetType: "User",
+								Mode:       "Infinite",
+								Embedded:   true,
+								Operations: []string{
+									"@User_delete",
+									"User_Friends_remove",
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "projects without lists",
+				Pass: true,
+				Input: []string{
+					`query AllUsers { users { name } }`,
+				},
+				Extra: map[string]any{
+					"report": inspect.ListsReport{Lists: []inspect.List{}},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,108 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/artifacts"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents/collected"
+	"code.houdinigraphql.com/plugins"
+)
+
+// SelectionReport is the selection of a document once every fragment has been merged in,
+// which is what ends up in its artifact
+type SelectionReport struct {
+	Document  string           `json:"document"`
+	Kind      string           `json:"kind"`
+	Selection []SelectionField `json:"selection"`
+	// the flattened document printed as graphql
+	Printed string `json:"printed"`
+}
+
+// SelectionField is a single entry of the flattened selection
+type SelectionField struct {
+	Name     string           `json:"name"`
+	Alias    string           `json:"alias,omitempty"`
+	Kind     string           `json:"kind"`
+	Type     string           `json:"type,omitempty"`
+	Hidden   bool             `json:"hidden,omitempty"`
+	Children []SelectionField `json:"children,omitempty"`
+}
+
+// Selection flattens the selection of a document the same way artifact generation does.
+// Fields that houdini adds on its own (keys, __typename, ...) are included and marked as
+// hidden when the user can't see them.
+func Selection(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	name string,
+) (SelectionReport, error) {
+	report := SelectionReport{Document: name, Selection: []SelectionField{}}
+
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	conn, err := db.Take(ctx)
+	if err != nil {
+		return report, err
+	}
+	defer db.Put(conn)
+
+	// without a task, every document is collected
+	docs, err := collected.CollectDocuments(ctx, db, conn, true)
+	if err != nil {
+		return report, err
+	}
+	doc, ok := docs.Selections[name]
+	if !ok {
+		return report, fmt.Errorf("could not find a document named %s", name)
+	}
+
+	selection, err := artifacts.FlattenSelection(
+		ctx,
+		docs,
+		name,
+		projectConfig.DefaultFragmentMasking,
+		true,
+	)
+	if err != nil {
+		return report, err
+	}
+
+	flattened := *doc
+	flattened.Selections = selection
+
+	report.Kind = doc.Kind
+	report.Selection = selectionFields(selection)
+	report.Printed = artifacts.PrintCollectedDocument(&flattened, true)
+	return report, nil
+}
+
+func selectionFields(selections []*collected.Selection) []SelectionField {
+	result := []SelectionField{}
+	for _, selection := range selections {
+		field := SelectionField{
+			Name:     selection.FieldName,
+			Kind:     selection.Kind,
+			Type:     selection.FieldType,
+			Hidden:   !selection.Visible,
+			Children: selectionFields(selection.Children),
+		}
+		if selection.Alias != nil && *selection.Alias != selection.FieldName {
+			field.Alias = *selection.Alias
+		}
+		if len(field.Children) == 0 {
+			field.Children = nil
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// String renders the report for a terminal
+func (r SelectionReport) String() string {
+	return r.Printed
+}
@@ -0,0 +1,89 @@
+package inspect_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/inspect"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestSelection(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				user: User
+			}
+
+			type User {
+				id: ID!
+				name: String
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			require.NoError(t, p.AfterValidate(context.Background()))
+
+			report, err := inspect.Selection(context.Background(), p.DB, test.Extra["document"].(string))
+			if !test.Pass {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.Extra["report"], report)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "fragments are merged into the selection",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user { ...UserFields } }`,
+					`fragment UserFields on User { name }`,
+				},
+				Extra: map[string]any{
+					"document": "UserInfo",
+					"report": inspect.SelectionReport{
+						Document: "UserInfo",
+						Kind:     "query",
+						Selection: []inspect.SelectionField{
+							{
+								Name: "user",
+								Kind: "field",
+								Type: "User",
+								Children: []inspect.SelectionField{
+									{Name: "__typename", Kind: "field", Type: "String", Hidden: true},
+									{Name: "id", Kind: "field", Type: "ID", Hidden: true},
+									{Name: "name", Kind: "field", Type: "String", Hidden: true},
+									{Name: "UserFields", Kind: "fragment"},
+								},
+							},
+						},
+						Printed: tests.Dedent(`
+							query UserInfo {
+							    user {
+							        __typename
+							        id
+							        name
+							        ...UserFields
+							    }
+							}
+						`),
+					},
+				},
+			},
+			{
+				Name: "unknown documents",
+				Pass: false,
+				Input: []string{
+					`query UserInfo { user { name } }`,
+				},
+				Extra: map[string]any{
+					"document": "Missing",
+				},
+			},
+		},
+	})
+}
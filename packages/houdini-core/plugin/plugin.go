@@ -1,17 +1,25 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"maps"
+	"sync"
 
 	"github.com/joho/godotenv"
 
 	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents"
 	"code.houdinigraphql.com/plugins"
 )
 
 type HoudiniCore struct {
 	plugins.Plugin[config.PluginConfig]
+
+	// the dependencies of the project that ship documents, read along with the config
+	packagesMu sync.Mutex
+	packages   []documents.FragmentPackage
+	loaded     bool
 }
 
 func (p *HoudiniCore) Name() string {
@@ -46,3 +54,27 @@ func (p *HoudiniCore) Environment(mode string) (map[string]string, error) {
 	// we're done
 	return result, nil
 }
+
+// FragmentPackages returns the dependencies of the project that ship documents. Like the
+// config, the list is read the first time it's needed and kept for as long as the plugin runs
+// so extraction and validation don't look through node_modules on every hook.
+func (p *HoudiniCore) FragmentPackages(ctx context.Context) ([]documents.FragmentPackage, error) {
+	p.packagesMu.Lock()
+	defer p.packagesMu.Unlock()
+	if p.loaded {
+		return p.packages, nil
+	}
+
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := documents.FragmentPackages(p.Fs, projectConfig.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	p.packages = packages
+	p.loaded = true
+	return packages, nil
+}
@@ -71,6 +71,18 @@ func (p *HoudiniCore) Schema(ctx context.Context) error {
 		}
 	}
 
+	// when the schema is loaded as part of a task, only the documents affected by the
+	// changes need to go through the rest of the pipeline. we need to know what the schema
+	// looked like before we replace it to figure that out
+	taskID := plugins.TaskIDFromContext(ctx)
+	var before houdiniSchema.Snapshot
+	if taskID != nil {
+		before, err = houdiniSchema.TakeSnapshot(ctx, p.DB, conn)
+		if err != nil {
+			return err
+		}
+	}
+
 	// all of the schema operations are done in a transaction
 	closeTx := p.DB.Transaction(conn)
 	commit := func(err error) error {
@@ -118,6 +130,29 @@ func (p *HoudiniCore) Schema(ctx context.Context) error {
 		return commit(err)
 	}
 
+	err = commit(nil)
+	if err != nil || taskID == nil {
+		return err
+	}
+
+	// add the documents that depend on what changed to the task
+	after, err := houdiniSchema.TakeSnapshot(ctx, p.DB, conn)
+	if err != nil {
+		return err
+	}
+	changes := before.Diff(after)
+	affected, err := houdiniSchema.MarkImpactedDocuments(ctx, p.DB, conn, changes, *taskID)
+	if err != nil {
+		return err
+	}
+	if logger, err := p.DB.Logger(ctx); err == nil {
+		logger.Info(
+			plugins.LogLevelSummary,
+			"  schema change: %d types and %d fields changed, %d documents affected",
+			len(changes.Types), len(changes.Fields), affected,
+		)
+	}
+
 	// we're done
-	return commit(nil)
+	return nil
 }
@@ -108,8 +108,8 @@ directive @plural on FRAGMENT_DEFINITION
 """@with  is used to provide arguments to fragments that have been marked with @arguments"""
 directive @with on FRAGMENT_SPREAD
 
-"""@cache is is used to specify cache rules for a query"""
-directive @cache(partial: Boolean, policy: CachePolicy) on QUERY
+"""@cache is is used to specify cache rules for a query or a single field"""
+directive @cache(lifetime: Int, partial: Boolean, policy: CachePolicy) on FIELD | QUERY
 
 """@mask_enable is used to to enable masking on fragment (overwriting the global conf)"""
 directive @mask_enable on FRAGMENT_SPREAD
@@ -135,6 +135,9 @@ directive @endpoint(fields: [String!], id: String, redirect: String) on MUTATION
 """@session writes the session from a mutation result: the field named by path becomes (or, with merge, is merged into) the user's session."""
 directive @session(merge: Boolean, path: String!) on MUTATION
 
+"""@transport picks the protocol a subscription is sent over (sse, graphql-ws, or multipart)"""
+directive @transport(type: String!) on SUBSCRIPTION
+
 """@componentField is used to mark a field as a component field"""
 directive @componentField(field: String, prop: String) on FIELD_DEFINITION | FRAGMENT_DEFINITION | INLINE_FRAGMENT
 
@@ -0,0 +1,322 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/plugins"
+)
+
+// Snapshot describes every type and field in the schema so two versions of it can be
+// compared. A type's signature doesn't include its fields: adding a field to a type shouldn't
+// affect the documents that select the type's other fields. The key fields it has are the
+// exception since they decide whether its records can be normalized.
+type Snapshot struct {
+	Types  map[string]string
+	Fields map[string]string
+	// the types used by the fields of every input object
+	inputRefs map[string][]string
+}
+
+// Changes are the types and fields that differ between two snapshots. Added and removed
+// definitions count as changed.
+type Changes struct {
+	Types  []string
+	Fields []string
+}
+
+// Empty returns true when the two snapshots describe the same schema
+func (c Changes) Empty() bool {
+	return len(c.Types) == 0 && len(c.Fields) == 0
+}
+
+// TakeSnapshot reads the schema that's currently in the database. It uses the connection it's
+// given so it sees the schema of any open transaction.
+func TakeSnapshot(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	conn plugins.Conn,
+) (Snapshot, error) {
+	types := map[string]*strings.Builder{}
+	fields := map[string]*strings.Builder{}
+	snapshot := Snapshot{
+		Types:     map[string]string{},
+		Fields:    map[string]string{},
+		inputRefs: map[string][]string{},
+	}
+
+	typeSignature := func(name string) *strings.Builder {
+		if _, ok := types[name]; !ok {
+			types[name] = &strings.Builder{}
+		}
+		return types[name]
+	}
+	// the names of the fields of every type
+	fieldNames := map[string][]string{}
+	fieldSignature := func(id string) *strings.Builder {
+		if _, ok := fields[id]; !ok {
+			fields[id] = &strings.Builder{}
+		}
+		return fields[id]
+	}
+
+	// every query is ordered so the signatures don't depend on the order rows were written in
+	queries := []struct {
+		query string
+		row   func(stmt plugins.Stmt)
+	}{
+		{
+			query: `SELECT name, kind, operation, description FROM types ORDER BY name`,
+			row: func(stmt plugins.Stmt) {
+				fmt.Fprintf(typeSignature(stmt.ColumnText(0)), "%s %s %q\n",
+					stmt.ColumnText(1), stmt.ColumnText(2), stmt.ColumnText(3))
+			},
+		},
+		{
+			query: `SELECT type, member FROM possible_types ORDER BY type, member`,
+			row: func(stmt plugins.Stmt) {
+				fmt.Fprintf(typeSignature(stmt.ColumnText(0)), "member %s\n", stmt.ColumnText(1))
+				fmt.Fprintf(typeSignature(stmt.ColumnText(1)), "implements %s\n", stmt.ColumnText(0))
+			},
+		},
+		{
+			query: `SELECT parent, value, description FROM enum_values ORDER BY parent, value`,
+			row: func(stmt plugins.Stmt) {
+				fmt.Fprintf(typeSignature(stmt.ColumnText(0)), "value %s %q\n",
+					stmt.ColumnText(1), stmt.ColumnText(2))
+			},
+		},
+		{
+			query: `
+				SELECT type_fields.id, type_fields.type, type_fields.type_modifiers,
+					type_fields.default_value, type_fields.description, types.kind, type_fields.parent,
+					type_fields.name
+				FROM type_fields
+					JOIN types ON types.name = type_fields.parent
+				ORDER BY type_fields.id
+			`,
+			row: func(stmt plugins.Stmt) {
+				fmt.Fprintf(fieldSignature(stmt.ColumnText(0)), "%s%s %q %q\n",
+					stmt.ColumnText(1), stmt.ColumnText(2), stmt.ColumnText(3), stmt.ColumnText(4))
+				fieldNames[stmt.ColumnText(6)] = append(fieldNames[stmt.ColumnText(6)], stmt.ColumnText(7))
+
+				// the fields of an input object are all sent together so they're part of
+				// the type too
+				if stmt.ColumnText(5) == "INPUT" {
+					parent := stmt.ColumnText(6)
+					fmt.Fprintf(typeSignature(parent), "field %s %s%s %q\n",
+						stmt.ColumnText(0), stmt.ColumnText(1), stmt.ColumnText(2), stmt.ColumnText(3))
+					snapshot.inputRefs[parent] = append(snapshot.inputRefs[parent], stmt.ColumnText(1))
+				}
+			},
+		},
+		{
+			query: `
+				SELECT field, name, type, type_modifiers, default_value
+				FROM type_field_arguments
+				ORDER BY field, name
+			`,
+			row: func(stmt plugins.Stmt) {
+				fmt.Fprintf(fieldSignature(stmt.ColumnText(0)), "argument %s %s%s %q\n",
+					stmt.ColumnText(1), stmt.ColumnText(2), stmt.ColumnText(3), stmt.ColumnText(4))
+			},
+		},
+	}
+
+	for _, q := range queries {
+		stmt, err := conn.Prepare(q.query)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		err = db.StepStatement(ctx, stmt, func() { q.row(stmt) })
+		stmt.Finalize()
+		if err != nil {
+			return Snapshot{}, err
+		}
+	}
+
+	// the key fields come from the config, which a schema reload doesn't touch
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for parent, names := range fieldNames {
+		keys := []string{}
+		for _, name := range names {
+			if name != "__typename" && projectConfig.IdentifiesRecord(parent, name) {
+				keys = append(keys, name)
+			}
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			fmt.Fprintf(typeSignature(parent), "keys %s\n", strings.Join(keys, ","))
+		}
+	}
+
+	for name, signature := range types {
+		snapshot.Types[name] = signature.String()
+	}
+	for id, signature := range fields {
+		snapshot.Fields[id] = signature.String()
+	}
+	return snapshot, nil
+}
+
+// Diff computes the types and fields that changed between the snapshot and a newer one. An
+// input object changes with any of the input objects it's built from since documents send
+// them together.
+func (before Snapshot) Diff(after Snapshot) Changes {
+	changedTypes := diffSignatures(before.Types, after.Types)
+	changedFields := diffSignatures(before.Fields, after.Fields)
+
+	for {
+		grew := false
+		for parent, refs := range after.inputRefs {
+			if changedTypes[parent] {
+				continue
+			}
+			for _, ref := range refs {
+				if changedTypes[ref] {
+					changedTypes[parent] = true
+					grew = true
+					break
+				}
+			}
+		}
+		if !grew {
+			break
+		}
+	}
+
+	return Changes{
+		Types:  sortedSet(changedTypes),
+		Fields: sortedSet(changedFields),
+	}
+}
+
+// MarkImpactedDocuments adds every document that depends on the changes to the task so the
+// rest of the pipeline only processes them. Along with the documents that use a changed type
+// or field directly, the task gets the documents that spread them and the fragments they
+// spread. It returns the number of documents in the task.
+func MarkImpactedDocuments(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	conn plugins.Conn,
+	changes Changes,
+	taskID string,
+) (int, error) {
+	changedTypes, err := json.Marshal(changes.Types)
+	if err != nil {
+		return 0, err
+	}
+	changedFields, err := json.Marshal(changes.Fields)
+	if err != nil {
+		return 0, err
+	}
+
+	mark, err := conn.Prepare(`
+		WITH RECURSIVE
+		changed_types(name) AS (SELECT value FROM json_each($types)),
+		changed_fields(id) AS (SELECT value FROM json_each($fields)),
+		seed(id) AS (
+			-- selections of changed fields, of fields that belong to a changed type or return
+			-- one, and inline fragments on a changed type
+			SELECT selection_refs.document FROM selection_refs
+				JOIN selections ON selections.id = selection_refs.child_id
+				LEFT JOIN type_fields ON type_fields.id = selections.type
+			WHERE selections.type IN (SELECT id FROM changed_fields)
+				OR (
+					selections.kind = 'inline_fragment'
+					AND selections.field_name IN (SELECT name FROM changed_types)
+				)
+				OR type_fields.parent IN (SELECT name FROM changed_types)
+				OR type_fields.type IN (SELECT name FROM changed_types)
+			UNION
+			SELECT id FROM documents WHERE type_condition IN (SELECT name FROM changed_types)
+			UNION
+			SELECT document FROM document_variables WHERE type IN (SELECT name FROM changed_types)
+			UNION
+			SELECT document FROM argument_values WHERE expected_type IN (SELECT name FROM changed_types)
+		),
+		up(name) AS (
+			SELECT name FROM documents WHERE id IN (SELECT id FROM seed)
+			UNION
+			SELECT documents.name FROM up
+				JOIN document_dependencies ON document_dependencies.depends_on = up.name
+				JOIN documents ON documents.id = document_dependencies.document
+		),
+		down(name) AS (
+			SELECT name FROM up
+			UNION
+			SELECT document_dependencies.depends_on FROM down
+				JOIN documents ON documents.name = down.name
+				JOIN document_dependencies ON document_dependencies.document = documents.id
+		)
+		UPDATE raw_documents SET current_task = $task_id
+		WHERE id IN (
+			SELECT raw_document FROM documents
+			WHERE name IN (SELECT name FROM down) AND raw_document IS NOT NULL
+		)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer mark.Finalize()
+
+	err = db.ExecStatement(mark, map[string]any{
+		"types":   string(changedTypes),
+		"fields":  string(changedFields),
+		"task_id": taskID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := conn.Prepare(`
+		SELECT COUNT(*) FROM documents
+			JOIN raw_documents ON raw_documents.id = documents.raw_document
+		WHERE raw_documents.current_task = $task_id AND documents.generated = false
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer count.Finalize()
+	count.SetText("$task_id", taskID)
+
+	documents := 0
+	err = db.StepStatement(ctx, count, func() {
+		documents = count.ColumnInt(0)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return documents, nil
+}
+
+func diffSignatures(before map[string]string, after map[string]string) map[string]bool {
+	changed := map[string]bool{}
+	for name, signature := range before {
+		if after[name] != signature {
+			changed[name] = true
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			changed[name] = true
+		}
+	}
+	return changed
+}
+
+func sortedSet(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -917,21 +917,23 @@ then the request will never be deduplicated.`,
 		}
 	}
 
-	// @cache(policy: CachePolicy, partial: Boolean) on QUERY
+	// @cache(policy: CachePolicy, partial: Boolean, lifetime: Int) on QUERY | FIELD
 	err = db.ExecStatement(statements.InsertInternalDirective, map[string]any{
 		"name":        graphql.CacheDirective,
-		"description": "@cache is is used to specify cache rules for a query",
+		"description": "@cache is is used to specify cache rules for a query or a single field",
 		"visible":     true,
 	})
 	if err != nil {
 		return err
 	}
-	err = db.ExecStatement(statements.InsertDirectiveLocation, map[string]any{
-		"directive": graphql.CacheDirective,
-		"location":  "QUERY",
-	})
-	if err != nil {
-		return err
+	for _, location := range []string{"QUERY", "FIELD"} {
+		err = db.ExecStatement(statements.InsertDirectiveLocation, map[string]any{
+			"directive": graphql.CacheDirective,
+			"location":  location,
+		})
+		if err != nil {
+			return err
+		}
 	}
 	err = db.ExecStatement(statements.InsertDirectiveArgument, map[string]any{
 		"directive": graphql.CacheDirective,
@@ -949,6 +951,14 @@ then the request will never be deduplicated.`,
 	if err != nil {
 		return err
 	}
+	err = db.ExecStatement(statements.InsertDirectiveArgument, map[string]any{
+		"directive": graphql.CacheDirective,
+		"name":      "lifetime",
+		"type":      "Int",
+	})
+	if err != nil {
+		return err
+	}
 
 	// @mask_enable on FRAGMENT_SPREAD
 	err = db.ExecStatement(statements.InsertInternalDirective, map[string]any{
@@ -1148,6 +1158,32 @@ then the request will never be deduplicated.`,
 		return err
 	}
 
+	// @transport(type: String!) on SUBSCRIPTION
+	err = db.ExecStatement(statements.InsertInternalDirective, map[string]any{
+		"name":        graphql.TransportDirective,
+		"description": "@transport picks the protocol a subscription is sent over (sse, graphql-ws, or multipart)",
+		"visible":     true,
+	})
+	if err != nil {
+		return err
+	}
+	err = db.ExecStatement(statements.InsertDirectiveLocation, map[string]any{
+		"directive": graphql.TransportDirective,
+		"location":  "SUBSCRIPTION",
+	})
+	if err != nil {
+		return err
+	}
+	err = db.ExecStatement(statements.InsertDirectiveArgument, map[string]any{
+		"directive":      graphql.TransportDirective,
+		"name":           "type",
+		"type":           "String",
+		"type_modifiers": "!",
+	})
+	if err != nil {
+		return err
+	}
+
 	// @componentField(prop: String, field: String) on FRAGMENT_DEFINITION | INLINE_FRAGMENT | FIELD_DEFINITION
 	err = db.ExecStatement(statements.InsertInternalDirective, map[string]any{
 		"name":        graphql.ComponentFieldDirective,
@@ -25,6 +25,7 @@ func schemaCore(t *testing.T, schemaContent string) (*plugin.HoudiniCore, plugin
 	db.SetProjectConfig(plugins.ProjectConfig{
 		ProjectRoot: "/project",
 		SchemaPath:  "schema.graphql",
+		DefaultKeys: []string{"id"},
 	})
 
 	conn, err := db.Take(context.Background())
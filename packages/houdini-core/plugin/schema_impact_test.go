@@ -0,0 +1,158 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// TestSchema_ImpactedDocuments verifies that reloading the schema as part of a task only adds
+// the documents that depend on what changed to the task.
+func TestSchema_ImpactedDocuments(t *testing.T) {
+	original := `
+		type Query {
+			version: Int
+			user: User
+			search(filter: Filter): [Result!]!
+			settings: Settings
+		}
+		type User {
+			id: ID!
+			name: String!
+			avatar: String
+		}
+		type Post {
+			id: ID!
+			title: String
+		}
+		type Comment {
+			id: ID!
+		}
+		type Settings {
+			theme: String
+		}
+		union Result = User | Post
+		input Filter {
+			term: String
+			inner: Inner
+		}
+		input Inner {
+			exact: Boolean
+		}
+	`
+
+	documents := map[string]string{
+		"version.gql":  `query Version { version }`,
+		"userName.gql": `fragment UserName on User { name }`,
+		"viewer.gql":   `query Viewer { user { ...UserName } }`,
+		"search.gql":   `query Search($filter: Filter) { search(filter: $filter) { ... on Post { title } } }`,
+		"avatar.gql":   `query Avatar { user { avatar } }`,
+		"settings.gql": `query Settings { settings { theme } }`,
+	}
+
+	table := []struct {
+		name     string
+		schema   string
+		expected []string
+	}{
+		{
+			name:     "nothing changed",
+			schema:   original,
+			expected: []string{},
+		},
+		{
+			name: "changed field",
+			schema: replaceOnce(t, original,
+				"name: String!", "name: String",
+			),
+			// the fragment changed and so did the query that spreads it
+			expected: []string{"userName.gql", "viewer.gql"},
+		},
+		{
+			name: "added field",
+			schema: replaceOnce(t, original,
+				"avatar: String", "avatar: String\nemail: String",
+			),
+			expected: []string{},
+		},
+		{
+			name: "adding id",
+			schema: replaceOnce(t, original,
+				"theme: String", "theme: String\nid: ID!",
+			),
+			// the records of the type can be normalized now
+			expected: []string{"settings.gql"},
+		},
+		{
+			name: "nested input changed",
+			schema: replaceOnce(t, original,
+				"exact: Boolean", "exact: Boolean\ncaseSensitive: Boolean",
+			),
+			expected: []string{"search.gql"},
+		},
+		{
+			name: "union member added",
+			schema: replaceOnce(t, original,
+				"union Result = User | Post", "union Result = User | Post | Comment",
+			),
+			expected: []string{"search.gql"},
+		},
+	}
+
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			ctx := context.Background()
+			core, db := schemaCore(t, original)
+			require.NoError(t, core.Schema(ctx))
+
+			conn, err := db.Take(ctx)
+			require.NoError(t, err)
+			insert, err := conn.Prepare(
+				"INSERT INTO raw_documents (content, filepath) VALUES ($content, $filepath)",
+			)
+			require.NoError(t, err)
+			for fp, content := range documents {
+				require.NoError(t, db.ExecStatement(insert, map[string]any{
+					"content":  content,
+					"filepath": fp,
+				}))
+			}
+			insert.Finalize()
+			db.Put(conn)
+			require.NoError(t, core.AfterExtract(ctx))
+
+			// load the new schema as part of a task
+			require.NoError(t, afero.WriteFile(
+				core.Fs,
+				filepath.Join("/project", "schema.graphql"),
+				[]byte(row.schema),
+				0644,
+			))
+			require.NoError(t, core.Schema(plugins.ContextWithTaskID(ctx, "schema")))
+
+			marked := []string{}
+			err = db.StepQuery(ctx,
+				"SELECT filepath FROM raw_documents WHERE current_task = 'schema' ORDER BY filepath",
+				nil,
+				func(row plugins.Row) {
+					marked = append(marked, row.ColumnText(0))
+				},
+			)
+			require.NoError(t, err)
+			require.ElementsMatch(t, row.expected, marked)
+		})
+	}
+}
+
+func replaceOnce(t *testing.T, schema string, old string, new string) string {
+	t.Helper()
+	replaced := strings.Replace(schema, old, new, 1)
+	require.NotEqual(t, schema, replaced, "schema doesn't contain %q", old)
+	return replaced
+}
@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"code.houdinigraphql.com/packages/houdini-core/plugin/documents"
+	"code.houdinigraphql.com/plugins"
+)
+
+// HookTrigger calls a hook on every plugin of the project and returns their results keyed by
+// plugin name, like Host.TriggerHook does
+type HookTrigger func(
+	ctx context.Context,
+	hook string,
+	payload map[string]any,
+	parallel bool,
+) (map[string]any, error)
+
+// RunTask brings the project up to date after some files changed, the same way the dev
+// server does when it sees a save. The documents in the files, the documents that spread
+// them, and the fragments they spread are extracted, validated and generated again. Nothing
+// else is touched. Paths are relative to the project root. A file that doesn't exist anymore
+// loses its documents. The hooks after extraction go through trigger so every plugin of the
+// project takes part in the task. It returns the files that were generated.
+func (p *HoudiniCore) RunTask(
+	ctx context.Context,
+	taskID string,
+	files []string,
+	trigger HookTrigger,
+) ([]string, error) {
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the documents of a file are recorded under the path the walker reports for it
+	walker, err := projectConfig.Walker()
+	if err != nil {
+		return nil, err
+	}
+	reported := make([]string, 0, len(files))
+	for _, fp := range files {
+		rel, err := walker.ReportedPath(p.Fs, projectConfig.ProjectRoot, fp)
+		if err != nil {
+			return nil, err
+		}
+		reported = append(reported, rel)
+	}
+	changedFiles, err := json.Marshal(reported)
+	if err != nil {
+		return nil, err
+	}
+
+	// the documents the files defined before they changed. anything that spreads one of
+	// them has to be validated again, even if the document is gone
+	previous := []string{}
+	err = p.DB.StepQuery(ctx, `
+		SELECT documents.name FROM documents
+			JOIN raw_documents ON raw_documents.id = documents.raw_document
+		WHERE raw_documents.filepath IN (SELECT value FROM json_each($files))
+			AND documents.generated = false
+	`, map[string]any{"files": string(changedFiles)}, func(row plugins.Row) {
+		previous = append(previous, row.ColumnText(0))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filepaths := make([]string, 0, len(files))
+	for _, fp := range files {
+		filepaths = append(filepaths, filepath.Join(projectConfig.ProjectRoot, filepath.FromSlash(fp)))
+	}
+	packages, err := p.FragmentPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := documents.ExtractFromFilepaths(ctx, p.DB, p.Fs, packages, filepaths); err != nil {
+		return nil, err
+	}
+
+	// whatever happens, the next task shouldn't see this one's documents
+	defer p.execStatement(ctx, `
+		UPDATE raw_documents SET current_task = NULL WHERE current_task = $task_id
+	`, map[string]any{"task_id": taskID})
+
+	err = p.execStatement(ctx, `
+		UPDATE raw_documents SET current_task = $task_id
+		WHERE filepath IN (SELECT value FROM json_each($files))
+	`, map[string]any{"task_id": taskID, "files": string(changedFiles)})
+	if err != nil {
+		return nil, err
+	}
+
+	taskCtx := plugins.ContextWithTaskID(ctx, taskID)
+	marked := 0
+	err = p.DB.StepQuery(taskCtx, `
+		SELECT COUNT(*) FROM raw_documents WHERE current_task = $task_id
+	`, nil, func(row plugins.Row) {
+		marked = row.ColumnInt(0)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the files never had documents and still don't
+	if marked == 0 && len(previous) == 0 {
+		return nil, nil
+	}
+
+	if _, err := trigger(taskCtx, "AfterExtract", nil, false); err != nil {
+		return nil, err
+	}
+
+	// the documents that were just loaded know what they depend on so we can add the
+	// rest of the task
+	previousNames, err := json.Marshal(previous)
+	if err != nil {
+		return nil, err
+	}
+	err = p.execStatement(ctx, `
+		WITH RECURSIVE
+		seed(name) AS (
+			SELECT documents.name FROM documents
+				JOIN raw_documents ON raw_documents.id = documents.raw_document
+			WHERE raw_documents.current_task = $task_id
+			UNION
+			SELECT value FROM json_each($previous)
+		),
+		up(name) AS (
+			SELECT name FROM seed
+			UNION
+			SELECT documents.name FROM up
+				JOIN document_dependencies ON document_dependencies.depends_on = up.name
+				JOIN documents ON documents.id = document_dependencies.document
+		),
+		down(name) AS (
+			SELECT name FROM seed
+			UNION
+			SELECT document_dependencies.depends_on FROM down
+				JOIN documents ON documents.name = down.name
+				JOIN document_dependencies ON document_dependencies.document = documents.id
+		)
+		UPDATE raw_documents SET current_task = $task_id
+		WHERE id IN (
+			SELECT raw_document FROM documents
+			WHERE (name IN (SELECT name FROM up) OR name IN (SELECT name FROM down))
+				AND raw_document IS NOT NULL
+		)
+	`, map[string]any{
+		"task_id":  taskID,
+		"previous": string(previousNames),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if logger, err := p.DB.Logger(ctx); err == nil {
+		affected := 0
+		err = p.DB.StepQuery(taskCtx, `
+			SELECT COUNT(*) FROM documents
+				JOIN raw_documents ON raw_documents.id = documents.raw_document
+			WHERE raw_documents.current_task = $task_id AND documents.generated = false
+		`, nil, func(row plugins.Row) {
+			affected = row.ColumnInt(0)
+		})
+		if err == nil {
+			logger.Info(
+				plugins.LogLevelSummary,
+				"  task %s: %d files changed, %d documents affected",
+				taskID, len(files), affected,
+			)
+		}
+	}
+
+	// the task has every document we need to process
+	for _, hook := range []string{"BeforeValidate", "Validate", "AfterValidate"} {
+		if _, err := trigger(taskCtx, hook, nil, hook == "Validate"); err != nil {
+			return nil, err
+		}
+	}
+
+	generated := []string{}
+	for _, hook := range []string{"GenerateDocuments", "GenerateRuntime"} {
+		results, err := trigger(taskCtx, hook, nil, hook == "GenerateDocuments")
+		if err != nil {
+			return nil, err
+		}
+		// the results went through json so the lists of files are lists of anything
+		for _, name := range slices.Sorted(maps.Keys(results)) {
+			files, _ := results[name].([]any)
+			for _, file := range files {
+				if fp, ok := file.(string); ok {
+					generated = append(generated, fp)
+				}
+			}
+		}
+	}
+
+	return generated, nil
+}
+
+// execStatement runs a single statement on its own connection. ExecStatement doesn't bind
+// $task_id from the context so the arguments have to include it.
+func (p *HoudiniCore) execStatement(ctx context.Context, query string, args map[string]any) error {
+	conn, err := p.DB.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.DB.Put(conn)
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Finalize()
+	return p.DB.ExecStatement(stmt, args)
+}
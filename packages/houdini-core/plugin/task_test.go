@@ -0,0 +1,167 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestRunTask(t *testing.T) {
+	ctx := context.Background()
+
+	documents := []string{
+		`query UserInfo { user { ...UserFields } }`,
+		`fragment UserFields on User { name }`,
+		`query Version { version }`,
+	}
+	filepaths := []string{"src/userInfo.gql", "src/userFields.gql", "src/version.gql"}
+
+	// poison replaces every artifact so we can tell which ones a task wrote again
+	poison := func(t *testing.T, p *plugin.HoudiniCore) {
+		projectConfig, err := p.DB.ProjectConfig(ctx)
+		require.NoError(t, err)
+		for _, name := range []string{"UserInfo", "UserFields", "Version"} {
+			require.NoError(t, afero.WriteFile(p.Fs, projectConfig.ArtifactPath(name), []byte("stale"), 0644))
+		}
+	}
+
+	readArtifact := func(t *testing.T, p *plugin.HoudiniCore, name string) string {
+		projectConfig, err := p.DB.ProjectConfig(ctx)
+		require.NoError(t, err)
+		content, err := afero.ReadFile(p.Fs, projectConfig.ArtifactPath(name))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query {
+				version: Int
+				user: User
+			}
+
+			type User {
+				id: ID!
+				name: String
+				email: String
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			require.NoError(t, p.AfterExtract(ctx))
+			require.NoError(t, p.Validate(ctx))
+			require.NoError(t, p.AfterValidate(ctx))
+			_, err := p.GenerateDocuments(ctx)
+			require.NoError(t, err)
+			poison(t, p)
+
+			test.Extra["check"].(func(t *testing.T, p *plugin.HoudiniCore))(t, p)
+
+			// the next task starts from a clean slate
+			inTask := 0
+			err = p.DB.StepQuery(ctx,
+				"SELECT COUNT(*) FROM raw_documents WHERE current_task IS NOT NULL",
+				nil,
+				func(row plugins.Row) { inTask = row.ColumnInt(0) },
+			)
+			require.NoError(t, err)
+			require.Zero(t, inTask)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name:      "a changed fragment regenerates the documents that spread it",
+				Pass:      true,
+				Input:     documents,
+				Filepaths: filepaths,
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore) {
+						require.NoError(t, afero.WriteFile(
+							p.Fs,
+							"/project/src/userFields.gql",
+							[]byte(`fragment UserFields on User { name email }`),
+							0644,
+						))
+
+						generated, err := p.RunTask(ctx, "1", []string{"src/userFields.gql"}, coreHooks(p))
+						require.NoError(t, err)
+						require.NotEmpty(t, generated)
+
+						require.Contains(t, readArtifact(t, p, "UserFields"), "email")
+						require.Contains(t, readArtifact(t, p, "UserInfo"), "email")
+						require.Equal(t, "stale", readArtifact(t, p, "Version"))
+					},
+				},
+			},
+			{
+				Name:      "spreads of a deleted fragment are validated again",
+				Pass:      true,
+				Input:     documents,
+				Filepaths: filepaths,
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore) {
+						require.NoError(t, p.Fs.Remove("/project/src/userFields.gql"))
+
+						_, err := p.RunTask(ctx, "1", []string{"src/userFields.gql"}, coreHooks(p))
+						require.ErrorContains(t, err, "UserFields")
+					},
+				},
+			},
+			{
+				Name:      "files without documents don't start a task",
+				Pass:      true,
+				Input:     documents,
+				Filepaths: filepaths,
+				Extra: map[string]any{
+					"check": func(t *testing.T, p *plugin.HoudiniCore) {
+						require.NoError(t, afero.WriteFile(
+							p.Fs,
+							"/project/src/notes.txt",
+							[]byte("nothing to see here"),
+							0644,
+						))
+
+						generated, err := p.RunTask(ctx, "1", []string{"src/notes.txt"}, coreHooks(p))
+						require.NoError(t, err)
+						require.Empty(t, generated)
+						require.Equal(t, "stale", readArtifact(t, p, "UserInfo"))
+					},
+				},
+			},
+		},
+	})
+}
+
+// coreHooks triggers the hooks on houdini-core, the only plugin of the test. The generated
+// files come back the way they would after going through json.
+func coreHooks(p *plugin.HoudiniCore) plugin.HookTrigger {
+	return func(ctx context.Context, hook string, _ map[string]any, _ bool) (map[string]any, error) {
+		var files []string
+		var err error
+		switch hook {
+		case "AfterExtract":
+			err = p.AfterExtract(ctx)
+		case "BeforeValidate":
+			err = p.BeforeValidate(ctx)
+		case "Validate":
+			err = p.Validate(ctx)
+		case "AfterValidate":
+			err = p.AfterValidate(ctx)
+		case "GenerateDocuments":
+			files, err = p.GenerateDocuments(ctx)
+		case "GenerateRuntime":
+			files, err = p.GenerateRuntime(ctx)
+		}
+
+		decoded := []any{}
+		for _, file := range files {
+			decoded = append(decoded, file)
+		}
+		return map[string]any{p.Name(): decoded}, err
+	}
+}
@@ -50,6 +50,8 @@ func (p *HoudiniCore) Validate(ctx context.Context) error {
 		documents.ValidateRefetchDirective,
 		documents.ValidateEndpointDirective,
 		documents.ValidateSessionDirective,
+		documents.ValidateCacheRules,
+		documents.ValidateSubscriptionTransport,
 		lists.DiscoverListsThenValidate,
 		lists.ValidateConflictingParentIDAllLists,
 		lists.ValidateConflictingPrependAppend,
@@ -73,6 +75,18 @@ func (p *HoudiniCore) Validate(ctx context.Context) error {
 		}(rule)
 	}
 
+	// the usage checks need to look at the project's files so they don't fit the rule signature
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		packages, err := p.FragmentPackages(ctx)
+		if err != nil {
+			errs.Append(plugins.WrapError(err))
+			return
+		}
+		documents.ValidateDocumentUsage(ctx, p.DB, p.Fs, packages, errs)
+	}()
+
 	// wait for the validation to finish
 	wg.Wait()
 
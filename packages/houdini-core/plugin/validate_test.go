@@ -2878,6 +2878,30 @@ func TestValidate_Houdini(t *testing.T) {
 					}`,
 				},
 			},
+			{
+				Name: "@session mutations agree on the type of a key (positive)",
+				Pass: true,
+				Input: []string{
+					`mutation LoginA @session(path: "addFriend.friend") {
+						addFriend { friend { id firstName } }
+					}`,
+					`mutation LoginB @session(path: "updateNode") {
+						updateNode { id }
+					}`,
+				},
+			},
+			{
+				Name: "@session mutations disagree on the type of a key (negative)",
+				Pass: false,
+				Input: []string{
+					`mutation LoginA @session(path: "addFriend") {
+						addFriend { friend { id } }
+					}`,
+					`mutation LoginB @session(path: "addFriend.friend") {
+						addFriend { friend { friend: bestFriend { id } } }
+					}`,
+				},
+			},
 			{
 				Name: "@session on a query (negative)",
 				Pass: false,
@@ -2887,6 +2911,169 @@ func TestValidate_Houdini(t *testing.T) {
 					}`,
 				},
 			},
+			{
+				Name: "@cache with a policy and lifetime on a field (positive)",
+				Pass: true,
+				Input: []string{
+					`query UserInfo @cache(policy: CacheOrNetwork, lifetime: 60000) {
+						user(name: "x") {
+							id
+							avatarURL @cache(policy: NetworkOnly, lifetime: 1000)
+						}
+					}`,
+				},
+			},
+			{
+				Name: "@cache(partial:) on a field (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo {
+						user(name: "x") {
+							id
+							avatarURL @cache(partial: true)
+						}
+					}`,
+				},
+			},
+			{
+				Name: "@cache with a negative lifetime (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo {
+						user(name: "x") {
+							id
+							avatarURL @cache(lifetime: -1)
+						}
+					}`,
+				},
+			},
+			{
+				Name: "@cache with a lifetime from a variable (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo($lifetime: Int) {
+						user(name: "x") {
+							id
+							avatarURL @cache(lifetime: $lifetime)
+						}
+					}`,
+				},
+			},
+			{
+				Name: "cache rules in the type config (positive)",
+				Pass: true,
+				Input: []string{
+					`query UserInfo { user(name: "x") { id } }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.TypeConfig = map[string]plugins.TypeConfig{
+						"User": {
+							Cache: plugins.CacheRules{Lifetime: 1000},
+							FieldCache: map[string]plugins.CacheRules{
+								"avatarURL": {Policy: "NetworkOnly"},
+							},
+						},
+					}
+				},
+			},
+			{
+				Name: "cache rules for an unknown field (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo { user(name: "x") { id } }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.TypeConfig = map[string]plugins.TypeConfig{
+						"User": {
+							FieldCache: map[string]plugins.CacheRules{
+								"nope": {Lifetime: 1000},
+							},
+						},
+					}
+				},
+			},
+			{
+				Name: "cache rules with an unknown policy (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo { user(name: "x") { id } }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.TypeConfig = map[string]plugins.TypeConfig{
+						"User": {
+							Cache: plugins.CacheRules{Policy: "Sometimes"},
+						},
+					}
+				},
+			},
+			{
+				Name: "@transport on a subscription (positive)",
+				Pass: true,
+				Input: []string{
+					`subscription NewMessage @transport(type: "sse") { newMessage }`,
+				},
+			},
+			{
+				Name: "@transport with an unknown transport (negative)",
+				Pass: false,
+				Input: []string{
+					`subscription NewMessage @transport(type: "carrier-pigeon") { newMessage }`,
+				},
+			},
+			{
+				Name: "@transport on a query (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo @transport(type: "sse") { user(name: "x") { id } }`,
+				},
+			},
+			{
+				Name: "subscription transports in the config (positive)",
+				Pass: true,
+				Input: []string{
+					`subscription NewMessage { newMessage }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.SubscriptionTransport = plugins.SubscriptionTransportGraphQLWS
+					config.SubscriptionTransports = map[string]plugins.SubscriptionTransport{
+						"NewMessage": plugins.SubscriptionTransportSSE,
+					}
+				},
+			},
+			{
+				Name: "unknown default subscription transport in the config (negative)",
+				Pass: false,
+				Input: []string{
+					`subscription NewMessage { newMessage }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.SubscriptionTransport = "carrier-pigeon"
+				},
+			},
+			{
+				Name: "subscription transport for an unknown operation (negative)",
+				Pass: false,
+				Input: []string{
+					`subscription NewMessage { newMessage }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.SubscriptionTransports = map[string]plugins.SubscriptionTransport{
+						"OldMessage": plugins.SubscriptionTransportSSE,
+					}
+				},
+			},
+			{
+				Name: "subscription transport for a query (negative)",
+				Pass: false,
+				Input: []string{
+					`query UserInfo { user(name: "x") { id } }`,
+				},
+				ProjectConfig: func(config *plugins.ProjectConfig) {
+					config.SubscriptionTransports = map[string]plugins.SubscriptionTransport{
+						"UserInfo": plugins.SubscriptionTransportSSE,
+					}
+				},
+			},
 		},
 	})
 }
@@ -2966,3 +3153,59 @@ func TestValidate_DeleteDirectiveDocumentsSurvive(t *testing.T) {
 		},
 	})
 }
+
+// conflicting session keys are reported when one of the mutations that writes them is part of
+// the task, the same as every other rule
+func TestValidate_SessionKeysInTask(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniCore]{
+		Schema: `
+			type Query { viewer: User }
+			type Mutation {
+				login: LoginOutput!
+				refresh: User
+			}
+			type LoginOutput { user: User! token: String! }
+			type User { id: ID! token: Int }
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniCore, test tests.Test[config.PluginConfig]) {
+			ctx := context.Background()
+			err := p.DB.ExecQuery(ctx, `
+				UPDATE raw_documents SET current_task = 'task'
+				WHERE filepath IN (SELECT value FROM json_each($filepaths))
+			`, map[string]any{"filepaths": test.Extra["task"]})
+			require.NoError(t, err)
+
+			err = p.Validate(plugins.ContextWithTaskID(ctx, "task"))
+			if !test.Extra["reported"].(bool) {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, `session key "token"`)
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				// the full validation in the setup reports the conflict
+				Name: "conflicts outside of the task aren't reported",
+				Pass: false,
+				Input: []string{
+					`mutation Login @session(path: "login") { login { token } }`,
+					`mutation Refresh @session(path: "refresh") { refresh { token } }`,
+					`query Viewer { viewer { id } }`,
+				},
+				Filepaths: []string{"login.gql", "refresh.gql", "viewer.gql"},
+				Extra:     map[string]any{"task": `["viewer.gql"]`, "reported": false},
+			},
+			{
+				Name: "conflicts with a mutation in the task are reported",
+				Pass: false,
+				Input: []string{
+					`mutation Login @session(path: "login") { login { token } }`,
+					`mutation Refresh @session(path: "refresh") { refresh { token } }`,
+					`query Viewer { viewer { id } }`,
+				},
+				Filepaths: []string{"login.gql", "refresh.gql", "viewer.gql"},
+				Extra:     map[string]any{"task": `["refresh.gql"]`, "reported": true},
+			},
+		},
+	})
+}
@@ -1,11 +1,15 @@
 import { deepEquals } from 'houdini/runtime'
 import type { ClientPluginContext } from 'houdini/runtime/documentStore'
 import { ArtifactKind, DataSource } from 'houdini/runtime/types'
-import type { GraphQLError } from 'houdini/runtime/types'
+import type { GraphQLError, SubscriptionTransports } from 'houdini/runtime/types'
 
 import { documentPlugin } from './utils/index.js'
 
-export function subscription(factory: SubscriptionHandler) {
+export function subscription(factory: SubscriptionHandler | SubscriptionHandlers) {
+	// a single handler is used for every subscription
+	const handlers: SubscriptionHandlers =
+		typeof factory === 'function' ? { default: factory } : factory
+
 	return documentPlugin(ArtifactKind.Subscription, () => {
 		// the unsubscribe hook for the active subscription
 		let clearSubscription: null | (() => void) = null
@@ -47,10 +51,24 @@ export function subscription(factory: SubscriptionHandler) {
 				// we need to use this as the new check value
 				check = checkValue
 
+				// the artifact knows which protocol the subscription has to be sent over
+				const transport =
+					'transport' in ctx.artifact && ctx.artifact.transport
+						? ctx.artifact.transport
+						: 'default'
+				const handler = handlers[transport] ?? handlers.default
+				if (!handler) {
+					throw new Error(
+						`No subscription handler was provided for the ${transport} transport (used by ${ctx.name})`
+					)
+				}
+				const key = handlers[transport] ? transport : 'default'
+
 				// if the session has changed then recreate the client
-				if (sessionChange) {
-					await loadClient(ctx, factory)
+				if (sessionChange || !clients[key]) {
+					await loadClient(ctx, key, handler)
 				}
+				const client = clients[key]!
 
 				// if we got this far, we need to clear the subscription before we
 				// create a new one
@@ -102,6 +120,12 @@ export function subscription(factory: SubscriptionHandler) {
 
 export type SubscriptionHandler = (ctx: ClientPluginContext) => SubscriptionClient
 
+// one handler per transport. subscriptions without a transport (or with one that isn't
+// listed) fall back to the default handler
+export type SubscriptionHandlers = Partial<Record<SubscriptionTransports, SubscriptionHandler>> & {
+	default?: SubscriptionHandler
+}
+
 export type SubscriptionClient = {
 	subscribe: (
 		payload: {
@@ -119,36 +143,37 @@ export type SubscriptionClient = {
 }
 
 // if 2 subscriptions start at the same time we don't want to create
-// multiple clients. We'll make a global promise that we will use to
+// multiple clients. We'll make a global promise (per transport) that we will use to
 // coordinate across invocations of the plugin. This is only safe to do
 // without considering user-sessions on the server because this plugin
 // ensures that it only runs on the browser in the start phase
-let pendingCreate: Promise<void> | null = null
+const pendingCreate: Record<string, Promise<void> | null> = {}
 
-// the actual client
-let client: SubscriptionClient
+// the actual clients, keyed by transport
+const clients: Record<string, SubscriptionClient> = {}
 
 function loadClient(
 	ctx: ClientPluginContext,
+	key: string,
 	factory: (ctx: ClientPluginContext) => SubscriptionClient
 ): Promise<void> {
 	// if we are currently loading a client, just wait for that
-	if (pendingCreate) {
-		return pendingCreate
+	if (pendingCreate[key]) {
+		return pendingCreate[key]!
 	}
 
 	// we aren't currently loading the client so we're safe to do that
 	// and register the effort to coordinate other subscriptions
-	pendingCreate = new Promise((resolve) => {
+	pendingCreate[key] = new Promise((resolve) => {
 		// update the client reference
-		client = factory(ctx)
+		clients[key] = factory(ctx)
 
 		// we're done
 		resolve()
 
 		// we're done with the create
-		pendingCreate = null
+		pendingCreate[key] = null
 	})
 
-	return pendingCreate
+	return pendingCreate[key]!
 }
@@ -12,7 +12,7 @@ import { existsSync } from 'node:fs'
 import { fileURLToPath } from 'node:url'
 import { afterAll, describe, expect, test, vi } from 'vitest'
 
-import { schema_version } from '../../houdini/src/lib/database'
+import { read_schema_version, schema_version } from '../../houdini/src/lib/database'
 import { openDb, type Db } from '../../houdini/src/lib/db'
 import {
 	fragment_arguments,
@@ -31,8 +31,7 @@ const DB_PATH = fileURLToPath(new URL('../../../e2e/react/.houdini/db.sqlite', i
 let db: Db | undefined
 if (existsSync(DB_PATH)) {
 	const candidate = await openDb(DB_PATH)
-	const stamped = candidate.get<{ user_version: number }>('PRAGMA user_version')?.user_version
-	if (stamped === schema_version) {
+	if (read_schema_version(candidate) === schema_version) {
 		db = candidate
 	} else {
 		candidate.close()
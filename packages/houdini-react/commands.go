@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	"code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins"
+)
+
+// the binary doubles as a few commands that look at the database left behind by codegen
+var commands = plugins.Commands{
+	"export-routes":  exportRoutes,
+	"analyze-routes": analyzeRoutes,
+}
+
+// exportRoutes writes the route manifest, prerender plan, and sitemap skeleton for the project
+func exportRoutes(args []string) error {
+	flags := flag.NewFlagSet("export-routes", flag.ExitOnError)
+	databasePath := flags.String("database", plugins.DefaultDatabasePath, "the path to the project's database")
+	outDir := flags.String("out", "build/routes", "the directory to write the exported files to")
+	format := flags.String("format", plugin.ExportFormatJSON, "the format of the route manifest (json or yaml)")
+	baseURL := flags.String("base-url", "https://example.com", "the origin used for the urls in sitemap.xml")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	p, db, err := openPlugin(*databasePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	files, err := p.ExportRoutes(context.Background(), *outDir, *format, *baseURL)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Println(file)
+	}
+	return nil
+}
+
+// analyzeRoutes prints the waterfalls and duplicate fields of every route and can write the
+// merged preload plan of each route to a file
+func analyzeRoutes(args []string) error {
+	flags := flag.NewFlagSet("analyze-routes", flag.ExitOnError)
+	databasePath := flags.String("database", plugins.DefaultDatabasePath, "the path to the project's database")
+	asJSON := flags.Bool("json", false, "print the report as json")
+	preload := flags.String("preload", "", "write the preload plan of every route to this file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	p, db, err := openPlugin(*databasePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := p.AnalyzePrefetch(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *preload != "" {
+		contents, err := json.MarshalIndent(report.PreloadPlan(), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*preload, append(contents, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	fmt.Println(report)
+	return nil
+}
+
+// openPlugin points the plugin at the database codegen left behind
+func openPlugin(path string) (*plugin.HoudiniReact, plugins.DatabasePool[config.PluginConfig], error) {
+	db, err := plugins.OpenExistingPool[config.PluginConfig](path)
+	if err != nil {
+		return nil, db, err
+	}
+
+	p := &plugin.HoudiniReact{}
+	p.SetFilesystem(afero.NewOsFs())
+	p.SetDatabase(db)
+	return p, db, nil
+}
@@ -2,6 +2,7 @@ import { fs, path } from 'houdini'
 import {
 	app_component_path,
 	adapter_config_path,
+	client_boundaries_path,
 	plugin_dir,
 	client_build_directory,
 } from 'houdini/router/conventions'
@@ -9,7 +10,7 @@ import { load_manifest, type ProjectManifest } from 'houdini/router/manifest'
 import { type RouterManifest, type RouterPageManifest } from 'houdini/router/types'
 import { VitePluginContext } from 'houdini/vite'
 import { lookup } from 'mrmime'
-import { existsSync, mkdirSync, statSync, writeFileSync } from 'node:fs'
+import { existsSync, mkdirSync, readFileSync, statSync, writeFileSync } from 'node:fs'
 import { createRequire } from 'node:module'
 import type * as React from 'react'
 import { build, type BuildOptions, type ConfigEnv, type Connect } from 'vite'
@@ -77,6 +78,8 @@ export default function (ctx: VitePluginContext): PluginOption {
 	let devServer = false
 	let isSSRBuild = false
 	let cfCache: ComponentFieldRow[] | null = null
+	// the absolute paths of the client boundaries listed by the plugin in rsc mode
+	let boundaryCache: Set<string> | null = null
 
 	return {
 		name: 'houdini-react',
@@ -190,6 +193,7 @@ export default function (ctx: VitePluginContext): PluginOption {
 			// Clear after every HMR cycle so the next transform re-queries the DB.
 			// Safe because transform is only called after the pipeline has finished.
 			cfCache = null
+			boundaryCache = null
 		},
 
 		async transform(code: string, filepath: string, options?: { ssr?: boolean }) {
@@ -217,6 +221,19 @@ export default function (ctx: VitePluginContext): PluginOption {
 				}
 			}
 
+			// the list only exists in rsc mode, so a missing file means nothing is a boundary
+			if (boundaryCache === null) {
+				boundaryCache = new Set()
+				try {
+					const boundaries: { filepath: string }[] = JSON.parse(
+						readFileSync(client_boundaries_path(ctx.config), 'utf-8')
+					)
+					for (const { filepath } of boundaries) {
+						boundaryCache.add(path.posixify(path.join(ctx.config.root_dir, filepath)))
+					}
+				} catch {}
+			}
+
 			return transform_file(
 				{
 					config: ctx.config,
@@ -225,7 +242,7 @@ export default function (ctx: VitePluginContext): PluginOption {
 					watch_file: this.addWatchFile.bind(this),
 				},
 				cfCache,
-				{ stripHeaders }
+				{ stripHeaders, useClient: boundaryCache.has(path.posixify(filepath)) }
 			)
 		},
 
@@ -14,6 +14,7 @@ import * as recast from 'recast'
 import type { SourceMapInput } from 'rollup'
 
 import { strip_named_export } from './strip-headers.js'
+import { add_use_client } from './use-client.js'
 
 const AST = recast.types.builders
 
@@ -22,7 +23,7 @@ export type ComponentFieldRow = { type: string; field: string; fragment: string
 export async function transform_file(
 	page: TransformPage,
 	cfRows: ComponentFieldRow[],
-	opts: { stripHeaders?: boolean } = {}
+	opts: { stripHeaders?: boolean; useClient?: boolean } = {}
 ): Promise<{ code: string; map?: SourceMapInput }> {
 	const isJSX = page.filepath.endsWith('.tsx') || page.filepath.endsWith('.jsx')
 	if (!isJSX && !page.filepath.endsWith('.ts') && !page.filepath.endsWith('.js')) {
@@ -40,6 +41,12 @@ export async function transform_file(
 		strip_named_export(script, 'headers')
 	}
 
+	// in rsc mode, components whose documents need a hook (mutations, subscriptions,
+	// pagination) are client boundaries: everything else renders on the server
+	if (opts.useClient) {
+		add_use_client(script)
+	}
+
 	const cfMap: Record<string, Record<string, string>> = {}
 	for (const row of cfRows) {
 		if (row.type && row.field && row.fragment) {
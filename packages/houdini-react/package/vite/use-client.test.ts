@@ -0,0 +1,24 @@
+import { parseJS, printJS } from 'houdini'
+import { test, expect, describe } from 'vitest'
+
+import { add_use_client } from './use-client.js'
+
+async function mark(code: string): Promise<string> {
+	const script = parseJS(code, { plugins: ['jsx'] })
+	add_use_client(script)
+	const { code: out } = await printJS(script)
+	return out.trim()
+}
+
+describe('add_use_client', () => {
+	test('adds the directive to the top of the module', async () => {
+		const out = await mark(`import React from 'react'\nexport default () => null`)
+		expect(out.startsWith(`'use client'`) || out.startsWith(`"use client"`)).toBe(true)
+		expect(out).toContain('export default')
+	})
+
+	test('keeps an existing directive', async () => {
+		const out = await mark(`'use client'\nexport default () => null`)
+		expect(out.match(/use client/g)).toHaveLength(1)
+	})
+})
@@ -0,0 +1,13 @@
+import * as recast from 'recast'
+
+const AST = recast.types.builders
+
+// add_use_client marks a parsed module as a client boundary by adding the 'use client'
+// directive to the top of it. Modules that already have the directive are left alone.
+export function add_use_client(script: any): void {
+	script.directives ??= []
+	if (script.directives.some((d: any) => d.value?.value === 'use client')) {
+		return
+	}
+	script.directives.unshift(AST.directive(AST.directiveLiteral('use client')))
+}
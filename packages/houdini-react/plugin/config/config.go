@@ -0,0 +1,7 @@
+package config
+
+// PluginConfig is the houdini-react entry in the plugins section of the config file
+type PluginConfig struct {
+	// RenderMode picks how pages render on the server: client (the default) or rsc
+	RenderMode string `json:"renderMode"`
+}
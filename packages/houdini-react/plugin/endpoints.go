@@ -0,0 +1,425 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// uploadScalars are the scalars that hold files. An endpoint with a variable of one of
+// these types takes a multipart body.
+var uploadScalars = map[string]bool{"File": true, "Upload": true}
+
+// Endpoint is a mutation carrying @endpoint. Besides the no-JS form handler, the server
+// accepts JSON and form bodies for it at {apiEndpoint}/{Name} so clients that don't speak
+// GraphQL (webhooks, mobile shells, ...) can call it.
+type Endpoint struct {
+	Name     string
+	Path     string
+	Filepath string
+	// Fields is the @endpoint(fields:) allowlist. nil when the directive doesn't restrict
+	// the body.
+	Fields    []string
+	Multipart bool
+	Variables []EndpointVariable
+}
+
+// EndpointVariable is one of the variables of an endpoint's mutation
+type EndpointVariable struct {
+	Name       string
+	Type       string
+	Wrappers   []string
+	HasDefault bool
+}
+
+// accepts reports whether a body can provide the variable. The allowlist holds flat form
+// keys (user.name, tags[]) so a variable is accepted when any of them starts with it.
+func (e Endpoint) accepts(variable string) bool {
+	if e.Fields == nil {
+		return true
+	}
+	for _, field := range e.Fields {
+		root, _, _ := strings.Cut(field, ".")
+		if strings.TrimSuffix(root, "[]") == variable {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAPIEndpoint returns the path the local GraphQL API is served at
+func (p *HoudiniReact) loadAPIEndpoint(ctx context.Context) string {
+	apiEndpoint := "/_api"
+	_ = p.DB.StepQuery(ctx, `SELECT api_endpoint FROM router_config LIMIT 1`, nil, func(q plugins.Row) {
+		if v := q.ColumnText(0); v != "" {
+			apiEndpoint = v
+		}
+	})
+	return apiEndpoint
+}
+
+// LoadEndpoints returns every @endpoint mutation in the project, sorted by name
+func (p *HoudiniReact) LoadEndpoints(ctx context.Context) ([]Endpoint, error) {
+	apiEndpoint := strings.TrimSuffix(p.loadAPIEndpoint(ctx), "/")
+
+	endpoints := map[string]*Endpoint{}
+	err := p.DB.StepQuery(ctx, `
+		SELECT d.name, rd.filepath, dv.name, dv.type, COALESCE(dv.type_modifiers, ''), dv.default_value IS NOT NULL
+		FROM documents d
+		JOIN raw_documents rd ON d.raw_document = rd.id
+		LEFT JOIN document_variables dv ON dv.document = d.id
+		WHERE d.kind = 'mutation'
+		  AND EXISTS (
+			SELECT 1 FROM document_directives dd
+			WHERE dd.document = d.id AND dd.directive = $endpoint_directive
+		  )
+		ORDER BY d.name, dv.id
+	`, map[string]any{"endpoint_directive": graphql.EndpointDirective}, func(row plugins.Row) {
+		name := row.ColumnText(0)
+		endpoint, ok := endpoints[name]
+		if !ok {
+			endpoint = &Endpoint{
+				Name:      name,
+				Path:      apiEndpoint + "/" + name,
+				Filepath:  row.ColumnText(1),
+				Variables: []EndpointVariable{},
+			}
+			endpoints[name] = endpoint
+		}
+		// the variable columns are NULL for mutations without variables (LEFT JOIN)
+		if variable := row.ColumnText(2); variable != "" {
+			endpoint.Variables = append(endpoint.Variables, EndpointVariable{
+				Name:       variable,
+				Type:       row.ColumnText(3),
+				Wrappers:   modifiersToWrappers(row.ColumnText(4)),
+				HasDefault: row.ColumnBool(5),
+			})
+			if uploadScalars[row.ColumnText(3)] {
+				endpoint.Multipart = true
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the fields allowlist is a list argument so its values live in the children of the
+	// argument. an empty list still restricts the body, so it has to show up as non-nil
+	err = p.DB.StepQuery(ctx, `
+		SELECT d.name, child.raw
+		FROM documents d
+		JOIN document_directives dd ON dd.document = d.id AND dd.directive = $endpoint_directive
+		JOIN document_directive_arguments dda ON dda.parent = dd.id AND dda.name = 'fields'
+		LEFT JOIN argument_value_children avc ON avc.parent = dda.value
+		LEFT JOIN argument_values child ON child.id = avc.value
+		WHERE d.kind = 'mutation'
+		ORDER BY d.name, avc.id
+	`, map[string]any{"endpoint_directive": graphql.EndpointDirective}, func(row plugins.Row) {
+		endpoint, ok := endpoints[row.ColumnText(0)]
+		if !ok {
+			return
+		}
+		if endpoint.Fields == nil {
+			endpoint.Fields = []string{}
+		}
+		if field := row.ColumnText(1); field != "" {
+			endpoint.Fields = append(endpoint.Fields, field)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Endpoint, 0, len(endpoints))
+	for _, name := range sortedKeys(endpoints) {
+		result = append(result, *endpoints[name])
+	}
+	return result, nil
+}
+
+// GenerateOpenAPI writes an OpenAPI document that describes the endpoints of every
+// @endpoint mutation to {runtimeDir}/openapi.json. The file is removed when the project
+// has no endpoints.
+func (p *HoudiniReact) GenerateOpenAPI(ctx context.Context) ([]string, error) {
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target := filepath.Join(projectConfig.ProjectRoot, projectConfig.RuntimeDir, "openapi.json")
+
+	endpoints, err := p.LoadEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) == 0 {
+		if exists, _ := afero.Exists(p.Filesystem(), target); exists {
+			return []string{target}, p.Filesystem().Remove(target)
+		}
+		return nil, nil
+	}
+
+	document, err := p.BuildOpenAPI(ctx, endpoints, projectConfig.Scalars)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := writeIfChanged(p.Filesystem(), target, string(content)+"\n")
+	if err != nil || !changed {
+		return nil, err
+	}
+	return []string{target}, nil
+}
+
+// BuildOpenAPI describes the endpoints as an OpenAPI 3.1 document. The body of every
+// endpoint is an object with one property per variable its mutation accepts. Input
+// objects and enums become shared component schemas.
+func (p *HoudiniReact) BuildOpenAPI(
+	ctx context.Context,
+	endpoints []Endpoint,
+	scalars map[string]plugins.ScalarConfig,
+) (map[string]any, error) {
+	schemas := map[string]any{
+		"GraphQLResponse": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"data": map[string]any{
+					"type":        []string{"object", "null"},
+					"description": "The selection of the mutation",
+				},
+				"errors": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type":     "object",
+						"required": []string{"message"},
+						"properties": map[string]any{
+							"message": map[string]any{"type": "string"},
+							"path": map[string]any{
+								"type":  "array",
+								"items": map[string]any{"type": []string{"string", "integer"}},
+							},
+							"extensions": map[string]any{"type": "object"},
+						},
+					},
+				},
+			},
+		},
+	}
+	builder := &openAPISchemaBuilder{p: p, ctx: ctx, scalars: scalars, schemas: schemas}
+
+	response := map[string]any{
+		"application/json": map[string]any{
+			"schema": map[string]any{"$ref": "#/components/schemas/GraphQLResponse"},
+		},
+	}
+
+	paths := map[string]any{}
+	for _, endpoint := range endpoints {
+		properties := map[string]any{}
+		required := []string{}
+		for _, variable := range endpoint.Variables {
+			if !endpoint.accepts(variable.Name) {
+				continue
+			}
+			schema, err := builder.typeSchema(variable.Type, variable.Wrappers)
+			if err != nil {
+				return nil, err
+			}
+			properties[variable.Name] = schema
+			if isRequired(variable.Wrappers) && !variable.HasDefault {
+				required = append(required, variable.Name)
+			}
+		}
+
+		body := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			body["required"] = required
+		}
+		schemas[endpoint.Name+"Body"] = body
+		ref := map[string]any{"$ref": "#/components/schemas/" + endpoint.Name + "Body"}
+
+		// form bodies use the same flat keys as the no-JS form (user.name, tags[])
+		formType := "application/x-www-form-urlencoded"
+		if endpoint.Multipart {
+			formType = "multipart/form-data"
+		}
+
+		paths[endpoint.Path] = map[string]any{
+			"post": map[string]any{
+				"operationId": endpoint.Name,
+				"summary":     "Run the " + endpoint.Name + " mutation",
+				"requestBody": map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": ref},
+						formType:           map[string]any{"schema": ref},
+					},
+				},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "The mutation ran",
+						"content":     response,
+					},
+					"422": map[string]any{
+						"description": "The mutation returned errors",
+						"content":     response,
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "Houdini endpoints",
+			"version": "1.0.0",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}, nil
+}
+
+// openAPISchemaBuilder turns GraphQL input types into JSON schemas. Input objects and
+// enums are added to the component schemas the first time they show up.
+type openAPISchemaBuilder struct {
+	p       *HoudiniReact
+	ctx     context.Context
+	scalars map[string]plugins.ScalarConfig
+	schemas map[string]any
+}
+
+// typeSchema returns the schema of a type with its list wrappers applied. Wrappers are
+// ordered outermost first, like in the manifest.
+func (b *openAPISchemaBuilder) typeSchema(typeName string, wrappers []string) (map[string]any, error) {
+	lists := 0
+	for _, wrapper := range wrappers {
+		if wrapper == "List" {
+			lists++
+		}
+	}
+
+	schema, err := b.namedSchema(typeName)
+	if err != nil {
+		return nil, err
+	}
+	for range lists {
+		schema = map[string]any{"type": "array", "items": schema}
+	}
+	return schema, nil
+}
+
+func (b *openAPISchemaBuilder) namedSchema(typeName string) (map[string]any, error) {
+	switch typeName {
+	case "Int":
+		return map[string]any{"type": "integer"}, nil
+	case "Float":
+		return map[string]any{"type": "number"}, nil
+	case "Boolean":
+		return map[string]any{"type": "boolean"}, nil
+	case "String", "ID":
+		return map[string]any{"type": "string"}, nil
+	case "File", "Upload":
+		return map[string]any{"type": "string", "format": "binary"}, nil
+	}
+
+	ref := map[string]any{"$ref": "#/components/schemas/" + typeName}
+	if _, ok := b.schemas[typeName]; ok {
+		return ref, nil
+	}
+
+	kind := ""
+	err := b.p.DB.StepQuery(b.ctx, `SELECT kind FROM types WHERE name = $name`, map[string]any{"name": typeName}, func(row plugins.Row) {
+		kind = row.ColumnText(0)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case "ENUM":
+		values := []string{}
+		err := b.p.DB.StepQuery(b.ctx, `SELECT value FROM enum_values WHERE parent = $name ORDER BY id`, map[string]any{"name": typeName}, func(row plugins.Row) {
+			values = append(values, row.ColumnText(0))
+		})
+		if err != nil {
+			return nil, err
+		}
+		b.schemas[typeName] = map[string]any{"type": "string", "enum": values}
+		return ref, nil
+
+	case "INPUT":
+		// register the schema before walking the fields so recursive inputs point back at it
+		schema := map[string]any{"type": "object"}
+		b.schemas[typeName] = schema
+
+		type inputField struct {
+			name       string
+			typeName   string
+			wrappers   []string
+			hasDefault bool
+		}
+		fields := []inputField{}
+		err := b.p.DB.StepQuery(b.ctx, `
+			SELECT name, type, COALESCE(type_modifiers, ''), default_value IS NOT NULL
+			FROM type_fields WHERE parent = $name ORDER BY name
+		`, map[string]any{"name": typeName}, func(row plugins.Row) {
+			fields = append(fields, inputField{
+				name:       row.ColumnText(0),
+				typeName:   row.ColumnText(1),
+				wrappers:   modifiersToWrappers(row.ColumnText(2)),
+				hasDefault: row.ColumnBool(3),
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		properties := map[string]any{}
+		required := []string{}
+		for _, field := range fields {
+			fieldSchema, err := b.typeSchema(field.typeName, field.wrappers)
+			if err != nil {
+				return nil, err
+			}
+			properties[field.name] = fieldSchema
+			if isRequired(field.wrappers) && !field.hasDefault {
+				required = append(required, field.name)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		return ref, nil
+	}
+
+	// custom scalars are sent in their serialized form. we only know its shape when the
+	// scalar is configured with a primitive type
+	schema := map[string]any{"format": typeName}
+	switch b.scalars[typeName].Type {
+	case "string":
+		schema["type"] = "string"
+	case "number":
+		schema["type"] = "number"
+	case "boolean":
+		schema["type"] = "boolean"
+	}
+	return schema, nil
+}
+
+// isRequired reports whether the outermost wrapper of a type is NonNull
+func isRequired(wrappers []string) bool {
+	return len(wrappers) > 0 && wrappers[0] == "NonNull"
+}
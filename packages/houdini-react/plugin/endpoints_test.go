@@ -0,0 +1,206 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestGenerateOpenAPI(t *testing.T) {
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
+		Schema: `
+			type Query {
+				node(id: ID!): Node
+			}
+			type Mutation {
+				createUser(input: UserInput!, role: Role): User!
+				deleteUser(id: ID!): Boolean
+				updateUser(id: ID!, name: String): User!
+			}
+			interface Node { id: ID! }
+			type User implements Node { id: ID! }
+			input UserInput {
+				name: String!
+				tags: [String!]
+				manager: UserInput
+			}
+			enum Role { ADMIN, MEMBER }
+		`,
+
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			ctx := context.Background()
+
+			endpoints, err := p.LoadEndpoints(ctx)
+			require.NoError(t, err)
+			if expected, ok := test.Extra["endpoints"].([]plugin.Endpoint); ok {
+				require.Equal(t, expected, endpoints)
+			}
+
+			_, err = p.GenerateOpenAPI(ctx)
+			require.NoError(t, err)
+
+			cfg, err := p.DB.ProjectConfig(ctx)
+			require.NoError(t, err)
+			target := filepath.Join(cfg.ProjectRoot, cfg.RuntimeDir, "openapi.json")
+			expected, ok := test.Extra["openapi"].(string)
+			if !ok {
+				exists, err := afero.Exists(p.Filesystem(), target)
+				require.NoError(t, err)
+				require.False(t, exists)
+				return
+			}
+			got, err := afero.ReadFile(p.Filesystem(), target)
+			require.NoError(t, err)
+			require.JSONEq(t, expected, string(got))
+		},
+
+		Tests: []tests.Test[reactConfig.PluginConfig]{
+			{
+				Name: "no endpoints",
+				Pass: true,
+				Input: []string{
+					`mutation DeleteUser($id: ID!) { deleteUser(id: $id) }`,
+				},
+				Extra: map[string]any{
+					"endpoints": []plugin.Endpoint{},
+				},
+			},
+			{
+				Name: "describes the body of every endpoint",
+				Pass: true,
+				Input: []string{
+					`mutation CreateUser($input: UserInput!, $role: Role = MEMBER) @endpoint { createUser(input: $input, role: $role) { id } }`,
+					`mutation UpdateUser($id: ID!, $name: String) @endpoint(fields: ["name"]) { updateUser(id: $id, name: $name) { id } }`,
+				},
+				Extra: map[string]any{
+					"endpoints": []plugin.Endpoint{
+						{
+							Name:     "CreateUser",
+							Path:     "/_api/CreateUser",
+							Filepath: "file-0.gql",
+							Variables: []plugin.EndpointVariable{
+								{Name: "input", Type: "UserInput", Wrappers: []string{"NonNull"}},
+								{Name: "role", Type: "Role", Wrappers: []string{}, HasDefault: true},
+							},
+						},
+						{
+							Name:     "UpdateUser",
+							Path:     "/_api/UpdateUser",
+							Filepath: "file-1.gql",
+							Fields:   []string{"name"},
+							Variables: []plugin.EndpointVariable{
+								{Name: "id", Type: "ID", Wrappers: []string{"NonNull"}},
+								{Name: "name", Type: "String", Wrappers: []string{}},
+							},
+						},
+					},
+					"openapi": `{
+						"openapi": "3.1.0",
+						"info": {"title": "Houdini endpoints", "version": "1.0.0"},
+						"paths": {
+							"/_api/CreateUser": {
+								"post": {
+									"operationId": "CreateUser",
+									"summary": "Run the CreateUser mutation",
+									"requestBody": {
+										"required": true,
+										"content": {
+											"application/json": {"schema": {"$ref": "#/components/schemas/CreateUserBody"}},
+											"application/x-www-form-urlencoded": {"schema": {"$ref": "#/components/schemas/CreateUserBody"}}
+										}
+									},
+									"responses": {
+										"200": {
+											"description": "The mutation ran",
+											"content": {"application/json": {"schema": {"$ref": "#/components/schemas/GraphQLResponse"}}}
+										},
+										"422": {
+											"description": "The mutation returned errors",
+											"content": {"application/json": {"schema": {"$ref": "#/components/schemas/GraphQLResponse"}}}
+										}
+									}
+								}
+							},
+							"/_api/UpdateUser": {
+								"post": {
+									"operationId": "UpdateUser",
+									"summary": "Run the UpdateUser mutation",
+									"requestBody": {
+										"required": true,
+										"content": {
+											"application/json": {"schema": {"$ref": "#/components/schemas/UpdateUserBody"}},
+											"application/x-www-form-urlencoded": {"schema": {"$ref": "#/components/schemas/UpdateUserBody"}}
+										}
+									},
+									"responses": {
+										"200": {
+											"description": "The mutation ran",
+											"content": {"application/json": {"schema": {"$ref": "#/components/schemas/GraphQLResponse"}}}
+										},
+										"422": {
+											"description": "The mutation returned errors",
+											"content": {"application/json": {"schema": {"$ref": "#/components/schemas/GraphQLResponse"}}}
+										}
+									}
+								}
+							}
+						},
+						"components": {
+							"schemas": {
+								"CreateUserBody": {
+									"type": "object",
+									"properties": {
+										"input": {"$ref": "#/components/schemas/UserInput"},
+										"role": {"$ref": "#/components/schemas/Role"}
+									},
+									"required": ["input"]
+								},
+								"UpdateUserBody": {
+									"type": "object",
+									"properties": {
+										"name": {"type": "string"}
+									}
+								},
+								"UserInput": {
+									"type": "object",
+									"properties": {
+										"manager": {"$ref": "#/components/schemas/UserInput"},
+										"name": {"type": "string"},
+										"tags": {"type": "array", "items": {"type": "string"}}
+									},
+									"required": ["name"]
+								},
+								"Role": {"type": "string", "enum": ["ADMIN", "MEMBER"]},
+								"GraphQLResponse": {
+									"type": "object",
+									"properties": {
+										"data": {"type": ["object", "null"], "description": "The selection of the mutation"},
+										"errors": {
+											"type": "array",
+											"items": {
+												"type": "object",
+												"required": ["message"],
+												"properties": {
+													"message": {"type": "string"},
+													"path": {"type": "array", "items": {"type": ["string", "integer"]}},
+													"extensions": {"type": "object"}
+												}
+											}
+										}
+									}
+								}
+							}
+						}
+					}`,
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,284 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is the encoding used for the exported route manifest and prerender plan
+type ExportFormat = string
+
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatYAML ExportFormat = "yaml"
+)
+
+// RouteExport is the description of a project's routes handed to tooling outside of houdini
+// (CDNs, static exporters, ...). Unlike ProjectManifest it only describes the public shape of
+// each route and every list is sorted so the output only changes when the routes do.
+type RouteExport struct {
+	Routes    []ExportedRoute `json:"routes" yaml:"routes"`
+	Prerender PrerenderPlan   `json:"prerender" yaml:"prerender"`
+}
+
+// ExportedRoute is a single page of the application
+type ExportedRoute struct {
+	ID string `json:"id" yaml:"id"`
+	// Path is the public url pattern of the route. Route groups are removed and dynamic
+	// segments keep their [param] form.
+	Path         string          `json:"path" yaml:"path"`
+	File         string          `json:"file" yaml:"file"`
+	Layouts      []string        `json:"layouts" yaml:"layouts"`
+	Params       []ExportedParam `json:"params" yaml:"params"`
+	SearchParams []ExportedParam `json:"search_params" yaml:"search_params"`
+	Queries      []string        `json:"queries" yaml:"queries"`
+}
+
+// ExportedParam is a route or search param. Type is the GraphQL type of the variable the param
+// fills and is empty when no query uses the param.
+type ExportedParam struct {
+	Name     string `json:"name" yaml:"name"`
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	Optional bool   `json:"optional,omitempty" yaml:"optional,omitempty"`
+	Rest     bool   `json:"rest,omitempty" yaml:"rest,omitempty"`
+}
+
+// PrerenderPlan splits the routes into the urls that can be rendered as they are and the
+// routes that need a list of param values from the user before they can be rendered.
+type PrerenderPlan struct {
+	Static        []string         `json:"static" yaml:"static"`
+	Parameterized []PrerenderRoute `json:"parameterized" yaml:"parameterized"`
+}
+
+// PrerenderRoute is a route that needs a value for each of its params to be rendered
+type PrerenderRoute struct {
+	ID     string   `json:"id" yaml:"id"`
+	Path   string   `json:"path" yaml:"path"`
+	Params []string `json:"params" yaml:"params"`
+}
+
+// ExportRoutes writes the route manifest, the prerender plan, and a sitemap.xml skeleton to
+// the given directory. It returns the paths of the files it wrote.
+func (p *HoudiniReact) ExportRoutes(
+	ctx context.Context,
+	outDir string,
+	format ExportFormat,
+	baseURL string,
+) ([]string, error) {
+	if format != ExportFormatJSON && format != ExportFormatYAML {
+		return nil, fmt.Errorf("unknown export format %q. use %q or %q", format, ExportFormatJSON, ExportFormatYAML)
+	}
+
+	manifest, err := p.LoadManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	export := BuildRouteExport(manifest)
+
+	routes, err := encodeExport(format, struct {
+		Routes []ExportedRoute `json:"routes" yaml:"routes"`
+	}{export.Routes})
+	if err != nil {
+		return nil, err
+	}
+	prerender, err := encodeExport(format, export.Prerender)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := p.Filesystem()
+	if err := fs.MkdirAll(outDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name     string
+		contents []byte
+	}{
+		{"routes." + format, routes},
+		{"prerender." + format, prerender},
+		{"sitemap.xml", []byte(export.Sitemap(baseURL))},
+	}
+	written := []string{}
+	for _, file := range files {
+		target := filepath.Join(outDir, file.name)
+		if err := afero.WriteFile(fs, target, file.contents, 0o644); err != nil {
+			return nil, err
+		}
+		written = append(written, target)
+	}
+
+	return written, nil
+}
+
+// BuildRouteExport turns the manifest into the shape handed to external tooling
+func BuildRouteExport(manifest ProjectManifest) RouteExport {
+	export := RouteExport{
+		Routes: []ExportedRoute{},
+		Prerender: PrerenderPlan{
+			Static:        []string{},
+			Parameterized: []PrerenderRoute{},
+		},
+	}
+
+	static := map[string]bool{}
+	for _, id := range sortedKeys(manifest.Pages) {
+		page := manifest.Pages[id]
+		path := publicPath(page.URL)
+
+		route := ExportedRoute{
+			ID:           page.ID,
+			Path:         path,
+			File:         page.Path,
+			Layouts:      clone(page.Layouts),
+			Params:       []ExportedParam{},
+			SearchParams: []ExportedParam{},
+			Queries:      append(clone(page.LayoutQueries), page.Queries...),
+		}
+
+		required := []string{}
+		for _, segment := range strings.Split(path, "/") {
+			param, ok := segmentParam(segment)
+			if !ok {
+				continue
+			}
+			if info := page.Params[param.Name]; info != nil {
+				param.Type = info.Type
+			}
+			route.Params = append(route.Params, param)
+			if !param.Optional {
+				required = append(required, param.Name)
+			}
+		}
+		for _, name := range sortedKeys(page.SearchParams) {
+			param := ExportedParam{Name: name, Optional: true}
+			if info := page.SearchParams[name]; info != nil {
+				param.Type = info.Type
+			}
+			route.SearchParams = append(route.SearchParams, param)
+		}
+		export.Routes = append(export.Routes, route)
+
+		// a route without required params is rendered at the url that leaves out its optional
+		// segments. the others need a list of values before they can be rendered
+		if len(required) == 0 {
+			static[staticPath(path)] = true
+		} else {
+			names := []string{}
+			for _, param := range route.Params {
+				names = append(names, param.Name)
+			}
+			export.Prerender.Parameterized = append(export.Prerender.Parameterized, PrerenderRoute{
+				ID:     route.ID,
+				Path:   path,
+				Params: names,
+			})
+		}
+	}
+
+	export.Prerender.Static = sortedKeys(static)
+	sort.SliceStable(export.Routes, func(i, j int) bool {
+		return export.Routes[i].Path < export.Routes[j].Path
+	})
+	sort.SliceStable(export.Prerender.Parameterized, func(i, j int) bool {
+		return export.Prerender.Parameterized[i].Path < export.Prerender.Parameterized[j].Path
+	})
+
+	return export
+}
+
+// Sitemap returns a sitemap.xml with an entry for every static url. Parameterized routes
+// are left as comments since their urls depend on data houdini doesn't know about.
+func (e RouteExport) Sitemap(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, path := range e.Prerender.Static {
+		b.WriteString("  <url>\n    <loc>")
+		_ = xml.EscapeText(&b, []byte(baseURL+path))
+		b.WriteString("</loc>\n  </url>\n")
+	}
+	for _, route := range e.Prerender.Parameterized {
+		// a comment can't contain a double dash
+		path := strings.ReplaceAll(route.Path, "--", "- -")
+		fmt.Fprintf(&b, "  <!-- %s%s needs a url for every value of: %s -->\n",
+			baseURL, path, strings.Join(route.Params, ", "))
+	}
+	b.WriteString("</urlset>\n")
+
+	return b.String()
+}
+
+func encodeExport(format ExportFormat, value any) ([]byte, error) {
+	if format == ExportFormatYAML {
+		var b bytes.Buffer
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), encoder.Close()
+	}
+
+	contents, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(contents, '\n'), nil
+}
+
+// publicPath turns a manifest url into the path users see: route groups don't show up in the
+// url and only the root keeps its trailing slash
+func publicPath(url string) string {
+	segments := []string{}
+	for _, segment := range strings.Split(url, "/") {
+		if segment == "" || (strings.HasPrefix(segment, "(") && strings.HasSuffix(segment, ")")) {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// staticPath drops the optional segments of a path
+func staticPath(path string) string {
+	segments := []string{}
+	for _, segment := range strings.Split(path, "/") {
+		if param, ok := segmentParam(segment); segment == "" || (ok && param.Optional) {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// segmentParam decodes a dynamic route segment ([id], [[optional]], or [...rest])
+func segmentParam(segment string) (ExportedParam, bool) {
+	if !strings.HasPrefix(segment, "[") || !strings.HasSuffix(segment, "]") {
+		return ExportedParam{}, false
+	}
+
+	param := ExportedParam{}
+	if strings.HasPrefix(segment, "[[") && strings.HasSuffix(segment, "]]") {
+		param.Optional = true
+	}
+	name := strings.Trim(segment, "[]")
+	if strings.HasPrefix(name, "...") {
+		param.Rest = true
+		name = strings.TrimPrefix(name, "...")
+	}
+	param.Name = name
+
+	return param, true
+}
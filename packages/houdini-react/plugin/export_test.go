@@ -0,0 +1,244 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestExportRoutes(t *testing.T) {
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
+		Schema: `
+			type Query {
+				node(id: ID!): Node
+				search(q: String, lang: String): [Node!]!
+			}
+			interface Node { id: ID! }
+		`,
+		SetupAlwaysPasses: true,
+
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			views, ok := test.Extra["views"].(map[string]string)
+			if !ok {
+				return
+			}
+			fs := p.Filesystem()
+			for fp, content := range views {
+				abs := filepath.Join("/project", fp)
+				require.NoError(t, fs.MkdirAll(filepath.Dir(abs), 0755))
+				require.NoError(t, afero.WriteFile(fs, abs, []byte(content), 0644))
+			}
+		},
+
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			format, _ := test.Extra["format"].(string)
+			outDir := "/project/build/routes"
+
+			files, err := p.ExportRoutes(context.Background(), outDir, format, "https://houdini.test/")
+			if !test.Pass {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, files, 3)
+
+			for name, expected := range test.Extra["files"].(map[string]string) {
+				contents, err := afero.ReadFile(p.Filesystem(), filepath.Join(outDir, name))
+				require.NoError(t, err)
+				require.Equal(t, expected+"\n", string(contents), name)
+			}
+		},
+
+		Tests: []tests.Test[reactConfig.PluginConfig]{
+			{
+				Name: "static and parameterized routes",
+				Pass: true,
+				Input: []string{
+					`query UserInfo($id: ID!) { node(id: $id) { id } }`,
+					`query Docs($lang: String, $q: String) { search(q: $q, lang: $lang) { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+					"src/routes/docs/[[lang]]/+page.gql",
+				},
+				Extra: map[string]any{
+					"format": plugin.ExportFormatJSON,
+					"views": map[string]string{
+						"src/routes/+layout.tsx":                 mockView([]string{}),
+						"src/routes/+page.tsx":                   mockView([]string{}),
+						"src/routes/(marketing)/about/+page.tsx": mockView([]string{}),
+						"src/routes/users/[id]/+page.tsx":        mockView([]string{"UserInfo"}),
+						"src/routes/docs/[[lang]]/+page.tsx":     mockView([]string{"Docs"}),
+						"src/routes/files/[...path]/+page.tsx":   mockView([]string{}),
+					},
+					"files": map[string]string{
+						"routes.json": tests.Dedent(`
+							{
+							  "routes": [
+							    {
+							      "id": "_",
+							      "path": "/",
+							      "file": "src/routes/+page.tsx",
+							      "layouts": [
+							        "_"
+							      ],
+							      "params": [],
+							      "search_params": [],
+							      "queries": []
+							    },
+							    {
+							      "id": "__marketing__about",
+							      "path": "/about",
+							      "file": "src/routes/(marketing)/about/+page.tsx",
+							      "layouts": [
+							        "_"
+							      ],
+							      "params": [],
+							      "search_params": [],
+							      "queries": []
+							    },
+							    {
+							      "id": "_docs___lang__",
+							      "path": "/docs/[[lang]]",
+							      "file": "src/routes/docs/[[lang]]/+page.tsx",
+							      "layouts": [
+							        "_"
+							      ],
+							      "params": [
+							        {
+							          "name": "lang",
+							          "type": "String",
+							          "optional": true
+							        }
+							      ],
+							      "search_params": [
+							        {
+							          "name": "q",
+							          "type": "String",
+							          "optional": true
+							        }
+							      ],
+							      "queries": [
+							        "Docs"
+							      ]
+							    },
+							    {
+							      "id": "_files__...path_",
+							      "path": "/files/[...path]",
+							      "file": "src/routes/files/[...path]/+page.tsx",
+							      "layouts": [
+							        "_"
+							      ],
+							      "params": [
+							        {
+							          "name": "path",
+							          "rest": true
+							        }
+							      ],
+							      "search_params": [],
+							      "queries": []
+							    },
+							    {
+							      "id": "_users__id_",
+							      "path": "/users/[id]",
+							      "file": "src/routes/users/[id]/+page.tsx",
+							      "layouts": [
+							        "_"
+							      ],
+							      "params": [
+							        {
+							          "name": "id",
+							          "type": "ID"
+							        }
+							      ],
+							      "search_params": [],
+							      "queries": [
+							        "UserInfo"
+							      ]
+							    }
+							  ]
+							}
+						`),
+						"prerender.json": tests.Dedent(`
+							{
+							  "static": [
+							    "/",
+							    "/about",
+							    "/docs"
+							  ],
+							  "parameterized": [
+							    {
+							      "id": "_files__...path_",
+							      "path": "/files/[...path]",
+							      "params": [
+							        "path"
+							      ]
+							    },
+							    {
+							      "id": "_users__id_",
+							      "path": "/users/[id]",
+							      "params": [
+							        "id"
+							      ]
+							    }
+							  ]
+							}
+						`),
+						"sitemap.xml": tests.Dedent(`
+							<?xml version="1.0" encoding="UTF-8"?>
+							<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+							  <url>
+							    <loc>https://houdini.test/</loc>
+							  </url>
+							  <url>
+							    <loc>https://houdini.test/about</loc>
+							  </url>
+							  <url>
+							    <loc>https://houdini.test/docs</loc>
+							  </url>
+							  <!-- https://houdini.test/files/[...path] needs a url for every value of: path -->
+							  <!-- https://houdini.test/users/[id] needs a url for every value of: id -->
+							</urlset>
+						`),
+					},
+				},
+			},
+			{
+				Name: "yaml",
+				Pass: true,
+				Extra: map[string]any{
+					"format": plugin.ExportFormatYAML,
+					"views": map[string]string{
+						"src/routes/+page.tsx":            mockView([]string{}),
+						"src/routes/users/[id]/+page.tsx": mockView([]string{}),
+					},
+					"files": map[string]string{
+						"prerender.yaml": tests.Dedent(`
+							static:
+							  - /
+							parameterized:
+							  - id: _users__id_
+							    path: /users/[id]
+							    params:
+							      - id
+						`),
+					},
+				},
+			},
+			{
+				Name: "unknown format",
+				Pass: false,
+				Extra: map[string]any{
+					"format": "toml",
+				},
+			},
+		},
+	})
+}
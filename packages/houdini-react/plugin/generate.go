@@ -2,8 +2,11 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -21,8 +24,9 @@ func errorsDir(pluginDir string) string  { return filepath.Join(pluginDir, "unit
 func fallbacksDir(pluginDir, which string) string {
 	return filepath.Join(pluginDir, "units", "fallbacks", which)
 }
-func entriesDir(pluginDir string) string { return filepath.Join(pluginDir, "units", "entries") }
-func renderDir(pluginDir string) string  { return filepath.Join(pluginDir, "units", "render") }
+func entriesDir(pluginDir string) string     { return filepath.Join(pluginDir, "units", "entries") }
+func renderDir(pluginDir string) string      { return filepath.Join(pluginDir, "units", "render") }
+func serverUnitsDir(pluginDir string) string { return filepath.Join(pluginDir, "units", "server") }
 
 // stripViewExt removes .tsx/.jsx from an import path.
 func stripViewExt(p string) string {
@@ -46,6 +50,32 @@ func writeIfChanged(fs afero.Fs, path, content string) (bool, error) {
 	return true, plugins.WriteFile(fs, path, []byte(content), 0644)
 }
 
+// removeStaleFiles deletes every file under the directory that stale returns true for and
+// returns their paths. A directory that doesn't exist has nothing to remove.
+func removeStaleFiles(fs afero.Fs, dir string, stale func(string) bool) ([]string, error) {
+	removed := []string{}
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !stale(path) {
+			return nil
+		}
+		if err := fs.Remove(path); err != nil {
+			return err
+		}
+		removed = append(removed, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
 // ---- unit file generation ----
 
 // generateUnitFile builds the JSX source for a document-wrapper component.
@@ -546,12 +576,7 @@ func (p *HoudiniReact) GenerateRenderInfrastructure(ctx context.Context) ([]stri
 	}
 
 	// Read the API endpoint from router_config, defaulting to "/_api"
-	apiEndpoint := "/_api"
-	_ = p.DB.StepQuery(ctx, `SELECT api_endpoint FROM router_config LIMIT 1`, nil, func(q plugins.Row) {
-		if v := q.ColumnText(0); v != "" {
-			apiEndpoint = v
-		}
-	})
+	apiEndpoint := p.loadAPIEndpoint(ctx)
 
 	pluginDir := projectConfig.PluginDirectory(p.Name())
 	rDir := renderDir(pluginDir)
@@ -624,6 +649,7 @@ export const on_render =
 	({ assetPrefix, pipe, production, documentPremable, cssLinks }) =>
 	async ({
 		url,
+		host,
 		match,
 		is404,
 		session,
@@ -671,6 +697,7 @@ export const on_render =
 			React.createElement(StatusContext.Provider, { value: statusRef },
 				React.createElement(App, {
 					initialURL: url,
+					initialHost: host,
 					cache: cache,
 					session: session,
 					formResult: formResult ?? null,
@@ -958,9 +985,206 @@ func (p *HoudiniReact) GenerateTypeRoots(ctx context.Context) ([]string, error)
 		}
 	}
 
+	// route.ts holds the typed url builder for every page of the project
+	routeContent, err := generateRouteBuilder(manifest)
+	if err != nil {
+		return nil, err
+	}
+	routePath := filepath.Join(runtimeDir, "route.ts")
+	if ok, err := writeIfChanged(p.Filesystem(), routePath, routeContent); err != nil {
+		return nil, err
+	} else if ok {
+		changed = append(changed, routePath)
+	}
+
 	return changed, nil
 }
 
+// generateRouteBuilder emits route.ts: a union of every route ID (the page's url without
+// route groups), the params and search params each route accepts, and a route() function
+// that builds a url from them. Linking to a route that doesn't exist or passing a param of
+// the wrong type fails at type-check time instead of producing a broken url.
+func generateRouteBuilder(manifest ProjectManifest) (string, error) {
+	// key the routes by their ID so the output doesn't depend on the page ids. the locale
+	// variants of a page are reached through the locale option instead of their own ID
+	pagesByRoute := map[string]PageManifest{}
+	for _, page := range manifest.Pages {
+		if manifest.isLocaleVariant(page) {
+			continue
+		}
+		pagesByRoute[stripRouteGroups(page.URL)] = page
+	}
+	routeIDs := sortedKeys(pagesByRoute)
+
+	var b strings.Builder
+	b.WriteString("// this file is generated by houdini — do not edit\n")
+	b.WriteString("import { getCurrentConfig } from '$houdini/runtime/config'\n\n")
+	b.WriteString("// @ts-ignore\n")
+	b.WriteString("import manifest from './manifest.js'\n")
+	b.WriteString("// @ts-ignore\n")
+	b.WriteString("import type { _TSType } from './manifest.js'\n")
+	b.WriteString("import { buildHref, type RouteHrefInfo } from './resolve-href.js'\n")
+
+	// the union of every route
+	b.WriteString("\nexport type RouteID =")
+	if len(routeIDs) == 0 {
+		b.WriteString(" never\n")
+	} else {
+		b.WriteString("\n")
+		for _, id := range routeIDs {
+			b.WriteString(fmt.Sprintf("\t| '%s'\n", id))
+		}
+	}
+
+	// the params that fill the dynamic segments of each route
+	b.WriteString("\nexport type RouteParams = {\n")
+	for _, id := range routeIDs {
+		page := pagesByRoute[id]
+		_, params, err := parsePagePattern(page.URL)
+		if err != nil {
+			return "", fmt.Errorf("could not parse pattern for page %s: %w", page.ID, err)
+		}
+		var fields []string
+		for _, param := range params {
+			gqlType := "String"
+			if info := page.Params[param.Name]; info != nil && !param.Rest {
+				gqlType = info.Type
+			}
+			optional := ""
+			if param.Optional || param.Rest {
+				optional = "?"
+			}
+			fields = append(fields, fmt.Sprintf("%s%s: _TSType<'%s'>", param.Name, optional, gqlType))
+		}
+		b.WriteString(fmt.Sprintf("\t'%s': %s\n", id, formatObjectType(fields)))
+	}
+	b.WriteString("}\n")
+
+	// search params come from the nullable variables of the route's queries so they're always
+	// optional. like <Link>, any other key can ride along for state that no query reads
+	b.WriteString("\nexport type RouteSearch = {\n")
+	for _, id := range routeIDs {
+		page := pagesByRoute[id]
+		var fields []string
+		for _, name := range sortedKeys(page.SearchParams) {
+			tsType := "_TSType<'String'>"
+			if info := page.SearchParams[name]; info != nil {
+				tsType = fmt.Sprintf("_TSType<'%s'>", info.Type)
+				if slices.Contains(info.Wrappers, "List") {
+					tsType += "[]"
+				}
+			}
+			fields = append(fields, fmt.Sprintf("%s?: %s | null", name, tsType))
+		}
+		search := "Record<string, unknown>"
+		if len(fields) > 0 {
+			search = formatObjectType(fields) + " & " + search
+		}
+		b.WriteString(fmt.Sprintf("\t'%s': %s\n", id, search))
+	}
+	b.WriteString("}\n")
+
+	if manifest.I18n == nil {
+		b.WriteString(routeBuilderFunction)
+		return b.String(), nil
+	}
+
+	quoted := make([]string, len(manifest.I18n.Locales))
+	for i, locale := range manifest.I18n.Locales {
+		quoted[i] = fmt.Sprintf("'%s'", locale)
+	}
+	b.WriteString(fmt.Sprintf("\nexport type Locale = %s\n", strings.Join(quoted, " | ")))
+	b.WriteString(fmt.Sprintf("\nconst defaultLocale = '%s'\n", manifest.I18n.DefaultLocale))
+	if manifest.I18n.Strategy == LocaleStrategyDomain {
+		b.WriteString("const domains: Record<string, string> = {\n")
+		for _, locale := range sortedKeys(manifest.I18n.Domains) {
+			b.WriteString(fmt.Sprintf("\t'%s': '%s',\n", locale, manifest.I18n.Domains[locale]))
+		}
+		b.WriteString("}\n")
+		b.WriteString(localizeDomainFunction)
+	} else {
+		b.WriteString(localizePrefixFunction)
+	}
+	b.WriteString(localizedRouteBuilderFunction)
+
+	return b.String(), nil
+}
+
+func formatObjectType(fields []string) string {
+	if len(fields) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+// localizePrefixFunction puts every locale but the default one in front of the path
+const localizePrefixFunction = `
+function localize(href: string, locale?: Locale): string {
+	if (!locale || locale === defaultLocale) {
+		return href
+	}
+	return '/' + locale + (href === '/' ? '' : href)
+}
+`
+
+// localizeDomainFunction sends the url to the domain of the locale
+const localizeDomainFunction = `
+function localize(href: string, locale?: Locale): string {
+	if (!locale || !domains[locale]) {
+		return href
+	}
+	return '//' + domains[locale] + href
+}
+`
+
+// localizedRouteBuilderFunction is routeBuilderFunction with an extra argument to pick the
+// locale of the url
+const localizedRouteBuilderFunction = `
+type RouteOptions = { locale?: Locale }
+
+type RouteArgs<R extends RouteID> = {} extends RouteParams[R]
+	? [params?: RouteParams[R], search?: RouteSearch[R], options?: RouteOptions]
+	: [params: RouteParams[R], search?: RouteSearch[R], options?: RouteOptions]
+
+// route builds the url of a page from its route ID, params, and search params. Custom
+// scalars are marshaled the same way <Link> and goto() do it. Passing a locale builds the
+// url of the page in that locale.
+export function route<R extends RouteID>(id: R, ...[params, search, options]: RouteArgs<R>): string {
+	const m = manifest as any
+	const page = m.pages[m.pagesByUrl[id]] as RouteHrefInfo | undefined
+	const href = buildHref(
+		id,
+		page,
+		getCurrentConfig()?.scalars,
+		params as Record<string, unknown> | undefined,
+		search as Record<string, unknown> | undefined
+	)
+	return localize(href, options?.locale)
+}
+`
+
+// routeBuilderFunction is the part of route.ts that doesn't depend on the project. params
+// can only be left out when every param of the route is optional.
+const routeBuilderFunction = `
+type RouteArgs<R extends RouteID> = {} extends RouteParams[R]
+	? [params?: RouteParams[R], search?: RouteSearch[R]]
+	: [params: RouteParams[R], search?: RouteSearch[R]]
+
+// route builds the url of a page from its route ID, params, and search params. Custom
+// scalars are marshaled the same way <Link> and goto() do it.
+export function route<R extends RouteID>(id: R, ...[params, search]: RouteArgs<R>): string {
+	const m = manifest as any
+	const page = m.pages[m.pagesByUrl[id]] as RouteHrefInfo | undefined
+	return buildHref(
+		id,
+		page,
+		getCurrentConfig()?.scalars,
+		params as Record<string, unknown> | undefined,
+		search as Record<string, unknown> | undefined
+	)
+}
+`
+
 func generateTypeRoot(runtimeRel, artifactRelDir string, allQueries, pageQueries, layoutQueries, errorQueries []string, params map[string]*ParamTypeInfo) string {
 	var b strings.Builder
 
@@ -8,8 +8,8 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 
-	coreConfig "code.houdinigraphql.com/packages/houdini-core/config"
 	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
 	"code.houdinigraphql.com/plugins/tests"
 )
 
@@ -52,17 +52,17 @@ func insertComponentFields(t *testing.T, p *plugin.HoudiniReact, rows []map[stri
 }
 
 func TestGenerateComponentFieldWrappers(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema:            `type Query { id: ID }`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			if rows, ok := test.Extra["rows"].([]map[string]any); ok {
 				insertComponentFields(t, p, rows)
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			_, err := p.GenerateComponentFieldWrappers(ctx)
 			require.NoError(t, err)
@@ -75,7 +75,7 @@ func TestGenerateComponentFieldWrappers(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "generates wrapper that calls useFragment and registers with component cache",
 				Pass: true,
@@ -124,7 +124,7 @@ export default UserAvatar
 }
 
 func TestGenerateDocumentWrappers(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				id: ID
@@ -134,7 +134,7 @@ func TestGenerateDocumentWrappers(t *testing.T) {
 		`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			views, ok := test.Extra["views"].(map[string]string)
 			if !ok {
 				return
@@ -147,7 +147,7 @@ func TestGenerateDocumentWrappers(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			_, err := p.GenerateDocumentWrappers(ctx)
 			require.NoError(t, err)
@@ -160,7 +160,7 @@ func TestGenerateDocumentWrappers(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				// Ported from entries.test.ts "composes layouts and pages"
 				Name: "page unit passes queries as props",
@@ -274,11 +274,11 @@ export default ({ children }) => {
 }
 
 func TestGenerateFallbacks(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema:            `type Query { id: ID }`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			views, ok := test.Extra["views"].(map[string]string)
 			if !ok {
 				return
@@ -291,7 +291,7 @@ func TestGenerateFallbacks(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			_, err := p.GenerateFallbacks(ctx)
 			require.NoError(t, err)
@@ -304,7 +304,7 @@ func TestGenerateFallbacks(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				// Ported from entries.test.ts fallback assertions
 				Name: "generates fallback with loading queries",
@@ -440,11 +440,11 @@ export default ({ children }) => {
 }
 
 func TestGeneratePageEntries(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema:            `type Query { id: ID }`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			if views, ok := test.Extra["views"].(map[string]string); ok {
 				fs := p.Filesystem()
 				for fp, content := range views {
@@ -458,7 +458,7 @@ func TestGeneratePageEntries(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			_, err := p.GeneratePageEntries(ctx)
 			require.NoError(t, err)
@@ -471,7 +471,7 @@ func TestGeneratePageEntries(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				// Ported from entries.test.ts "composes layouts and pages"
 				Name: "composes layout wrappers and fallbacks around page",
@@ -606,7 +606,7 @@ export default ({ showLoading }) => {
 }
 
 func TestGenerateErrorWrappers(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				id: ID
@@ -616,7 +616,7 @@ func TestGenerateErrorWrappers(t *testing.T) {
 		`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			views, ok := test.Extra["views"].(map[string]string)
 			if !ok {
 				return
@@ -629,7 +629,7 @@ func TestGenerateErrorWrappers(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			_, err := p.GenerateErrorWrappers(ctx)
 			require.NoError(t, err)
@@ -642,7 +642,7 @@ func TestGenerateErrorWrappers(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "generates error wrapper with no layout queries",
 				Pass: true,
@@ -727,11 +727,11 @@ export default ({ children }) => (
 }
 
 func TestGenerateRenderInfrastructure(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema:            `type Query { id: ID }`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			if apiFiles, ok := test.Extra["api_files"].(map[string]string); ok {
 				fs := p.Filesystem()
 				for fp, content := range apiFiles {
@@ -745,7 +745,7 @@ func TestGenerateRenderInfrastructure(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			_, err := p.GenerateRenderInfrastructure(ctx)
 			require.NoError(t, err)
@@ -758,7 +758,7 @@ func TestGenerateRenderInfrastructure(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "generates App.jsx and config.js without schema or yoga",
 				Pass: true,
@@ -917,17 +917,18 @@ export function createServerAdapter(options) {
 }
 
 func TestGenerateTypeRoots(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				id: ID
 				node(id: ID!): Node
+				search(q: String, tags: [String!]): [Node!]!
 			}
 			interface Node { id: ID! }
 		`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			views, ok := test.Extra["views"].(map[string]string)
 			if !ok {
 				return
@@ -940,7 +941,7 @@ func TestGenerateTypeRoots(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			cfg, err := p.DB.ProjectConfig(ctx)
 			require.NoError(t, err)
@@ -955,9 +956,16 @@ func TestGenerateTypeRoots(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, expected, string(got), "file: %s", file)
 			}
+
+			// the route builder is written next to the manifest it reads
+			if expected, ok := test.Extra["route"].(string); ok {
+				got, err := afero.ReadFile(p.Filesystem(), filepath.Join(cfg.PluginRuntimeDirectory(p.Name()), "route.ts"))
+				require.NoError(t, err)
+				require.Equal(t, expected, string(got))
+			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				// Ported from typeRoot.test.ts "generates type files for pages"
 				Name: "generates $types.d.ts per route directory",
@@ -1129,6 +1137,76 @@ export type ErrorRoute = {
 					},
 				},
 			},
+			{
+				Name: "generates a typed route builder",
+				Pass: true,
+				Input: []string{
+					"query UserInfo($id: ID!, $tab: String, $tags: [String!]) {\n\tnode(id: $id) { id }\n\tsearch(q: $tab, tags: $tags) { id }\n}\n",
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+				},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/+page.tsx":                 mockView([]string{}),
+						"src/routes/(app)/settings/+page.tsx":  mockView([]string{}),
+						"src/routes/users/[id]/+page.tsx":      mockView([]string{"UserInfo"}),
+						"src/routes/docs/[[lang]]/+page.tsx":   mockView([]string{}),
+						"src/routes/files/[...path]/+page.tsx": mockView([]string{}),
+					},
+					"expected": map[string]string{},
+					"route": `// this file is generated by houdini — do not edit
+import { getCurrentConfig } from '$houdini/runtime/config'
+
+// @ts-ignore
+import manifest from './manifest.js'
+// @ts-ignore
+import type { _TSType } from './manifest.js'
+import { buildHref, type RouteHrefInfo } from './resolve-href.js'
+
+export type RouteID =
+	| '/'
+	| '/docs/[[lang]]'
+	| '/files/[...path]'
+	| '/settings'
+	| '/users/[id]'
+
+export type RouteParams = {
+	'/': {}
+	'/docs/[[lang]]': { lang?: _TSType<'String'> }
+	'/files/[...path]': { path?: _TSType<'String'> }
+	'/settings': {}
+	'/users/[id]': { id: _TSType<'ID'> }
+}
+
+export type RouteSearch = {
+	'/': Record<string, unknown>
+	'/docs/[[lang]]': Record<string, unknown>
+	'/files/[...path]': Record<string, unknown>
+	'/settings': Record<string, unknown>
+	'/users/[id]': { tab?: _TSType<'String'> | null, tags?: _TSType<'String'>[] | null } & Record<string, unknown>
+}
+
+type RouteArgs<R extends RouteID> = {} extends RouteParams[R]
+	? [params?: RouteParams[R], search?: RouteSearch[R]]
+	: [params: RouteParams[R], search?: RouteSearch[R]]
+
+// route builds the url of a page from its route ID, params, and search params. Custom
+// scalars are marshaled the same way <Link> and goto() do it.
+export function route<R extends RouteID>(id: R, ...[params, search]: RouteArgs<R>): string {
+	const m = manifest as any
+	const page = m.pages[m.pagesByUrl[id]] as RouteHrefInfo | undefined
+	return buildHref(
+		id,
+		page,
+		getCurrentConfig()?.scalars,
+		params as Record<string, unknown> | undefined,
+		search as Record<string, unknown> | undefined
+	)
+}
+`,
+				},
+			},
 		},
 	})
 }
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// LocaleStrategy is how the url of a page tells the router which locale to render
+type LocaleStrategy = string
+
+const (
+	// LocaleStrategyPrefix serves every locale but the default one under /[locale]/...
+	LocaleStrategyPrefix LocaleStrategy = "prefix"
+	// LocaleStrategyDomain serves each locale from its own domain
+	LocaleStrategyDomain LocaleStrategy = "domain"
+)
+
+// defaultLocaleVariable is the query variable that receives the locale when the config
+// doesn't name one
+const defaultLocaleVariable = "locale"
+
+// I18nConfig is the router.i18n section of the config file
+type I18nConfig struct {
+	Locales       []string          `json:"locales"`
+	DefaultLocale string            `json:"defaultLocale"`
+	Strategy      LocaleStrategy    `json:"strategy"`
+	Domains       map[string]string `json:"domains"`
+	Variable      string            `json:"variable"`
+}
+
+// loadI18nConfig returns the project's locale config, or nil when locale routing is off
+func (p *HoudiniReact) loadI18nConfig(ctx context.Context) (*I18nConfig, error) {
+	raw := ""
+	err := p.DB.StepQuery(ctx, `SELECT i18n FROM router_config WHERE i18n IS NOT NULL LIMIT 1`, nil, func(row plugins.Row) {
+		raw = row.ColumnText(0)
+	})
+	if err != nil || raw == "" {
+		return nil, err
+	}
+
+	config := &I18nConfig{}
+	if err := json.Unmarshal([]byte(raw), config); err != nil {
+		return nil, fmt.Errorf("could not parse router.i18n: %w", err)
+	}
+	if len(config.Locales) == 0 {
+		return nil, fmt.Errorf("router.i18n must list at least one locale")
+	}
+
+	if config.DefaultLocale == "" {
+		config.DefaultLocale = config.Locales[0]
+	}
+	if !slices.Contains(config.Locales, config.DefaultLocale) {
+		return nil, fmt.Errorf("router.i18n.defaultLocale %q is not one of the locales", config.DefaultLocale)
+	}
+	if config.Strategy == "" {
+		config.Strategy = LocaleStrategyPrefix
+	}
+	if config.Strategy != LocaleStrategyPrefix && config.Strategy != LocaleStrategyDomain {
+		return nil, fmt.Errorf(
+			"unknown router.i18n.strategy %q. use %q or %q",
+			config.Strategy, LocaleStrategyPrefix, LocaleStrategyDomain,
+		)
+	}
+	if config.Variable == "" {
+		config.Variable = defaultLocaleVariable
+	}
+
+	return config, nil
+}
+
+// localeVariable is the name of the variable the router fills with the locale, or empty
+// when locale routing is off
+func (c *I18nConfig) localeVariable() string {
+	if c == nil {
+		return ""
+	}
+	return c.Variable
+}
+
+// localizePages adds a variant of every page for each locale that isn't the default one.
+// The variants share the page's views and queries and only differ in their url and locale.
+// Domain routing keeps a single page since the url doesn't change between locales.
+func localizePages(manifest *ProjectManifest) {
+	config := manifest.I18n
+	if config == nil || config.Strategy != LocaleStrategyPrefix {
+		return
+	}
+
+	for _, id := range sortedKeys(manifest.Pages) {
+		page := manifest.Pages[id]
+		page.Locale = config.DefaultLocale
+		manifest.Pages[id] = page
+
+		for _, locale := range config.Locales {
+			if locale == config.DefaultLocale {
+				continue
+			}
+
+			url := "/" + locale
+			if page.URL != "/" {
+				url += page.URL
+			}
+			variant := page
+			variant.ID = pageID(url)
+			variant.URL = url
+			variant.Locale = locale
+			variant.Queries = clone(page.Queries)
+			variant.QueryOptions = clone(page.QueryOptions)
+			variant.LayoutQueries = clone(page.LayoutQueries)
+			variant.Layouts = clone(page.Layouts)
+			manifest.Pages[variant.ID] = variant
+
+			if query, ok := manifest.PageQueries[id]; ok {
+				query.URL = url
+				manifest.PageQueries[variant.ID] = query
+			}
+		}
+	}
+}
+
+// isLocaleVariant returns true if the page is the copy of another page for a locale that
+// isn't the default one
+func (m ProjectManifest) isLocaleVariant(page PageManifest) bool {
+	return m.I18n != nil && page.Locale != "" && page.Locale != m.I18n.DefaultLocale
+}
+
+// routerOrder returns the ids of the pages in the order the router tries them
+func (m ProjectManifest) routerOrder() []string {
+	ids := sortedKeys(m.Pages)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return routerLess(
+			ids[i], m.isLocaleVariant(m.Pages[ids[i]]),
+			ids[j], m.isLocaleVariant(m.Pages[ids[j]]),
+		)
+	})
+	return ids
+}
+
+// routerLess orders pages by id, except that the locale variants go first. Every variant
+// starts with its locale so a dynamic page at the root (like /[slug] or /[...rest]) would
+// otherwise match the url of a variant before the variant gets a chance.
+func routerLess(a string, aVariant bool, b string, bVariant bool) bool {
+	if aVariant != bVariant {
+		return aVariant
+	}
+	return a < b
+}
@@ -0,0 +1,210 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestLocaleRouting(t *testing.T) {
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
+		Schema: `
+			type Query {
+				node(id: ID!): Node
+				search(q: String, lang: String): [Node!]!
+			}
+			interface Node { id: ID! }
+		`,
+		SetupAlwaysPasses: true,
+
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			fs := p.Filesystem()
+			for fp, content := range test.Extra["views"].(map[string]string) {
+				abs := filepath.Join("/project", fp)
+				require.NoError(t, fs.MkdirAll(filepath.Dir(abs), 0755))
+				require.NoError(t, afero.WriteFile(fs, abs, []byte(content), 0644))
+			}
+
+			conn, err := p.DB.Take(context.Background())
+			require.NoError(t, err)
+			defer p.DB.Put(conn)
+			stmt, err := conn.Prepare(`INSERT INTO router_config (i18n, session_keys) VALUES ($i18n, '')`)
+			require.NoError(t, err)
+			defer stmt.Finalize()
+			require.NoError(t, p.DB.ExecStatement(stmt, map[string]any{"i18n": test.Extra["i18n"]}))
+		},
+
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			ctx := context.Background()
+			manifest, err := p.LoadManifest(ctx)
+			if !test.Pass {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			locales := map[string]string{}
+			for _, page := range manifest.Pages {
+				locales[page.URL] = page.Locale
+			}
+			require.Equal(t, test.Extra["locales"], locales)
+
+			if sources, ok := test.Extra["sources"].(map[string]map[string]plugin.VariableSource); ok {
+				for id, expected := range sources {
+					require.Contains(t, manifest.PageQueries, id)
+					require.Equal(t, expected, manifest.PageQueries[id].Sources, id)
+				}
+			}
+
+			if expected, ok := test.Extra["route"].(string); ok {
+				_, err := p.GenerateTypeRoots(ctx)
+				require.NoError(t, err)
+				cfg, err := p.DB.ProjectConfig(ctx)
+				require.NoError(t, err)
+				got, err := afero.ReadFile(p.Filesystem(), filepath.Join(cfg.PluginRuntimeDirectory(p.Name()), "route.ts"))
+				require.NoError(t, err)
+				require.Equal(t, expected, string(got))
+			}
+		},
+
+		Tests: []tests.Test[reactConfig.PluginConfig]{
+			{
+				Name: "prefix strategy adds a variant per locale",
+				Pass: true,
+				Input: []string{
+					`query UserInfo($id: ID!, $locale: String) { node(id: $id) { id } search(lang: $locale) { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+				},
+				Extra: map[string]any{
+					"i18n": `{"locales": ["en", "fr", "de"]}`,
+					"views": map[string]string{
+						"src/routes/+page.tsx":            mockView([]string{}),
+						"src/routes/users/[id]/+page.tsx": mockView([]string{"UserInfo"}),
+					},
+					"locales": map[string]string{
+						"/":              "en",
+						"/fr":            "fr",
+						"/de":            "de",
+						"/users/[id]":    "en",
+						"/fr/users/[id]": "fr",
+						"/de/users/[id]": "de",
+					},
+					// the locale never comes from the query string
+					"sources": map[string]map[string]plugin.VariableSource{
+						"_users__id_": {
+							"id":     plugin.VariableSourceRoute,
+							"locale": plugin.VariableSourceLocale,
+						},
+						"_fr_users__id_": {
+							"id":     plugin.VariableSourceRoute,
+							"locale": plugin.VariableSourceLocale,
+						},
+					},
+					"route": `// this file is generated by houdini — do not edit
+import { getCurrentConfig } from '$houdini/runtime/config'
+
+// @ts-ignore
+import manifest from './manifest.js'
+// @ts-ignore
+import type { _TSType } from './manifest.js'
+import { buildHref, type RouteHrefInfo } from './resolve-href.js'
+
+export type RouteID =
+	| '/'
+	| '/users/[id]'
+
+export type RouteParams = {
+	'/': {}
+	'/users/[id]': { id: _TSType<'ID'> }
+}
+
+export type RouteSearch = {
+	'/': Record<string, unknown>
+	'/users/[id]': Record<string, unknown>
+}
+
+export type Locale = 'en' | 'fr' | 'de'
+
+const defaultLocale = 'en'
+
+function localize(href: string, locale?: Locale): string {
+	if (!locale || locale === defaultLocale) {
+		return href
+	}
+	return '/' + locale + (href === '/' ? '' : href)
+}
+
+type RouteOptions = { locale?: Locale }
+
+type RouteArgs<R extends RouteID> = {} extends RouteParams[R]
+	? [params?: RouteParams[R], search?: RouteSearch[R], options?: RouteOptions]
+	: [params: RouteParams[R], search?: RouteSearch[R], options?: RouteOptions]
+
+// route builds the url of a page from its route ID, params, and search params. Custom
+// scalars are marshaled the same way <Link> and goto() do it. Passing a locale builds the
+// url of the page in that locale.
+export function route<R extends RouteID>(id: R, ...[params, search, options]: RouteArgs<R>): string {
+	const m = manifest as any
+	const page = m.pages[m.pagesByUrl[id]] as RouteHrefInfo | undefined
+	const href = buildHref(
+		id,
+		page,
+		getCurrentConfig()?.scalars,
+		params as Record<string, unknown> | undefined,
+		search as Record<string, unknown> | undefined
+	)
+	return localize(href, options?.locale)
+}
+`,
+				},
+			},
+			{
+				Name: "domain strategy keeps a single page",
+				Pass: true,
+				Input: []string{
+					`query Home($lang: String) { search(lang: $lang) { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/+page.gql",
+				},
+				Extra: map[string]any{
+					"i18n": `{"locales": ["en", "fr"], "strategy": "domain", "variable": "lang", "domains": {"fr": "example.fr"}}`,
+					"views": map[string]string{
+						"src/routes/+page.tsx": mockView([]string{"Home"}),
+					},
+					"locales": map[string]string{
+						"/": "",
+					},
+					"sources": map[string]map[string]plugin.VariableSource{
+						"_": {"lang": plugin.VariableSourceLocale},
+					},
+				},
+			},
+			{
+				Name: "default locale must be one of the locales",
+				Pass: false,
+				Extra: map[string]any{
+					"i18n":  `{"locales": ["en", "fr"], "defaultLocale": "de"}`,
+					"views": map[string]string{},
+				},
+			},
+			{
+				Name: "unknown strategy",
+				Pass: false,
+				Extra: map[string]any{
+					"i18n":  `{"locales": ["en"], "strategy": "cookie"}`,
+					"views": map[string]string{},
+				},
+			},
+		},
+	})
+}
@@ -36,6 +36,8 @@ type ProjectManifest struct {
 	// writes the session. Used by the session-mint plugin (any execution) and the no-JS form
 	// handler (inline cookie write). Independent of FormActions.
 	SessionMutations map[string]SessionMutationInfo `json:"session_mutations"`
+	// I18n is the locale routing config. It's nil when the project only has one locale.
+	I18n *I18nConfig `json:"i18n,omitempty"`
 }
 
 // SessionMutationInfo is how a @session mutation writes the session: the result field `Path`
@@ -63,6 +65,9 @@ type PageManifest struct {
 	// Headers is true when the view file exports a `headers()` function whose
 	// result should be merged into the HTTP response before streaming.
 	Headers bool `json:"headers"`
+	// Locale is the locale the page renders with prefix routing. Every locale but the
+	// default one gets its own copy of the page.
+	Locale string `json:"locale,omitempty"`
 }
 
 // ParamTypeInfo describes the GraphQL type of a URL route parameter.
@@ -78,8 +83,25 @@ type QueryManifest struct {
 	Loading   bool                        `json:"loading"`
 	Path      string                      `json:"path"`
 	Variables map[string]VariableTypeInfo `json:"variables"`
+	// Sources records how the router provides each variable. It's nil for queries
+	// without variables.
+	Sources map[string]VariableSource `json:"sources,omitempty"`
 }
 
+// VariableSource is where the router gets the value of a route query's variable.
+type VariableSource = string
+
+const (
+	// VariableSourceRoute variables are filled by a dynamic route segment
+	VariableSourceRoute VariableSource = "route"
+	// VariableSourceSearch variables are nullable and read from URLSearchParams
+	VariableSourceSearch VariableSource = "search"
+	// VariableSourceDefault variables are required but always use their default value
+	VariableSourceDefault VariableSource = "default"
+	// VariableSourceLocale variables receive the locale the page is rendered in
+	VariableSourceLocale VariableSource = "locale"
+)
+
 type VariableTypeInfo struct {
 	Type     string   `json:"type"`
 	Wrappers []string `json:"wrappers"`
@@ -93,8 +115,11 @@ type ComponentFieldInfo struct {
 type routeDoc struct {
 	name      string
 	filepath  string // as stored in raw_documents, relative to project root
+	line      int
+	column    int
 	loading   bool
 	variables map[string]VariableTypeInfo
+	defaults  map[string]bool // variables that declare a default value
 }
 
 // walkState carries accumulated context as we descend the route tree.
@@ -133,6 +158,13 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 		return ProjectManifest{}, err
 	}
 
+	// the locale is a well-known variable that the router fills instead of the url
+	manifest.I18n, err = p.loadI18nConfig(ctx)
+	if err != nil {
+		return ProjectManifest{}, err
+	}
+	localeVariable := manifest.I18n.localeVariable()
+
 	// @session mutations (name → sessionPath) — independent of the route documents above, since a
 	// session-establishing mutation need not be a form.
 	manifest.SessionMutations, err = p.loadSessionMutations(ctx)
@@ -187,6 +219,10 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 		return dirs[i] < dirs[j]
 	})
 
+	// every required variable of a route query has to be provided by the router. we collect
+	// the ones that can't be so the user sees all of them at once.
+	errs := &plugins.ErrorList{}
+
 	// Phase 3: iterate topologically, accumulating layout query scope and building the manifest.
 	claimedPageDocs := map[string]bool{}
 	stateByDir := map[string]walkState{
@@ -219,6 +255,7 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 				Loading:   layoutDoc.loading,
 				Path:      qPath,
 				Variables: cloneVariables(layoutDoc.variables),
+				Sources:   resolveVariableSources(url, layoutDoc, localeVariable, errs),
 			}
 			newLayoutQueries = append(newLayoutQueries, layoutDoc.name)
 			for k, v := range layoutDoc.variables {
@@ -238,7 +275,7 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 				Layouts:       clone(state.availableLayouts),
 				Path:          relPath,
 				Params:        buildParams(url, newVariables),
-				SearchParams:  buildSearchParams(url, newVariables),
+				SearchParams:  buildSearchParams(url, newVariables, localeVariable),
 				Headers:       info.layoutHeaders,
 			}
 			newLayoutIDs = append(newLayoutIDs, id)
@@ -253,6 +290,7 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 				Loading:   pageDoc.loading,
 				Path:      qPath,
 				Variables: cloneVariables(pageDoc.variables),
+				Sources:   resolveVariableSources(url, pageDoc, localeVariable, errs),
 			}
 		}
 
@@ -288,7 +326,7 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 				Path:          relPath,
 				ErrorPath:     errorPath,
 				Params:        buildParams(url, allVars),
-				SearchParams:  buildSearchParams(url, allVars),
+				SearchParams:  buildSearchParams(url, allVars, localeVariable),
 				Headers:       info.pageHeaders,
 			}
 		}
@@ -318,6 +356,12 @@ func (p *HoudiniReact) LoadManifest(ctx context.Context) (ProjectManifest, error
 		}
 	}
 
+	if errs.Len() > 0 {
+		return ProjectManifest{}, errs
+	}
+
+	localizePages(&manifest)
+
 	manifest.LocalSchema, manifest.LocalYoga, manifest.LocalConfig, err = p.detectLocalServer(serverDir)
 	if err != nil {
 		return ProjectManifest{}, err
@@ -384,7 +428,10 @@ func (p *HoudiniReact) loadRouteDocuments(
 		       CASE WHEN EXISTS(
 		           SELECT 1 FROM document_directives dd
 		           WHERE dd.document = d.id AND dd.directive = $endpoint_directive
-		       ) THEN 1 ELSE 0 END AS has_endpoint
+		       ) THEN 1 ELSE 0 END AS has_endpoint,
+		       rd.offset_line,
+		       rd.offset_column,
+		       dv.default_value IS NOT NULL AS has_default
 		FROM documents d
 		JOIN raw_documents rd ON d.raw_document = rd.id
 		LEFT JOIN document_variables dv ON dv.document = d.id
@@ -425,8 +472,11 @@ func (p *HoudiniReact) loadRouteDocuments(
 				doc: routeDoc{
 					name:      name,
 					filepath:  fp,
+					line:      q.ColumnInt(9),
+					column:    q.ColumnInt(10),
 					loading:   q.ColumnInt(4) == 1,
 					variables: map[string]VariableTypeInfo{},
+					defaults:  map[string]bool{},
 				},
 				isPage: strings.HasSuffix(fp, "+page.gql"),
 				dirKey: dirKey,
@@ -439,6 +489,9 @@ func (p *HoudiniReact) loadRouteDocuments(
 				Type:     q.ColumnText(6),
 				Wrappers: modifiersToWrappers(q.ColumnText(7)),
 			}
+			if q.ColumnBool(11) {
+				entry.doc.defaults[varName] = true
+			}
 		}
 	})
 	if err != nil {
@@ -664,6 +717,55 @@ func routeParamSet(path string) map[string]bool {
 	return names
 }
 
+// resolveVariableSources decides how the router provides each variable of a route query.
+// Route segments win, then the locale variable (when locale routing is on) gets the page's
+// locale, nullable variables are read from the search params, and required variables fall
+// back to their default value. A required variable without a default can't be provided at
+// all so it's reported as an error.
+func resolveVariableSources(url string, doc routeDoc, localeVariable string, errs *plugins.ErrorList) map[string]VariableSource {
+	if len(doc.variables) == 0 {
+		return nil
+	}
+
+	routeNames := routeParamSet(url)
+	sources := map[string]VariableSource{}
+	for _, name := range sortedKeys(doc.variables) {
+		info := doc.variables[name]
+		switch {
+		case routeNames[name]:
+			sources[name] = VariableSourceRoute
+		case localeVariable != "" && name == localeVariable:
+			sources[name] = VariableSourceLocale
+		case len(info.Wrappers) == 0 || info.Wrappers[0] != "NonNull":
+			sources[name] = VariableSourceSearch
+		case doc.defaults[name]:
+			sources[name] = VariableSourceDefault
+		default:
+			errs.Append(unprovidedVariableError(doc.name, name, doc.filepath, doc.line, doc.column))
+		}
+	}
+	return sources
+}
+
+// unprovidedVariableError is the error for a required route query variable that the
+// router has no way to fill
+func unprovidedVariableError(docName, varName, filepath string, line, column int) *plugins.Error {
+	return &plugins.Error{
+		Message: fmt.Sprintf(
+			"required variable $%s on %q can't be provided by the router: "+
+				"add a [%s] route segment, give it a default value, or make it nullable "+
+				"so it can be supplied via search params",
+			varName, docName, varName,
+		),
+		Kind: plugins.ErrorKindValidation,
+		Locations: []*plugins.ErrorLocation{{
+			Filepath: filepath,
+			Line:     line,
+			Column:   column,
+		}},
+	}
+}
+
 // buildParams maps a route's dynamic segments to the types of the variables they fill.
 // A segment with no matching variable maps to nil (an unconstrained param).
 func buildParams(url string, variables map[string]VariableTypeInfo) map[string]*ParamTypeInfo {
@@ -680,14 +782,14 @@ func buildParams(url string, variables map[string]VariableTypeInfo) map[string]*
 
 // buildSearchParams returns the variables in scope that can be supplied via
 // URLSearchParams: every nullable variable that is not already consumed by a
-// route segment. Required (NonNull) variables are excluded so that a missing
-// search param can never produce a failing query (issue #1210).
-func buildSearchParams(url string, variables map[string]VariableTypeInfo) map[string]*ParamTypeInfo {
+// route segment or the locale. Required (NonNull) variables are excluded so that a
+// missing search param can never produce a failing query (issue #1210).
+func buildSearchParams(url string, variables map[string]VariableTypeInfo, localeVariable string) map[string]*ParamTypeInfo {
 	routeNames := routeParamSet(url)
 
 	searchParams := map[string]*ParamTypeInfo{}
 	for name, info := range variables {
-		if routeNames[name] {
+		if routeNames[name] || (localeVariable != "" && name == localeVariable) {
 			continue
 		}
 		// a NonNull outer wrapper means the variable is required — skip it
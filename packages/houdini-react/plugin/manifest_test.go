@@ -8,8 +8,9 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 
-	coreConfig "code.houdinigraphql.com/packages/houdini-core/config"
 	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins"
 	"code.houdinigraphql.com/plugins/tests"
 )
 
@@ -35,17 +36,18 @@ func mockQuery(name string, loading bool) string {
 }
 
 func TestLoadManifest(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				id: ID
 				node(id: ID!): Node
+				search(q: String): [Node!]!
 			}
 			interface Node { id: ID! }
 		`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			views, ok := test.Extra["views"].(map[string]string)
 			if !ok {
 				return
@@ -62,11 +64,20 @@ func TestLoadManifest(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			got, err := p.LoadManifest(context.Background())
 
 			if !test.Pass {
 				require.Error(t, err)
+				if message, ok := test.Extra["message"].(string); ok {
+					list, ok := err.(*plugins.ErrorList)
+					require.True(t, ok, "expected an ErrorList")
+					require.Equal(t, 1, list.Len())
+					item := list.GetItems()[0]
+					require.Equal(t, plugins.ErrorKindValidation, item.Kind)
+					require.Equal(t, message, item.Message)
+					require.Equal(t, []*plugins.ErrorLocation{test.Extra["location"].(*plugins.ErrorLocation)}, item.Locations)
+				}
 				return
 			}
 			require.NoError(t, err)
@@ -74,9 +85,16 @@ func TestLoadManifest(t *testing.T) {
 			if expected, ok := test.Extra["expected"].(plugin.ProjectManifest); ok {
 				require.Equal(t, expected, got)
 			}
+			if sources, ok := test.Extra["sources"].(map[string]map[string]plugin.VariableSource); ok {
+				for id, expected := range sources {
+					query, ok := got.PageQueries[id]
+					require.True(t, ok, "expected a page query for %s", id)
+					require.Equal(t, expected, query.Sources)
+				}
+			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "empty routes dir generates empty manifest",
 				Pass: true,
@@ -406,6 +424,9 @@ func TestLoadManifest(t *testing.T) {
 								Variables: map[string]plugin.VariableTypeInfo{
 									"id": {Type: "ID", Wrappers: []string{"NonNull"}},
 								},
+								Sources: map[string]plugin.VariableSource{
+									"id": plugin.VariableSourceRoute,
+								},
 							},
 						},
 						Artifacts:       []string{},
@@ -667,6 +688,44 @@ func TestLoadManifest(t *testing.T) {
 					},
 				},
 			},
+			{
+				Name: "required variables must come from the route",
+				Pass: false,
+				Input: []string{
+					"query UserQuery($userID: ID!) {\n\tnode(id: $userID) {\n\t\tid\n\t}\n}\n",
+				},
+				Filepaths: []string{"src/routes/[id]/+page.gql"},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/[id]/+page.tsx": mockView([]string{"UserQuery"}),
+					},
+					"message": `required variable $userID on "UserQuery" can't be provided by the router: ` +
+						"add a [userID] route segment, give it a default value, or make it nullable " +
+						"so it can be supplied via search params",
+					"location": &plugins.ErrorLocation{Filepath: "src/routes/[id]/+page.gql", Line: 0, Column: 0},
+				},
+			},
+			{
+				Name: "variables can come from the route, search params, or defaults",
+				Pass: true,
+				Input: []string{
+					"query UserQuery($id: ID!, $other: String, $fallback: ID! = \"1\") {\n\tnode(id: $id) {\n\t\tid\n\t}\n\tsearch(q: $other) {\n\t\tid\n\t}\n\tfallback: node(id: $fallback) {\n\t\tid\n\t}\n}\n",
+				},
+				Filepaths: []string{"src/routes/[id]/+page.gql"},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/[id]/+page.tsx": mockView([]string{"UserQuery"}),
+					},
+					// keyed by the page id
+					"sources": map[string]map[string]plugin.VariableSource{
+						"__id_": {
+							"id":       plugin.VariableSourceRoute,
+							"other":    plugin.VariableSourceSearch,
+							"fallback": plugin.VariableSourceDefault,
+						},
+					},
+				},
+			},
 		},
 	})
 }
@@ -5,7 +5,7 @@ import (
 
 	"github.com/spf13/afero"
 
-	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-react/plugin/config"
 	"code.houdinigraphql.com/plugins"
 )
 
@@ -0,0 +1,600 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins"
+)
+
+// PrefetchReport describes how each route fetches its data. The router sends every layout
+// and page query of a route in parallel, so the problems it reports come from data that is
+// fetched outside of that batch or fetched more than once inside of it.
+type PrefetchReport struct {
+	Routes []RoutePrefetch `json:"routes"`
+}
+
+// RoutePrefetch is the analysis of a single page
+type RoutePrefetch struct {
+	ID              string           `json:"id"`
+	URL             string           `json:"url"`
+	Waterfalls      []Waterfall      `json:"waterfalls"`
+	DuplicateFields []DuplicateField `json:"duplicate_fields"`
+	// Preload is every query that can be sent as soon as the route is known. It's the route's
+	// own queries followed by the queries that cause waterfalls but only need values the
+	// router already has.
+	Preload []PreloadQuery `json:"preload"`
+}
+
+// WaterfallReason is why a query is only sent after other queries have resolved
+type WaterfallReason = string
+
+const (
+	// WaterfallReasonComponent queries are sent by a component that lives next to a page or
+	// layout, which only renders after the route queries without @loading resolve
+	WaterfallReasonComponent WaterfallReason = "component"
+	// WaterfallReasonComponentField queries are sent by a component field, which only renders
+	// once the query that selects the field resolves
+	WaterfallReasonComponentField WaterfallReason = "componentField"
+)
+
+// Waterfall is a query that the route can only send once the queries in After have resolved
+type Waterfall struct {
+	Query    string          `json:"query"`
+	Filepath string          `json:"filepath"`
+	Reason   WaterfallReason `json:"reason"`
+	After    []string        `json:"after"`
+}
+
+// DuplicateField is a root field (with the same argument values) selected by more than one of a
+// route's queries
+type DuplicateField struct {
+	Field   string   `json:"field"`
+	Queries []string `json:"queries"`
+}
+
+// PreloadSource is where a query in a preload plan comes from
+type PreloadSource = string
+
+const (
+	PreloadSourceLayout    PreloadSource = "layout"
+	PreloadSourcePage      PreloadSource = "page"
+	PreloadSourceComponent PreloadSource = "component"
+)
+
+// PreloadQuery is a single query of a route's preload plan
+type PreloadQuery struct {
+	Name   string        `json:"name"`
+	Source PreloadSource `json:"source"`
+}
+
+// prefetchDocument is what the analysis needs to know about a query that isn't a route query
+type prefetchDocument struct {
+	name     string
+	filepath string
+	// the variables that have to be given a value: non-null without a default
+	required []string
+}
+
+// AnalyzePrefetch looks at every page of the project for queries that are fetched in sequence
+// and root fields that the route's queries fetch more than once. Only the queries of files
+// that a route renders count: the files its views import and the files of the component
+// fields its queries select.
+func (p *HoudiniReact) AnalyzePrefetch(ctx context.Context) (PrefetchReport, error) {
+	report := PrefetchReport{Routes: []RoutePrefetch{}}
+
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	manifest, err := p.LoadManifest(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	// route queries are sent by the router so they aren't candidates for a waterfall
+	routeQueries := map[string]QueryManifest{}
+	for _, queries := range []map[string]QueryManifest{manifest.PageQueries, manifest.LayoutQueries} {
+		for _, query := range queries {
+			routeQueries[query.Name] = query
+		}
+	}
+
+	// every other query the user wrote
+	others := map[string]*prefetchDocument{}
+	err = p.DB.StepQuery(ctx, `
+		SELECT d.name, rd.filepath, dv.name
+		FROM documents d
+			JOIN raw_documents rd ON rd.id = d.raw_document
+			LEFT JOIN document_variables dv ON dv.document = d.id
+				AND dv.type_modifiers LIKE '%!'
+				AND dv.default_value IS NULL
+		WHERE d.kind = 'query' AND d.generated = false
+	`, nil, func(row plugins.Row) {
+		name := row.ColumnText(0)
+		if _, ok := routeQueries[name]; ok {
+			return
+		}
+		doc, ok := others[name]
+		if !ok {
+			doc = &prefetchDocument{name: name, filepath: row.ColumnText(1)}
+			others[name] = doc
+		}
+		if variable := row.ColumnText(2); variable != "" {
+			doc.required = append(doc.required, variable)
+		}
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// the fragments (and component fields) that each document spreads
+	dependencies := map[string][]string{}
+	err = p.DB.StepQuery(ctx, `
+		SELECT d.name, dd.depends_on
+		FROM document_dependencies dd
+			JOIN documents d ON d.id = dd.document
+	`, nil, func(row plugins.Row) {
+		dependencies[row.ColumnText(0)] = append(dependencies[row.ColumnText(0)], row.ColumnText(1))
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// component fields are rendered by the component in the file that defines them
+	componentFields, err := p.loadComponentFields(ctx)
+	if err != nil {
+		return report, err
+	}
+	componentFieldFiles := map[string]string{}
+	for _, field := range componentFields {
+		componentFieldFiles[field.fragment] = field.filepath
+	}
+
+	rootFields, defaults, err := p.loadRootFields(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, id := range sortedKeys(manifest.Pages) {
+		page := manifest.Pages[id]
+		route := RoutePrefetch{
+			ID:              id,
+			URL:             page.URL,
+			Waterfalls:      []Waterfall{},
+			DuplicateFields: []DuplicateField{},
+			Preload:         []PreloadQuery{},
+		}
+
+		// the queries the router sends for this page, outermost first
+		scope := []PreloadQuery{}
+		for _, name := range page.LayoutQueries {
+			scope = append(scope, PreloadQuery{Name: name, Source: PreloadSourceLayout})
+		}
+		if query, ok := manifest.PageQueries[id]; ok {
+			scope = append(scope, PreloadQuery{Name: query.Name, Source: PreloadSourcePage})
+		}
+		route.Preload = append(route.Preload, scope...)
+
+		// anything rendered by the route has to wait for the queries that don't have a loading state
+		blocking := []string{}
+		for _, query := range scope {
+			if !routeQueries[query.Name].Loading {
+				blocking = append(blocking, query.Name)
+			}
+		}
+
+		// the components the route renders are the views of the page and its layouts along
+		// with everything they import
+		views := []string{page.Path}
+		for _, layoutID := range page.Layouts {
+			if layout, ok := manifest.Layouts[layoutID]; ok {
+				views = append(views, layout.Path)
+			}
+		}
+		rendered := p.importedFiles(projectConfig.ProjectRoot, views)
+		late := map[string]bool{}
+		if len(blocking) > 0 {
+			for _, name := range sortedKeys(others) {
+				doc := others[name]
+				if !rendered[doc.filepath] {
+					continue
+				}
+				late[doc.name] = true
+				route.Waterfalls = append(route.Waterfalls, Waterfall{
+					Query:    doc.name,
+					Filepath: doc.filepath,
+					Reason:   WaterfallReasonComponent,
+					After:    clone(blocking),
+				})
+			}
+		}
+
+		// component fields render once the query that selects them resolves, no matter @loading
+		for _, query := range scope {
+			files := []string{}
+			for _, fragment := range transitiveDependencies(query.Name, dependencies) {
+				if file, ok := componentFieldFiles[fragment]; ok {
+					files = append(files, file)
+				}
+			}
+			if len(files) == 0 {
+				continue
+			}
+			fieldFiles := p.importedFiles(projectConfig.ProjectRoot, files)
+			for _, name := range sortedKeys(others) {
+				doc := others[name]
+				if late[doc.name] || !fieldFiles[doc.filepath] {
+					continue
+				}
+				late[doc.name] = true
+				route.Waterfalls = append(route.Waterfalls, Waterfall{
+					Query:    doc.name,
+					Filepath: doc.filepath,
+					Reason:   WaterfallReasonComponentField,
+					After:    []string{query.Name},
+				})
+			}
+		}
+
+		// a late query can join the preload if the router can provide all of its required variables
+		params := routeParamSet(page.URL)
+		for _, waterfall := range route.Waterfalls {
+			hoistable := true
+			for _, variable := range others[waterfall.Query].required {
+				if !params[variable] {
+					hoistable = false
+					break
+				}
+			}
+			if hoistable {
+				route.Preload = append(route.Preload, PreloadQuery{
+					Name:   waterfall.Query,
+					Source: PreloadSourceComponent,
+				})
+			}
+		}
+
+		// look for root fields that more than one of the route's queries select. arguments are
+		// compared by the value the router gives them so two queries only match when they
+		// send the same thing
+		selectedBy := map[string][]string{}
+		display := map[string]string{}
+		for _, query := range scope {
+			for _, field := range rootFields[query.Name] {
+				key := field.signature(func(variable string) string {
+					return resolveRouteVariable(routeQueries[query.Name], variable, defaults[query.Name])
+				})
+				if _, ok := display[key]; !ok {
+					display[key] = field.signature(func(variable string) string { return "$" + variable })
+				}
+				if !slices.Contains(selectedBy[key], query.Name) {
+					selectedBy[key] = append(selectedBy[key], query.Name)
+				}
+			}
+		}
+		duplicates := []DuplicateField{}
+		for key, queries := range selectedBy {
+			if len(queries) > 1 {
+				duplicates = append(duplicates, DuplicateField{Field: display[key], Queries: queries})
+			}
+		}
+		sort.Slice(duplicates, func(i, j int) bool {
+			return duplicates[i].Field < duplicates[j].Field
+		})
+		route.DuplicateFields = append(route.DuplicateFields, duplicates...)
+
+		report.Routes = append(report.Routes, route)
+	}
+
+	sort.SliceStable(report.Routes, func(i, j int) bool {
+		return report.Routes[i].URL < report.Routes[j].URL
+	})
+
+	return report, nil
+}
+
+// Problems returns the routes that have a waterfall or a duplicate field
+func (r PrefetchReport) Problems() []RoutePrefetch {
+	routes := []RoutePrefetch{}
+	for _, route := range r.Routes {
+		if len(route.Waterfalls) > 0 || len(route.DuplicateFields) > 0 {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// PreloadPlan maps the url of every route to the queries it can send as soon as it's known
+func (r PrefetchReport) PreloadPlan() map[string][]PreloadQuery {
+	plan := map[string][]PreloadQuery{}
+	for _, route := range r.Routes {
+		plan[route.URL] = route.Preload
+	}
+	return plan
+}
+
+func (r PrefetchReport) String() string {
+	problems := r.Problems()
+	if len(problems) == 0 {
+		return "No waterfalls or duplicate fields found."
+	}
+
+	var b strings.Builder
+	for i, route := range problems {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(route.URL + "\n")
+		for _, waterfall := range route.Waterfalls {
+			fmt.Fprintf(&b, "  waterfall: %s (%s) waits for %s\n",
+				waterfall.Query, waterfall.Filepath, strings.Join(waterfall.After, ", "))
+		}
+		for _, field := range route.DuplicateFields {
+			fmt.Fprintf(&b, "  duplicate: %s is fetched by %s\n",
+				field.Field, strings.Join(field.Queries, ", "))
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// rootField is a root field of a query along with the values of its arguments
+type rootField struct {
+	field string
+	args  []rootFieldArgument
+}
+
+type rootFieldArgument struct {
+	name  string
+	value *argumentValue
+}
+
+// argumentValue is an argument value along with the values it's built from (the items of a
+// list or the fields of an object)
+type argumentValue struct {
+	kind     string
+	raw      string
+	children []argumentChild
+}
+
+type argumentChild struct {
+	name  string
+	value *argumentValue
+}
+
+// signature prints the field with its arguments, e.g. user(id: $id), replacing every variable
+// with the value that resolve returns for it
+func (f rootField) signature(resolve func(variable string) string) string {
+	if len(f.args) == 0 {
+		return f.field
+	}
+	args := []string{}
+	for _, arg := range f.args {
+		args = append(args, arg.name+": "+arg.value.print(resolve))
+	}
+	return f.field + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (v *argumentValue) print(resolve func(variable string) string) string {
+	switch v.kind {
+	case "Variable":
+		return resolve(v.raw)
+	case "String", "Block":
+		return fmt.Sprintf("%q", v.raw)
+	case "Null":
+		return "null"
+	case "List":
+		items := []string{}
+		for _, child := range v.children {
+			items = append(items, child.value.print(resolve))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case "Object":
+		fields := []string{}
+		for _, child := range v.children {
+			fields = append(fields, child.name+": "+child.value.print(resolve))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return v.raw
+}
+
+// resolveRouteVariable describes where the router gets the value of a route query's variable.
+// Variables filled from the same place hold the same value no matter which query they belong
+// to. A variable the router can't fill only matches itself.
+func resolveRouteVariable(query QueryManifest, variable string, defaults map[string]*argumentValue) string {
+	switch query.Sources[variable] {
+	case VariableSourceRoute:
+		return "route." + variable
+	case VariableSourceSearch:
+		return "search." + variable
+	case VariableSourceLocale:
+		return "locale"
+	case VariableSourceDefault:
+		if value, ok := defaults[variable]; ok {
+			return value.print(func(variable string) string { return "$" + variable })
+		}
+	}
+	return query.Name + ".$" + variable
+}
+
+// loadRootFields returns the root fields of every query along with the default values of
+// every query's variables
+func (p *HoudiniReact) loadRootFields(
+	ctx context.Context,
+) (map[string][]rootField, map[string]map[string]*argumentValue, error) {
+	// every argument value of the project's queries
+	values := map[int64]*argumentValue{}
+	err := p.DB.StepQuery(ctx, `
+		SELECT av.id, av.kind, av.raw
+		FROM argument_values av
+			JOIN documents d ON d.id = av.document
+		WHERE d.kind = 'query'
+	`, nil, func(row plugins.Row) {
+		values[row.ColumnInt64(0)] = &argumentValue{kind: row.ColumnText(1), raw: row.ColumnText(2)}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = p.DB.StepQuery(ctx, `
+		SELECT avc.parent, avc.name, avc.value
+		FROM argument_value_children avc
+			JOIN documents d ON d.id = avc.document
+		WHERE d.kind = 'query'
+		ORDER BY avc.id
+	`, nil, func(row plugins.Row) {
+		parent, ok := values[row.ColumnInt64(0)]
+		if !ok {
+			return
+		}
+		parent.children = append(parent.children, argumentChild{
+			name:  row.ColumnText(1),
+			value: values[row.ColumnInt64(2)],
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type selection struct {
+		document string
+		field    rootField
+	}
+	fields := map[int64]*selection{}
+	order := []int64{}
+
+	err = p.DB.StepQuery(ctx, `
+		SELECT s.id, d.name, s.field_name, sa.name, sa.value
+		FROM selection_refs sr
+			JOIN documents d ON d.id = sr.document
+			JOIN selections s ON s.id = sr.child_id
+			LEFT JOIN selection_arguments sa ON sa.selection_id = s.id AND sa.document = d.id
+		WHERE sr.parent_id IS NULL
+			AND s.kind = 'field'
+			AND d.kind = 'query'
+		ORDER BY s.id, sa.name
+	`, nil, func(row plugins.Row) {
+		id := row.ColumnInt64(0)
+		field, ok := fields[id]
+		if !ok {
+			field = &selection{document: row.ColumnText(1), field: rootField{field: row.ColumnText(2)}}
+			fields[id] = field
+			order = append(order, id)
+		}
+		value, ok := values[row.ColumnInt64(4)]
+		if name := row.ColumnText(3); name != "" && ok {
+			field.field.args = append(field.field.args, rootFieldArgument{name: name, value: value})
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaults := map[string]map[string]*argumentValue{}
+	err = p.DB.StepQuery(ctx, `
+		SELECT d.name, dv.name, dv.default_value
+		FROM document_variables dv
+			JOIN documents d ON d.id = dv.document
+		WHERE d.kind = 'query' AND dv.default_value IS NOT NULL
+	`, nil, func(row plugins.Row) {
+		value, ok := values[row.ColumnInt64(2)]
+		if !ok {
+			return
+		}
+		if defaults[row.ColumnText(0)] == nil {
+			defaults[row.ColumnText(0)] = map[string]*argumentValue{}
+		}
+		defaults[row.ColumnText(0)][row.ColumnText(1)] = value
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := map[string][]rootField{}
+	for _, id := range order {
+		result[fields[id].document] = append(result[fields[id].document], fields[id].field)
+	}
+	return result, defaults, nil
+}
+
+// importRe matches the module of an import, a re-export or a dynamic import
+var importRe = regexp.MustCompile(`(?:\bfrom|\bimport\s*\(?)\s*['"]([^'"]+)['"]`)
+
+// importExtensions are tried in order when an import leaves off the extension of a file
+var importExtensions = []string{"", ".tsx", ".ts", ".jsx", ".js", ".gql", ".graphql"}
+
+// importedFiles returns the files along with every file they import, directly or through
+// other files. Only relative imports are followed. Paths are relative to the project root,
+// like the filepaths of raw documents.
+func (p *HoudiniReact) importedFiles(root string, files []string) map[string]bool {
+	reached := map[string]bool{}
+	queue := append([]string{}, files...)
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		if reached[file] {
+			continue
+		}
+		reached[file] = true
+
+		content, err := afero.ReadFile(p.Filesystem(), filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		for _, match := range importRe.FindAllStringSubmatch(string(content), -1) {
+			if !strings.HasPrefix(match[1], "./") && !strings.HasPrefix(match[1], "../") {
+				continue
+			}
+			if imported, ok := p.resolveImport(root, filepath.Join(filepath.Dir(file), match[1])); ok {
+				queue = append(queue, imported)
+			}
+		}
+	}
+	return reached
+}
+
+// resolveImport finds the file that an import without an extension (or of a directory) points to
+func (p *HoudiniReact) resolveImport(root string, target string) (string, bool) {
+	candidates := []string{}
+	for _, ext := range importExtensions {
+		candidates = append(candidates, target+ext)
+	}
+	for _, ext := range importExtensions[1:] {
+		candidates = append(candidates, filepath.Join(target, "index"+ext))
+	}
+	for _, candidate := range candidates {
+		info, err := p.Filesystem().Stat(filepath.Join(root, candidate))
+		if err == nil && !info.IsDir() {
+			return toSlash(candidate), true
+		}
+	}
+	return "", false
+}
+
+// transitiveDependencies walks the fragments a document spreads, including the ones spread
+// by those fragments
+func transitiveDependencies(document string, dependencies map[string][]string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	queue := append([]string{}, dependencies[document]...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		result = append(result, next)
+		queue = append(queue, dependencies[next]...)
+	}
+	return result
+}
@@ -0,0 +1,273 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestAnalyzePrefetch(t *testing.T) {
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
+		Schema: `
+			type Query {
+				viewer: User
+				user(id: ID!): User
+				feed(first: Int): [User!]!
+			}
+			type User {
+				id: ID!
+				name: String!
+				avatar: String!
+			}
+		`,
+		SetupAlwaysPasses: true,
+
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			views, ok := test.Extra["views"].(map[string]string)
+			if !ok {
+				return
+			}
+			fs := p.Filesystem()
+			for fp, content := range views {
+				abs := filepath.Join("/project", fp)
+				require.NoError(t, fs.MkdirAll(filepath.Dir(abs), 0755))
+				require.NoError(t, afero.WriteFile(fs, abs, []byte(content), 0644))
+			}
+		},
+
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			report, err := p.AnalyzePrefetch(context.Background())
+			require.NoError(t, err)
+
+			routes := map[string]plugin.RoutePrefetch{}
+			for _, route := range report.Routes {
+				routes[route.URL] = route
+			}
+			for url, expected := range test.Extra["expected"].(map[string]plugin.RoutePrefetch) {
+				require.Contains(t, routes, url)
+				require.Equal(t, expected.Waterfalls, routes[url].Waterfalls, url)
+				require.Equal(t, expected.DuplicateFields, routes[url].DuplicateFields, url)
+				require.Equal(t, expected.Preload, routes[url].Preload, url)
+			}
+			if expected, ok := test.Extra["output"].(string); ok {
+				require.Equal(t, expected, report.String())
+			}
+		},
+
+		Tests: []tests.Test[reactConfig.PluginConfig]{
+			{
+				Name: "component query waits for the page query",
+				Pass: true,
+				Input: []string{
+					`query UserPage($id: ID!) { user(id: $id) { name } }`,
+					`query UserDetails($id: ID!) { user(id: $id) { avatar } }`,
+					`query UserFeed($limit: Int!) { feed(first: $limit) { id } }`,
+					`query UserDraft { viewer { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+					"src/routes/users/[id]/Details.gql",
+					"src/routes/users/[id]/Feed.gql",
+					// the page doesn't import it so it's never fetched
+					"src/routes/users/[id]/Draft.gql",
+				},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/users/[id]/+page.tsx": "import Details from './Details'\n" +
+							"import { Feed } from './feed/index'\n" +
+							mockView([]string{"UserPage"}),
+						"src/routes/users/[id]/feed/index.ts": "export { default as Feed } from '../Feed.gql'\n",
+					},
+					"expected": map[string]plugin.RoutePrefetch{
+						"/users/[id]": {
+							Waterfalls: []plugin.Waterfall{
+								{
+									Query:    "UserDetails",
+									Filepath: "src/routes/users/[id]/Details.gql",
+									Reason:   plugin.WaterfallReasonComponent,
+									After:    []string{"UserPage"},
+								},
+								{
+									Query:    "UserFeed",
+									Filepath: "src/routes/users/[id]/Feed.gql",
+									Reason:   plugin.WaterfallReasonComponent,
+									After:    []string{"UserPage"},
+								},
+							},
+							DuplicateFields: []plugin.DuplicateField{},
+							// UserFeed needs a value for $limit that the route doesn't have
+							Preload: []plugin.PreloadQuery{
+								{Name: "UserPage", Source: plugin.PreloadSourcePage},
+								{Name: "UserDetails", Source: plugin.PreloadSourceComponent},
+							},
+						},
+					},
+					"output": tests.Dedent(`
+						/users/[id]
+						  waterfall: UserDetails (src/routes/users/[id]/Details.gql) waits for UserPage
+						  waterfall: UserFeed (src/routes/users/[id]/Feed.gql) waits for UserPage
+					`),
+				},
+			},
+			{
+				Name: "@loading lets components fetch right away",
+				Pass: true,
+				Input: []string{
+					`query UserPage($id: ID!) @loading { user(id: $id) { name } }`,
+					`query UserDetails($id: ID!) { user(id: $id) { avatar } }`,
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+					"src/routes/users/[id]/Details.gql",
+				},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/users/[id]/+page.tsx": "import Details from './Details'\n" +
+							mockView([]string{"UserPage"}),
+					},
+					"expected": map[string]plugin.RoutePrefetch{
+						"/users/[id]": {
+							Waterfalls:      []plugin.Waterfall{},
+							DuplicateFields: []plugin.DuplicateField{},
+							Preload: []plugin.PreloadQuery{
+								{Name: "UserPage", Source: plugin.PreloadSourcePage},
+							},
+						},
+					},
+					"output": "No waterfalls or duplicate fields found.",
+				},
+			},
+			{
+				Name: "layout and page fetch the same field",
+				Pass: true,
+				Input: []string{
+					`query RootLayout @loading { viewer { id name } }`,
+					`query Settings @loading { viewer { avatar } feed(first: 10) { id } }`,
+					`query Feed @loading { feed(first: 20) { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/+layout.gql",
+					"src/routes/settings/+page.gql",
+					"src/routes/feed/+page.gql",
+				},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/+layout.tsx":        mockView([]string{"RootLayout"}),
+						"src/routes/settings/+page.tsx": mockView([]string{"Settings"}),
+						"src/routes/feed/+page.tsx":     mockView([]string{"Feed"}),
+					},
+					"expected": map[string]plugin.RoutePrefetch{
+						"/settings": {
+							Waterfalls: []plugin.Waterfall{},
+							DuplicateFields: []plugin.DuplicateField{
+								{Field: "viewer", Queries: []string{"RootLayout", "Settings"}},
+							},
+							Preload: []plugin.PreloadQuery{
+								{Name: "RootLayout", Source: plugin.PreloadSourceLayout},
+								{Name: "Settings", Source: plugin.PreloadSourcePage},
+							},
+						},
+						// different arguments aren't the same field
+						"/feed": {
+							Waterfalls:      []plugin.Waterfall{},
+							DuplicateFields: []plugin.DuplicateField{},
+							Preload: []plugin.PreloadQuery{
+								{Name: "RootLayout", Source: plugin.PreloadSourceLayout},
+								{Name: "Feed", Source: plugin.PreloadSourcePage},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "arguments are compared by the values the router sends",
+				Pass: true,
+				Input: []string{
+					`query UserLayout($id: ID!) @loading { user(id: $id) { name } feed(first: 10) { id } }`,
+					`query UserPage($id: ID!) @loading { user(id: $id) { avatar } }`,
+					`query UserPosts($first: Int! = 10) @loading { user(id: "1") { id } feed(first: $first) { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+layout.gql",
+					"src/routes/users/[id]/+page.gql",
+					"src/routes/users/[id]/posts/+page.gql",
+				},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/users/[id]/+layout.tsx":     mockView([]string{"UserLayout"}),
+						"src/routes/users/[id]/+page.tsx":       mockView([]string{"UserPage"}),
+						"src/routes/users/[id]/posts/+page.tsx": mockView([]string{"UserPosts"}),
+					},
+					"expected": map[string]plugin.RoutePrefetch{
+						// both queries get $id from the url
+						"/users/[id]": {
+							Waterfalls: []plugin.Waterfall{},
+							DuplicateFields: []plugin.DuplicateField{
+								{Field: "user(id: $id)", Queries: []string{"UserLayout", "UserPage"}},
+							},
+							Preload: []plugin.PreloadQuery{
+								{Name: "UserLayout", Source: plugin.PreloadSourceLayout},
+								{Name: "UserPage", Source: plugin.PreloadSourcePage},
+							},
+						},
+						// $first always has its default value but the user is a different one
+						"/users/[id]/posts": {
+							Waterfalls: []plugin.Waterfall{},
+							DuplicateFields: []plugin.DuplicateField{
+								{Field: "feed(first: 10)", Queries: []string{"UserLayout", "UserPosts"}},
+							},
+							Preload: []plugin.PreloadQuery{
+								{Name: "UserLayout", Source: plugin.PreloadSourceLayout},
+								{Name: "UserPosts", Source: plugin.PreloadSourcePage},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "component field with its own query",
+				Pass: true,
+				Input: []string{
+					`query UserPage($id: ID!) @loading { user(id: $id) { Avatar } }`,
+					tests.Dedent(`
+						fragment UserAvatar on User @componentField(field: "Avatar", prop: "user") { avatar }
+						query AvatarSettings { viewer { id } }
+					`),
+				},
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+					"src/components/Avatar.gql",
+				},
+				Extra: map[string]any{
+					"views": map[string]string{
+						"src/routes/users/[id]/+page.tsx": mockView([]string{"UserPage"}),
+					},
+					"expected": map[string]plugin.RoutePrefetch{
+						"/users/[id]": {
+							Waterfalls: []plugin.Waterfall{
+								{
+									Query:    "AvatarSettings",
+									Filepath: "src/components/Avatar.gql",
+									Reason:   plugin.WaterfallReasonComponentField,
+									After:    []string{"UserPage"},
+								},
+							},
+							DuplicateFields: []plugin.DuplicateField{},
+							Preload: []plugin.PreloadQuery{
+								{Name: "UserPage", Source: plugin.PreloadSourcePage},
+								{Name: "AvatarSettings", Source: plugin.PreloadSourceComponent},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
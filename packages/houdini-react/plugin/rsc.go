@@ -0,0 +1,373 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// RenderMode picks how pages render on the server
+type RenderMode = string
+
+const (
+	// RenderModeClient streams the client router and its page entries (the default)
+	RenderModeClient RenderMode = "client"
+	// RenderModeRSC renders server-component units that run their queries on the server
+	RenderModeRSC RenderMode = "rsc"
+)
+
+// loadRenderMode reads the render mode from the plugin config, defaulting to client rendering
+func (p *HoudiniReact) loadRenderMode(ctx context.Context) (RenderMode, error) {
+	config, err := p.DB.PluginConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not parse the houdini-react config: %w", err)
+	}
+
+	switch config.RenderMode {
+	case "":
+		return RenderModeClient, nil
+	case RenderModeClient, RenderModeRSC:
+		return config.RenderMode, nil
+	}
+	return "", fmt.Errorf(
+		"unknown houdini-react renderMode %q. use %q or %q",
+		config.RenderMode, RenderModeClient, RenderModeRSC,
+	)
+}
+
+// ClientBoundary is a component that has to render on the client in rsc mode because one of
+// its documents is only usable through a hook: mutations, subscriptions, and paginated or
+// refetchable fragments and queries.
+type ClientBoundary struct {
+	// Filepath is the component's file, relative to the project root
+	Filepath string `json:"filepath"`
+	// Documents says which documents make it a boundary, as "<kind> <name>"
+	Documents []string `json:"documents"`
+}
+
+// LoadClientBoundaries returns every component that needs a client boundary, sorted by path.
+// Documents in .gql files are left out: they aren't components, and route queries are
+// checked by Validate instead.
+func (p *HoudiniReact) LoadClientBoundaries(ctx context.Context) ([]ClientBoundary, error) {
+	boundaries := []ClientBoundary{}
+	err := p.DB.StepQuery(ctx, `
+		SELECT rd.filepath, d.kind, d.name,
+			EXISTS (
+				SELECT 1 FROM discovered_lists dl
+				WHERE dl.document = d.id AND dl.paginate IS NOT NULL
+			),
+			EXISTS (
+				SELECT 1 FROM document_directives dd
+				WHERE dd.document = d.id AND dd.directive = $refetchable_directive
+			)
+		FROM documents d
+			JOIN raw_documents rd ON rd.id = d.raw_document
+		WHERE d.visible = 1
+		ORDER BY rd.filepath, d.name
+	`, map[string]any{"refetchable_directive": graphql.RefetchableDirective}, func(row plugins.Row) {
+		fp := row.ColumnText(0)
+		if strings.HasSuffix(fp, ".gql") || strings.HasSuffix(fp, ".graphql") {
+			return
+		}
+
+		kind, name := row.ColumnText(1), row.ColumnText(2)
+		reason := ""
+		switch {
+		case kind == "mutation" || kind == "subscription":
+			reason = kind + " " + name
+		case row.ColumnBool(3):
+			reason = "paginated " + kind + " " + name
+		case row.ColumnBool(4):
+			reason = "refetchable " + kind + " " + name
+		default:
+			return
+		}
+
+		if len(boundaries) == 0 || boundaries[len(boundaries)-1].Filepath != fp {
+			boundaries = append(boundaries, ClientBoundary{Filepath: fp})
+		}
+		last := &boundaries[len(boundaries)-1]
+		last.Documents = append(last.Documents, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return boundaries, nil
+}
+
+// validateRenderMode checks the plugin config and, in rsc mode, that no route query paginates.
+// Route queries run inside server units, which hand their views plain data and no handle to
+// load more with.
+func (p *HoudiniReact) validateRenderMode(ctx context.Context, errs *plugins.ErrorList) {
+	mode, err := p.loadRenderMode(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	if mode != RenderModeRSC {
+		return
+	}
+
+	err = p.DB.StepQuery(ctx, `
+		SELECT DISTINCT d.name, rd.filepath, rd.offset_line, rd.offset_column
+		FROM documents d
+			JOIN raw_documents rd ON rd.id = d.raw_document
+			JOIN discovered_lists dl ON dl.document = d.id
+		WHERE d.kind = 'query'
+			AND dl.paginate IS NOT NULL
+			AND (rd.filepath LIKE '%+page.gql' OR rd.filepath LIKE '%+layout.gql')
+		ORDER BY d.name
+	`, nil, func(row plugins.Row) {
+		errs.Append(&plugins.Error{
+			Message: fmt.Sprintf("route query %q can't use @paginate in rsc mode", row.ColumnText(0)),
+			Detail:  "Route queries run on the server, so their views get plain data without a handle to load more. Move the paginated list into a fragment that a client component reads with useFragmentHandle.",
+			Kind:    plugins.ErrorKindValidation,
+			Locations: []*plugins.ErrorLocation{
+				{
+					Filepath: row.ColumnText(1),
+					Line:     row.ColumnInt(2),
+					Column:   row.ColumnInt(3),
+				},
+			},
+		})
+	})
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+	}
+}
+
+// GenerateServerComponents generates the units rendered in rsc mode: a server unit for every
+// page and layout that runs its queries on the server and passes the data to the view as
+// props, an entry per page that nests the units, the render entry that picks the entry for
+// a request, and the list of client boundaries the vite plugin marks with 'use client'. In
+// client mode it removes anything a previous rsc build left behind, and in rsc mode the units
+// of pages and layouts that no longer exist.
+func (p *HoudiniReact) GenerateServerComponents(ctx context.Context) ([]string, error) {
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := p.loadRenderMode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginDir := projectConfig.PluginDirectory(p.Name())
+	renderPath := filepath.Join(renderDir(pluginDir), "rsc.js")
+	if mode != RenderModeRSC {
+		if err := p.Filesystem().RemoveAll(serverUnitsDir(pluginDir)); err != nil {
+			return nil, err
+		}
+		if err := p.Filesystem().Remove(renderPath); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	manifest, err := p.LoadManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	boundaries, err := p.LoadClientBoundaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+
+	for id, page := range manifest.Pages {
+		dir := filepath.Join(serverUnitsDir(pluginDir), "pages")
+		compAbs := stripViewExt(filepath.Join(projectConfig.ProjectRoot, page.Path))
+		compRel := toSlash(mustRel(dir, compAbs))
+		files[filepath.Join(dir, id+".jsx")] = generateServerUnitFile("Component_"+id, compRel, page.Queries)
+		files[filepath.Join(serverUnitsDir(pluginDir), "entries", id+".jsx")] = generateServerEntry(id, page)
+	}
+	for id, layout := range manifest.Layouts {
+		dir := filepath.Join(serverUnitsDir(pluginDir), "layouts")
+		compAbs := stripViewExt(filepath.Join(projectConfig.ProjectRoot, layout.Path))
+		compRel := toSlash(mustRel(dir, compAbs))
+		files[filepath.Join(dir, id+".jsx")] = generateServerUnitFile("Component_"+id, compRel, layout.QueryOptions)
+	}
+
+	marshaled, err := json.MarshalIndent(boundaries, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join(serverUnitsDir(pluginDir), "clientBoundaries.json")] = string(marshaled) + "\n"
+
+	rootRel := toSlash(mustRel(renderDir(pluginDir), projectConfig.ProjectRoot))
+	files[renderPath] = generateRSCRender(manifest, rootRel)
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var changed []string
+	for _, path := range paths {
+		if ok, err := writeIfChanged(p.Filesystem(), path, files[path]); err != nil {
+			return nil, err
+		} else if ok {
+			changed = append(changed, path)
+		}
+	}
+
+	// the units of a page or layout that was deleted would still import its view
+	removed, err := removeStaleFiles(p.Filesystem(), serverUnitsDir(pluginDir), func(path string) bool {
+		_, ok := files[path]
+		return !ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, removed...)
+
+	return changed, nil
+}
+
+// generateServerUnitFile builds the source of a server unit: an async component that loads
+// its queries during the server render and hands the view their data as props.
+func generateServerUnitFile(componentName, importPath string, queries []string) string {
+	var b strings.Builder
+
+	if len(queries) > 0 {
+		b.WriteString("import { loadServerQuery } from '$houdini/plugins/houdini-react/runtime/server'\n")
+		for _, q := range queries {
+			b.WriteString(fmt.Sprintf("import %s_artifact from '$houdini/artifacts/%s'\n", q, q))
+		}
+	}
+	b.WriteString(fmt.Sprintf("import %s from '%s'\n\n", componentName, importPath))
+
+	if len(queries) == 0 {
+		b.WriteString("export default ({ children }) => {\n")
+	} else {
+		b.WriteString("export default async ({ client, variables, session, children }) => {\n")
+	}
+
+	// every query in the unit is sent at once
+	load := func(q string) string {
+		return fmt.Sprintf("loadServerQuery({ client, artifact: %s_artifact, variables, session })", q)
+	}
+	switch len(queries) {
+	case 0:
+	case 1:
+		b.WriteString(fmt.Sprintf("\tconst %s = await %s\n\n", queries[0], load(queries[0])))
+	default:
+		b.WriteString(fmt.Sprintf("\tconst [%s] = await Promise.all([\n", strings.Join(queries, ", ")))
+		for _, q := range queries {
+			b.WriteString(fmt.Sprintf("\t\t%s,\n", load(q)))
+		}
+		b.WriteString("\t])\n\n")
+	}
+
+	var props []string
+	for _, q := range queries {
+		props = append(props, fmt.Sprintf("%s={%s}", q, q))
+	}
+	propsStr := ""
+	if len(props) > 0 {
+		propsStr = " " + strings.Join(props, " ")
+	}
+
+	b.WriteString("\treturn (\n")
+	b.WriteString(fmt.Sprintf("\t\t<%s%s>\n", componentName, propsStr))
+	b.WriteString("\t\t\t{children}\n")
+	b.WriteString(fmt.Sprintf("\t\t</%s>\n", componentName))
+	b.WriteString("\t)\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// generateServerEntry nests a page's server unit inside its layouts. Every unit gets the
+// same props (the client, the request's variables and the session) and sends only the
+// queries it declares.
+func generateServerEntry(id string, page PageManifest) string {
+	var b strings.Builder
+	for _, layoutID := range page.Layouts {
+		b.WriteString(fmt.Sprintf("import Layout_%s from '../layouts/%s.jsx'\n", layoutID, layoutID))
+	}
+	b.WriteString(fmt.Sprintf("import Page_%s from '../pages/%s.jsx'\n\n", id, id))
+
+	b.WriteString("export default (props) => (\n")
+	depth := 1
+	for _, layoutID := range page.Layouts {
+		b.WriteString(fmt.Sprintf("%s<Layout_%s {...props}>\n", strings.Repeat("\t", depth), layoutID))
+		depth++
+	}
+	b.WriteString(fmt.Sprintf("%s<Page_%s {...props} />\n", strings.Repeat("\t", depth), id))
+	for i := len(page.Layouts) - 1; i >= 0; i-- {
+		depth--
+		b.WriteString(fmt.Sprintf("%s</Layout_%s>\n", strings.Repeat("\t", depth), page.Layouts[i]))
+	}
+	b.WriteString(")\n")
+
+	return b.String()
+}
+
+// generateRSCRender builds the render entry for rsc mode. It matches the request against the
+// router manifest the same way the client router does, and builds the tree of server units
+// for the bundler's RSC renderer to serialize.
+func generateRSCRender(manifest ProjectManifest, rootRel string) string {
+	ids := make([]string, 0, len(manifest.Pages))
+	for id := range manifest.Pages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var entries strings.Builder
+	for _, id := range ids {
+		entries.WriteString(fmt.Sprintf("\t'%s': () => import('../server/entries/%s.jsx'),\n", id, id))
+	}
+
+	return fmt.Sprintf(`import { find_match } from 'houdini/router/match'
+import { getCurrentConfig } from '$houdini/runtime'
+import React from 'react'
+
+import { scalarUnmarshalers, unmarshalScalars } from '../../runtime/resolve-href'
+import router_manifest from '$houdini/plugins/houdini-react/runtime/manifest'
+// @ts-expect-error
+import client from '%s/src/+client'
+
+import Shell from '%s/src/+index'
+
+// the server entry of every page, by id
+const entries = {
+%s}
+
+// render_rsc builds the server component tree for a request. Hand the element to the RSC
+// renderer of your bundler (for example renderToReadableStream from react-server-dom-webpack)
+// to serialize it for the client. A url that doesn't match a page renders nothing.
+export async function render_rsc({ url, host, session }) {
+	const [page, rawVariables] = find_match(router_manifest, url, true, host)
+	if (!page || !entries[page.id]) {
+		return { status: 404, element: null }
+	}
+
+	// custom-scalar params arrive in their url form, just like they do in the client router
+	const unmarshalers = scalarUnmarshalers(
+		[...(page.params ?? []), ...(page.searchParams ?? [])],
+		getCurrentConfig()?.scalars
+	)
+	const variables = unmarshalScalars(rawVariables ?? {}, unmarshalers)
+
+	const { default: Entry } = await entries[page.id]()
+	return {
+		status: 200,
+		element: React.createElement(
+			Shell,
+			null,
+			React.createElement(Entry, { client, variables, session })
+		),
+	}
+}
+`, rootRel, rootRel, entries.String())
+}
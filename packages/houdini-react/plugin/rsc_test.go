@@ -0,0 +1,280 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestServerComponents(t *testing.T) {
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
+		Schema: `
+			type Query {
+				viewer: User
+				user(id: ID!): User
+			}
+			type Mutation {
+				follow(id: ID!): User
+			}
+			interface Node { id: ID! }
+			type User implements Node {
+				id: ID!
+				name: String!
+				friends(first: Int, after: String): UserConnection!
+			}
+			type UserConnection {
+				edges: [UserEdge!]!
+				pageInfo: PageInfo!
+			}
+			type UserEdge {
+				cursor: String
+				node: User
+			}
+			type PageInfo {
+				hasNextPage: Boolean!
+				hasPreviousPage: Boolean!
+				startCursor: String
+				endCursor: String
+			}
+		`,
+		SetupAlwaysPasses: true,
+
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			fs := p.Filesystem()
+			for fp, content := range test.Extra["views"].(map[string]string) {
+				abs := filepath.Join("/project", fp)
+				require.NoError(t, fs.MkdirAll(filepath.Dir(abs), 0755))
+				require.NoError(t, afero.WriteFile(fs, abs, []byte(content), 0644))
+			}
+
+			conn, err := p.DB.Take(context.Background())
+			require.NoError(t, err)
+			defer p.DB.Put(conn)
+			stmt, err := conn.Prepare(`
+				INSERT INTO plugins (name, port, hooks, plugin_order, config)
+				VALUES ($name, 0, '[]', 'core', $config)
+			`)
+			require.NoError(t, err)
+			defer stmt.Finalize()
+			require.NoError(t, p.DB.ExecStatement(stmt, map[string]any{
+				"name":   p.Name(),
+				"config": test.Extra["config"],
+			}))
+		},
+
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			ctx := context.Background()
+
+			if expected, ok := test.Extra["validate"].(string); ok {
+				err := p.Validate(ctx)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), expected)
+				return
+			}
+
+			_, err := p.GenerateServerComponents(ctx)
+			if !test.Pass {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			units := pluginUnitsDir(p)
+
+			// delete some views and generate again
+			if deleted, ok := test.Extra["deleted"].([]string); ok {
+				for _, view := range deleted {
+					require.NoError(t, p.Filesystem().Remove(filepath.Join("/project", view)))
+				}
+				changed, err := p.GenerateServerComponents(ctx)
+				require.NoError(t, err)
+				for _, file := range test.Extra["removed"].([]string) {
+					require.Contains(t, changed, filepath.Join(units, file))
+					exists, err := afero.Exists(p.Filesystem(), filepath.Join(units, file))
+					require.NoError(t, err)
+					require.False(t, exists, file)
+				}
+			}
+
+			expected, ok := test.Extra["expected"].(map[string]string)
+			if !ok {
+				for _, file := range []string{"server", "render/rsc.js"} {
+					exists, err := afero.Exists(p.Filesystem(), filepath.Join(units, file))
+					require.NoError(t, err)
+					require.False(t, exists, file)
+				}
+				return
+			}
+			for file, content := range expected {
+				got, err := afero.ReadFile(p.Filesystem(), filepath.Join(units, file))
+				require.NoError(t, err)
+				require.Equal(t, content, string(got), "file: %s", file)
+			}
+		},
+
+		Tests: []tests.Test[reactConfig.PluginConfig]{
+			{
+				Name: "rsc mode",
+				Pass: true,
+				Input: []string{
+					`query RootLayout { viewer { id } }`,
+					`query UserInfo($id: ID!) { user(id: $id) { id ...UserCard } }`,
+					"const UserCard = graphql(`fragment UserCard on User { name }`)",
+					"const UserFriends = graphql(`fragment UserFriends on User { friends(first: 10) @paginate { edges { node { id } } } }`)",
+					"const Follow = graphql(`mutation Follow($id: ID!) { follow(id: $id) { id } }`)",
+				},
+				Filepaths: []string{
+					"src/routes/+layout.gql",
+					"src/routes/users/[id]/+page.gql",
+					"src/components/UserCard.tsx",
+					"src/components/Friends.tsx",
+					"src/components/Follow.tsx",
+				},
+				Extra: map[string]any{
+					"config": `{"renderMode": "rsc"}`,
+					"views": map[string]string{
+						"src/routes/+layout.tsx":          mockView([]string{"RootLayout"}),
+						"src/routes/+page.tsx":            mockView([]string{}),
+						"src/routes/users/[id]/+page.tsx": mockView([]string{"UserInfo"}),
+					},
+					// the page unit sends its own query along with the layout's. loadServerQuery
+					// shares the send with the layout unit for the rest of the request
+					"expected": map[string]string{
+						"server/pages/_users__id_.jsx": `import { loadServerQuery } from '$houdini/plugins/houdini-react/runtime/server'
+import RootLayout_artifact from '$houdini/artifacts/RootLayout'
+import UserInfo_artifact from '$houdini/artifacts/UserInfo'
+import Component__users__id_ from '../../../../../../src/routes/users/[id]/+page'
+
+export default async ({ client, variables, session, children }) => {
+	const [RootLayout, UserInfo] = await Promise.all([
+		loadServerQuery({ client, artifact: RootLayout_artifact, variables, session }),
+		loadServerQuery({ client, artifact: UserInfo_artifact, variables, session }),
+	])
+
+	return (
+		<Component__users__id_ RootLayout={RootLayout} UserInfo={UserInfo}>
+			{children}
+		</Component__users__id_>
+	)
+}
+`,
+						"server/layouts/_.jsx": `import { loadServerQuery } from '$houdini/plugins/houdini-react/runtime/server'
+import RootLayout_artifact from '$houdini/artifacts/RootLayout'
+import Component__ from '../../../../../../src/routes/+layout'
+
+export default async ({ client, variables, session, children }) => {
+	const RootLayout = await loadServerQuery({ client, artifact: RootLayout_artifact, variables, session })
+
+	return (
+		<Component__ RootLayout={RootLayout}>
+			{children}
+		</Component__>
+	)
+}
+`,
+						"server/entries/_users__id_.jsx": `import Layout__ from '../layouts/_.jsx'
+import Page__users__id_ from '../pages/_users__id_.jsx'
+
+export default (props) => (
+	<Layout__ {...props}>
+		<Page__users__id_ {...props} />
+	</Layout__>
+)
+`,
+						// UserCard only reads data, so it stays a server component
+						"server/clientBoundaries.json": `[
+	{
+		"filepath": "src/components/Follow.tsx",
+		"documents": [
+			"mutation Follow"
+		]
+	},
+	{
+		"filepath": "src/components/Friends.tsx",
+		"documents": [
+			"paginated fragment UserFriends"
+		]
+	}
+]
+`,
+					},
+				},
+			},
+			{
+				Name: "units of deleted pages are removed",
+				Pass: true,
+				Input: []string{
+					`query RootLayout { viewer { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/+layout.gql",
+				},
+				Extra: map[string]any{
+					"config": `{"renderMode": "rsc"}`,
+					"views": map[string]string{
+						"src/routes/+layout.tsx":     mockView([]string{"RootLayout"}),
+						"src/routes/+page.tsx":       mockView([]string{}),
+						"src/routes/old/+page.tsx":   mockView([]string{}),
+						"src/routes/old/+layout.tsx": mockView([]string{}),
+					},
+					"deleted": []string{"src/routes/old/+page.tsx", "src/routes/old/+layout.tsx"},
+					"removed": []string{
+						"server/pages/_old.jsx",
+						"server/entries/_old.jsx",
+						"server/layouts/_old.jsx",
+					},
+					"expected": map[string]string{},
+				},
+			},
+			{
+				Name: "client mode doesn't generate server units",
+				Pass: true,
+				Input: []string{
+					`query RootLayout { viewer { id } }`,
+				},
+				Filepaths: []string{
+					"src/routes/+layout.gql",
+				},
+				Extra: map[string]any{
+					"config": `{}`,
+					"views": map[string]string{
+						"src/routes/+layout.tsx": mockView([]string{"RootLayout"}),
+						"src/routes/+page.tsx":   mockView([]string{}),
+					},
+				},
+			},
+			{
+				Name: "unknown render mode",
+				Pass: false,
+				Extra: map[string]any{
+					"config": `{"renderMode": "static"}`,
+					"views":  map[string]string{},
+				},
+			},
+			{
+				Name: "route queries can't paginate in rsc mode",
+				Pass: true,
+				Input: []string{
+					`query Friends { viewer { friends(first: 10) @paginate { edges { node { id } } } } }`,
+				},
+				Filepaths: []string{
+					"src/routes/+page.gql",
+				},
+				Extra: map[string]any{
+					"config": `{"renderMode": "rsc"}`,
+					"views": map[string]string{
+						"src/routes/+page.tsx": mockView([]string{"Friends"}),
+					},
+					"validate": `route query "Friends" can't use @paginate in rsc mode`,
+				},
+			},
+		},
+	})
+}
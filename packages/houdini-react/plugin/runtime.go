@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -166,7 +167,7 @@ func (p *HoudiniReact) UpdateIndexFiles(ctx context.Context) ([]string, error) {
 }
 
 // GenerateRuntime runs after the runtime files have been copied. It loads the project
-// manifest and writes manifest.ts into the plugin runtime directory.
+// manifest and writes manifest.ts and sessionType.ts into the plugin runtime directory.
 func (p *HoudiniReact) GenerateRuntime(ctx context.Context) ([]string, error) {
 	projectConfig, err := p.DB.ProjectConfig(ctx)
 	if err != nil {
@@ -237,12 +238,24 @@ func (p *HoudiniReact) GenerateRuntime(ctx context.Context) ([]string, error) {
 	}
 	changed = append(changed, renderFiles...)
 
+	serverComponents, err := p.GenerateServerComponents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, serverComponents...)
+
 	typeRoots, err := p.GenerateTypeRoots(ctx)
 	if err != nil {
 		return nil, err
 	}
 	changed = append(changed, typeRoots...)
 
+	openAPI, err := p.GenerateOpenAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, openAPI...)
+
 	tsConfig, err := p.GenerateTsConfig(ctx)
 	if err != nil {
 		return nil, err
@@ -250,6 +263,18 @@ func (p *HoudiniReact) GenerateRuntime(ctx context.Context) ([]string, error) {
 	changed = append(changed, tsConfig...)
 
 	runtimeDir := projectConfig.PluginRuntimeDirectory(p.Name())
+
+	// the keys that @session mutations write to the session
+	sessionType, err := plugins.GenerateSessionType(
+		ctx,
+		p.DB,
+		p.Filesystem(),
+		filepath.Join(runtimeDir, "sessionType.ts"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, sessionType...)
 	artifactDir := filepath.Join(projectConfig.ProjectRoot, projectConfig.RuntimeDir, "artifacts")
 
 	content, err := formatManifest(manifest, runtimeDir, artifactDir, projectConfig.ProjectRoot, projectConfig.Scalars)
@@ -270,6 +295,20 @@ func (p *HoudiniReact) GenerateRuntime(ctx context.Context) ([]string, error) {
 		changed = append(changed, manifestPath)
 	}
 
+	// a report of how every route fills its query variables
+	reportContent, err := formatVariableReport(manifest)
+	if err != nil {
+		return nil, err
+	}
+	reportPath := filepath.Join(projectConfig.PluginDirectory(p.Name()), "route-variables.json")
+	existingReport, _ := afero.ReadFile(p.Filesystem(), reportPath)
+	if string(existingReport) != reportContent {
+		if err := plugins.WriteFile(p.Filesystem(), reportPath, []byte(reportContent), 0644); err != nil {
+			return nil, err
+		}
+		changed = append(changed, reportPath)
+	}
+
 	mockContent, err := formatMockFile(manifest)
 	if err != nil {
 		return nil, err
@@ -287,6 +326,37 @@ func (p *HoudiniReact) GenerateRuntime(ctx context.Context) ([]string, error) {
 	return changed, nil
 }
 
+// formatVariableReport describes how the router provides the variables of every query a
+// page depends on, keyed by the page's URL and then the query's name.
+func formatVariableReport(manifest ProjectManifest) (string, error) {
+	queries := map[string]QueryManifest{}
+	for _, query := range manifest.LayoutQueries {
+		queries[query.Name] = query
+	}
+	for _, query := range manifest.PageQueries {
+		queries[query.Name] = query
+	}
+
+	report := map[string]map[string]map[string]VariableSource{}
+	for _, page := range manifest.Pages {
+		routeReport := map[string]map[string]VariableSource{}
+		for _, name := range page.Queries {
+			sources := queries[name].Sources
+			if sources == nil {
+				sources = map[string]VariableSource{}
+			}
+			routeReport[name] = sources
+		}
+		report[page.URL] = routeReport
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n", nil
+}
+
 // formatMockFile generates the typed createMock function for all routes.
 //
 // Uses a single generic function with precomputed route param types so TypeScript
@@ -767,7 +837,8 @@ func formatManifest(
 	// elimination strip them from the client build.
 	headerLoadersByID := map[string][]string{}
 
-	for _, id := range sortedKeys(manifest.Pages) {
+	// the router serves a url with the first page that matches it
+	for _, id := range manifest.routerOrder() {
 		page := manifest.Pages[id]
 
 		cleanURL := stripRouteGroups(page.URL)
@@ -791,6 +862,9 @@ func formatManifest(
 		sb.WriteString(fmt.Sprintf("\t\t\tpattern: %s,\n", pattern))
 		sb.WriteString(fmt.Sprintf("\t\t\tparams: %s,\n", formatParams(params, page.Params)))
 		sb.WriteString(fmt.Sprintf("\t\t\tsearchParams: %s,\n", formatSearchParams(page.SearchParams)))
+		if page.Locale != "" {
+			sb.WriteString(fmt.Sprintf("\t\t\tlocale: %q,\n", page.Locale))
+		}
 
 		// Documents block.
 		sb.WriteString("\t\t\tdocuments: {\n")
@@ -859,6 +933,26 @@ func formatManifest(
 	}
 	sb.WriteString("\t},\n")
 
+	// i18n tells the router which variable receives the locale and, for domain routing,
+	// which locale each host serves
+	if i18n := manifest.I18n; i18n != nil {
+		sb.WriteString("\ti18n: {\n")
+		sb.WriteString(fmt.Sprintf("\t\tstrategy: %q,\n", i18n.Strategy))
+		sb.WriteString(fmt.Sprintf("\t\tvariable: %q,\n", i18n.Variable))
+		sb.WriteString(fmt.Sprintf("\t\tdefaultLocale: %q,\n", i18n.DefaultLocale))
+		quoted := make([]string, len(i18n.Locales))
+		for i, locale := range i18n.Locales {
+			quoted[i] = fmt.Sprintf("%q", locale)
+		}
+		sb.WriteString(fmt.Sprintf("\t\tlocales: [%s],\n", strings.Join(quoted, ", ")))
+		sb.WriteString("\t\tdomains: {\n")
+		for _, locale := range sortedKeys(i18n.Domains) {
+			sb.WriteString(fmt.Sprintf("\t\t\t%q: %q,\n", locale, i18n.Domains[locale]))
+		}
+		sb.WriteString("\t\t},\n")
+		sb.WriteString("\t},\n")
+	}
+
 	sb.WriteString("} as const satisfies RouterManifest<any>\n")
 
 	// route_headers is a server-only export: it maps a page id to the ordered
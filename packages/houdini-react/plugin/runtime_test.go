@@ -9,16 +9,16 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 
-	coreConfig "code.houdinigraphql.com/packages/houdini-core/config"
 	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
 	plugins "code.houdinigraphql.com/plugins"
 	"code.houdinigraphql.com/plugins/tests"
 )
 
 func TestTransformRuntime(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `type Query { id: ID }`,
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 
 			original, _ := test.Extra["input"].(string)
@@ -27,7 +27,7 @@ func TestTransformRuntime(t *testing.T) {
 
 			require.Equal(t, test.Extra["expected"].(string), got)
 		},
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "client.ts",
 				Pass: true,
@@ -55,10 +55,10 @@ func TestTransformRuntime(t *testing.T) {
 // barrel (index.tsx) only when a redirect-login integration is configured (router_config.redirect,
 // derived from src/server/+config auth.redirect.url). Without it the helper stays out of $houdini.
 func TestTransformRuntimeLoginURL(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `type Query { id: ID }`,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			redirect, _ := test.Extra["redirect"].(string)
 			providers, _ := test.Extra["providers"].(string)
 			if redirect == "" && providers == "" {
@@ -80,13 +80,13 @@ func TestTransformRuntimeLoginURL(t *testing.T) {
 			stmt.Finalize()
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			got, err := p.TransformRuntime(context.Background(), "index.tsx", test.Extra["input"].(string))
 			require.NoError(t, err)
 			require.Equal(t, test.Extra["expected"].(string), got)
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "re-exports loginURL when a redirect integration is configured",
 				Pass: true,
@@ -131,10 +131,10 @@ const indexStub = "import type { Cache } from 'houdini/runtime/cache'\n\n" +
 	"export function graphql(str: string): never { throw new Error() }\n"
 
 func TestUpdateIndexFiles(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `type Query { id: ID }`,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			if test.Extra["no_stub"] == true {
 				return
 			}
@@ -145,7 +145,7 @@ func TestUpdateIndexFiles(t *testing.T) {
 			require.NoError(t, afero.WriteFile(p.Filesystem(), path, []byte(indexStub), 0644))
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			cfg, err := p.DB.ProjectConfig(ctx)
 			require.NoError(t, err)
@@ -169,7 +169,7 @@ func TestUpdateIndexFiles(t *testing.T) {
 			require.Equal(t, expected, string(got))
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "injects artifact overloads into runtime index",
 				Pass: true,
@@ -229,7 +229,7 @@ func TestUpdateIndexFiles(t *testing.T) {
 }
 
 func TestUpdateHookFiles(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				id: ID
@@ -260,7 +260,7 @@ func TestUpdateHookFiles(t *testing.T) {
 			}
 		`,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			cfg, err := p.DB.ProjectConfig(context.Background())
 			require.NoError(t, err)
 			hooksDir := filepath.Join(cfg.PluginRuntimeDirectory(p.Name()), "hooks")
@@ -276,7 +276,7 @@ func TestUpdateHookFiles(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			cfg, err := p.DB.ProjectConfig(ctx)
 			require.NoError(t, err)
@@ -297,7 +297,7 @@ func TestUpdateHookFiles(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "injects query overloads",
 				Pass: true,
@@ -459,10 +459,10 @@ func TestUpdateHookFiles(t *testing.T) {
 }
 
 func TestAddGraphQLType(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `type Query { id: ID }`,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			if rows, ok := test.Extra["component_fields"].([]map[string]any); ok {
 				insertComponentFields(t, p, rows)
 			}
@@ -475,7 +475,7 @@ func TestAddGraphQLType(t *testing.T) {
 			require.NoError(t, afero.WriteFile(p.Filesystem(), path, []byte(indexStub), 0644))
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			cfg, err := p.DB.ProjectConfig(ctx)
 			require.NoError(t, err)
@@ -497,7 +497,7 @@ func TestAddGraphQLType(t *testing.T) {
 			require.Equal(t, expected, string(got))
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "appends GraphQL type for each component field fragment",
 				Pass: true,
@@ -547,11 +547,11 @@ func TestAddGraphQLType(t *testing.T) {
 }
 
 func TestInjectComponentFieldArtifactTypes(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema:            `type Query { id: ID }`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			if rows, ok := test.Extra["component_fields"].([]map[string]any); ok {
 				insertComponentFields(t, p, rows)
 			}
@@ -568,7 +568,7 @@ func TestInjectComponentFieldArtifactTypes(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 			cfg, err := p.DB.ProjectConfig(ctx)
 			require.NoError(t, err)
@@ -589,7 +589,7 @@ func TestInjectComponentFieldArtifactTypes(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "injects Omit<ComponentPropsWithoutRef, injected-prop> accessor into fragment artifact",
 				Pass: true,
@@ -693,7 +693,7 @@ const tsTypeManifest = "\nexport type _TSType<T extends string> = T extends keyo
 	"\t\t\t\t: string\n"
 
 func TestGenerateRuntime(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				id: ID
@@ -711,7 +711,7 @@ func TestGenerateRuntime(t *testing.T) {
 		`,
 		SetupAlwaysPasses: true,
 
-		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			cfg, err := p.DB.ProjectConfig(context.Background())
 			require.NoError(t, err)
 			runtimeDir := cfg.PluginRuntimeDirectory(p.Name())
@@ -737,7 +737,7 @@ func TestGenerateRuntime(t *testing.T) {
 			}
 		},
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			ctx := context.Background()
 
 			changed, err := p.GenerateRuntime(ctx)
@@ -748,6 +748,9 @@ func TestGenerateRuntime(t *testing.T) {
 
 			manifestPath := filepath.Join(config.PluginRuntimeDirectory(p.Name()), "manifest.ts")
 
+			// the Session type comes from the @session mutations, even when there aren't any
+			require.Contains(t, changed, filepath.Join(config.PluginRuntimeDirectory(p.Name()), "sessionType.ts"))
+
 			if expected, ok := test.Extra["expected"].(string); ok {
 				require.Contains(t, changed, manifestPath)
 				got, err := afero.ReadFile(p.Filesystem(), manifestPath)
@@ -764,6 +767,14 @@ func TestGenerateRuntime(t *testing.T) {
 				}
 			}
 
+			if expectedReport, ok := test.Extra["expectedVariableReport"].(string); ok {
+				reportPath := filepath.Join(config.PluginDirectory(p.Name()), "route-variables.json")
+				require.Contains(t, changed, reportPath)
+				got, err := afero.ReadFile(p.Filesystem(), reportPath)
+				require.NoError(t, err)
+				require.Equal(t, expectedReport+"\n", string(got))
+			}
+
 			mockPath := filepath.Join(config.PluginRuntimeDirectory(p.Name()), "mock.ts")
 
 			if expectedMock, ok := test.Extra["expectedMock"].(string); ok {
@@ -774,7 +785,7 @@ func TestGenerateRuntime(t *testing.T) {
 			}
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "@endpoint mutation generates a form_actions entry",
 				Pass: true,
@@ -902,6 +913,15 @@ func TestGenerateRuntime(t *testing.T) {
 					"views": map[string]string{
 						"src/routes/[id]/+page.tsx": mockView([]string{"MyQuery"}),
 					},
+					"expectedVariableReport": tests.Dedent(`
+						{
+						  "/[id]": {
+						    "MyQuery": {
+						      "id": "route"
+						    }
+						  }
+						}
+					`),
 					"expectedMock": "import React from 'react'\n" +
 						"import { _createMock, buildMockPath } from './testing'\n" +
 						"import type { RouteHrefs, ParamsForRoute, SearchForRoute } from './routes'\n" +
@@ -954,7 +974,7 @@ func TestGenerateRuntime(t *testing.T) {
 				Name: "nullable non-route variables become search params",
 				Pass: true,
 				Input: []string{
-					"query SearchQuery($q: String, $tags: [String!], $first: Int!) {\n\tsearch(q: $q, tags: $tags, first: $first) {\n\t\tid\n\t}\n}\n",
+					"query SearchQuery($q: String, $tags: [String!], $first: Int! = 10) {\n\tsearch(q: $q, tags: $tags, first: $first) {\n\t\tid\n\t}\n}\n",
 				},
 				Filepaths: []string{
 					"src/routes/search/+page.gql",
@@ -963,9 +983,21 @@ func TestGenerateRuntime(t *testing.T) {
 					"views": map[string]string{
 						"src/routes/search/+page.tsx": mockView([]string{"SearchQuery"}),
 					},
+					"expectedVariableReport": tests.Dedent(`
+						{
+						  "/search": {
+						    "SearchQuery": {
+						      "first": "default",
+						      "q": "search",
+						      "tags": "search"
+						    }
+						  }
+						}
+					`),
 					// $q and $tags are nullable, so they surface as search params (the list
 					// keeps its wrapper chain). $first is required, so it is omitted — a
-					// missing search param can never make the query fail.
+					// missing search param can never make the query fail. it has a default
+					// value since the router would have no way to provide it otherwise.
 					"expectedMock": "import React from 'react'\n" +
 						"import { _createMock, buildMockPath } from './testing'\n" +
 						"import type { RouteHrefs, ParamsForRoute, SearchForRoute } from './routes'\n" +
@@ -3,6 +3,10 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"code.houdinigraphql.com/plugins"
 )
@@ -16,62 +20,230 @@ import (
 // absent. A required variable that is neither a route segment nor defaulted can never
 // be satisfied by navigation, so the query would fail at request time. We catch that
 // here instead, mirroring the build-time guarantee users get for route params.
+//
+// Every +page.gql and +layout.gql is checked, whether or not a view sits next to it. The
+// sources come from resolveVariableSources so the errors match the ones the manifest reports.
 func (p *HoudiniReact) Validate(ctx context.Context) error {
 	errs := &plugins.ErrorList{}
 
-	// type_modifiers is stored inner→outer, so the outermost wrapper is the final
-	// character: a trailing "!" means the variable is non-null (required). We also
-	// skip variables with a default value since those are satisfiable without input.
-	query := `
-		SELECT
-			d.name,
-			rd.filepath,
-			rd.offset_line,
-			rd.offset_column,
-			dv.name
-		FROM documents d
-			JOIN raw_documents rd ON rd.id = d.raw_document
-			JOIN document_variables dv ON dv.document = d.id
-		WHERE d.kind = 'query'
-			AND (rd.filepath LIKE '%+page.gql' OR rd.filepath LIKE '%+layout.gql')
-			AND dv.type_modifiers LIKE '%!'
-			AND dv.default_value IS NULL
-		ORDER BY d.name, dv.name
-	`
-
-	err := p.DB.StepQuery(ctx, query, nil, func(row plugins.Row) {
-		docName := row.ColumnText(0)
-		filepath := row.ColumnText(1)
-		line := row.ColumnInt(2)
-		column := row.ColumnInt(3)
-		varName := row.ColumnText(4)
-
-		// satisfied by a route segment — nothing to flag
-		if routeParamSet(filepath)[varName] {
-			return
+	p.validateRouteVariables(ctx, errs)
+	p.validateRoutes(ctx, errs)
+	p.validateRenderMode(ctx, errs)
+
+	if errs.Len() > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateRouteVariables makes sure the router can provide every variable of the route queries
+func (p *HoudiniReact) validateRouteVariables(ctx context.Context, errs *plugins.ErrorList) {
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	routesDir := filepath.Join(projectConfig.ProjectRoot, "src", "routes")
+	pageDocs, layoutDocs, _, _, _, err := p.loadRouteDocuments(ctx, projectConfig.ProjectRoot, routesDir)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	i18n, err := p.loadI18nConfig(ctx)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+
+	for _, docs := range []map[string]routeDoc{layoutDocs, pageDocs} {
+		for _, dir := range sortedKeys(docs) {
+			resolveVariableSources(dirKeyToURL(dir), docs[dir], i18n.localeVariable(), errs)
 		}
+	}
+}
 
-		errs.Append(&plugins.Error{
-			Message: fmt.Sprintf(
-				"required variable $%s on %q can't be provided by the router: "+
-					"add a [%s] route segment, give it a default value, or make it nullable "+
-					"so it can be supplied via search params",
-				varName, docName, varName,
-			),
-			Kind: plugins.ErrorKindValidation,
-			Locations: []*plugins.ErrorLocation{{
-				Filepath: filepath,
-				Line:     line,
-				Column:   column,
-			}},
-		})
-	})
+// routeView is a page view along with the url it's served at
+type routeView struct {
+	id       string
+	url      string // without route groups
+	filepath string // relative to the project root
+	segments []string
+	// the locale of a locale variant, empty for the page itself
+	locale string
+}
+
+// validateRoutes looks for pages the router can't tell apart. Route groups don't show up in
+// the url so two groups can hold the same page, and the router serves a url with the first
+// page (see routerLess) whose pattern matches it so a dynamic segment can shadow a static page.
+// The locale variants are checked too since a page of the project can take their url.
+func (p *HoudiniReact) validateRoutes(ctx context.Context, errs *plugins.ErrorList) {
+	projectConfig, err := p.DB.ProjectConfig(ctx)
 	if err != nil {
 		errs.Append(plugins.WrapError(err))
+		return
 	}
+	routesDir := filepath.Join(projectConfig.ProjectRoot, "src", "routes")
 
-	if errs.Len() > 0 {
-		return errs
+	views, err := p.discoverViewFiles(ctx, routesDir)
+	if err != nil {
+		errs.Append(plugins.WrapError(err))
+		return
+	}
+	// a broken locale config is reported by the manifest
+	i18n, _ := p.loadI18nConfig(ctx)
+
+	pages := []routeView{}
+	for dir, info := range views {
+		if info.pageViewPath == "" {
+			continue
+		}
+		url := dirKeyToURL(dir)
+		rel, _ := filepath.Rel(projectConfig.ProjectRoot, info.pageViewPath)
+		pages = append(pages, routeView{
+			id:       pageID(url),
+			url:      stripRouteGroups(url),
+			filepath: rel,
+			segments: routeSegments(url),
+		})
+
+		// the same variants localizePages adds to the manifest
+		if i18n == nil || i18n.Strategy != LocaleStrategyPrefix {
+			continue
+		}
+		for _, locale := range i18n.Locales {
+			if locale == i18n.DefaultLocale {
+				continue
+			}
+			localized := "/" + locale
+			if url != "/" {
+				localized += url
+			}
+			pages = append(pages, routeView{
+				id:       pageID(localized),
+				url:      stripRouteGroups(localized),
+				filepath: rel,
+				segments: routeSegments(localized),
+				locale:   locale,
+			})
+		}
+	}
+	// the order the router tries the patterns in
+	sort.Slice(pages, func(i, j int) bool {
+		return routerLess(pages[i].id, pages[i].locale != "", pages[j].id, pages[j].locale != "")
+	})
+
+	// pages that end up at the same url
+	byURL := map[string][]routeView{}
+	for _, page := range pages {
+		byURL[page.url] = append(byURL[page.url], page)
+	}
+	for _, url := range sortedKeys(byURL) {
+		if len(byURL[url]) > 1 && !sameLocaleVariants(byURL[url]) {
+			errs.Append(routeConflictError(
+				fmt.Sprintf("more than one page is served at %s", url),
+				"Route groups are left out of the url so only one of these pages can be reached.",
+				byURL[url]...,
+			))
+		}
+	}
+
+	// dynamic segments at the same position that give the param a different name
+	reported := map[string]bool{}
+	for i, a := range pages {
+		for _, b := range pages[i+1:] {
+			// the variants of a locale repeat what the pages themselves report
+			if a.locale != "" && a.locale == b.locale {
+				continue
+			}
+			position, ok := conflictingParam(a.segments, b.segments)
+			if !ok {
+				continue
+			}
+			key := strings.Join(a.segments[:position+1], "/") + " " + strings.Join(b.segments[:position+1], "/")
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			errs.Append(routeConflictError(
+				fmt.Sprintf(
+					"%s and %s use different names for the same part of the url",
+					a.segments[position], b.segments[position],
+				),
+				fmt.Sprintf("Both match /%s so rename one of the params.", strings.Join(b.segments[:position+1], "/")),
+				a, b,
+			))
+		}
+	}
+
+	// dynamic pages that are tried before a static page they match
+	for i, dynamic := range pages {
+		if !strings.Contains(dynamic.url, "[") {
+			continue
+		}
+		pattern, _, err := parsePagePattern(dynamic.url)
+		if err != nil {
+			continue
+		}
+		re, err := regexp.Compile(strings.TrimSuffix(strings.TrimPrefix(pattern, "/"), "/"))
+		if err != nil {
+			continue
+		}
+		for _, static := range pages[i+1:] {
+			if strings.Contains(static.url, "[") || static.url == dynamic.url || !re.MatchString(static.url) {
+				continue
+			}
+			if dynamic.locale != "" && dynamic.locale == static.locale {
+				continue
+			}
+			errs.Append(routeConflictError(
+				fmt.Sprintf("%s shadows %s", dynamic.url, static.url),
+				fmt.Sprintf("The router tries %s first so %s can never be reached.", dynamic.url, static.url),
+				dynamic, static,
+			))
+		}
+	}
+}
+
+// sameLocaleVariants returns true if every view is a variant of the same locale. Their pages
+// are reported on their own.
+func sameLocaleVariants(views []routeView) bool {
+	for _, view := range views {
+		if view.locale == "" || view.locale != views[0].locale {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictingParam returns the first position where two routes have a required param with a
+// different name after an identical start
+func conflictingParam(a, b []string) (int, bool) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		if isRequiredParam(a[i]) && isRequiredParam(b[i]) {
+			return i, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+func isRequiredParam(segment string) bool {
+	return strings.HasPrefix(segment, "[") && !strings.HasPrefix(segment, "[[") &&
+		!strings.HasPrefix(segment, "[...") && strings.HasSuffix(segment, "]")
+}
+
+func routeConflictError(message, detail string, views ...routeView) *plugins.Error {
+	locations := []*plugins.ErrorLocation{}
+	for _, view := range views {
+		locations = append(locations, &plugins.ErrorLocation{Filepath: view.filepath})
+	}
+	return &plugins.Error{
+		Message:   message,
+		Detail:    detail,
+		Kind:      plugins.ErrorKindValidation,
+		Locations: locations,
 	}
-	return nil
 }
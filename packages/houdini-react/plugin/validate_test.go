@@ -2,18 +2,20 @@ package plugin_test
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 
-	coreConfig "code.houdinigraphql.com/packages/houdini-core/config"
 	"code.houdinigraphql.com/packages/houdini-react/plugin"
+	reactConfig "code.houdinigraphql.com/packages/houdini-react/plugin/config"
 	"code.houdinigraphql.com/plugins"
 	"code.houdinigraphql.com/plugins/tests"
 )
 
 func TestValidateRouteVariables(t *testing.T) {
-	tests.RunTable(t, tests.Table[coreConfig.PluginConfig, *plugin.HoudiniReact]{
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
 		Schema: `
 			type Query {
 				node(id: ID!): Node
@@ -22,7 +24,7 @@ func TestValidateRouteVariables(t *testing.T) {
 			interface Node { id: ID! }
 		`,
 
-		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[coreConfig.PluginConfig]) {
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
 			err := p.Validate(context.Background())
 			if test.Pass {
 				if err != nil {
@@ -37,10 +39,14 @@ func TestValidateRouteVariables(t *testing.T) {
 			require.Error(t, err)
 			list, ok := err.(*plugins.ErrorList)
 			require.True(t, ok, "expected an ErrorList")
-			require.Equal(t, plugins.ErrorKindValidation, list.GetItems()[0].Kind)
+			require.Equal(t, 1, list.Len())
+			item := list.GetItems()[0]
+			require.Equal(t, plugins.ErrorKindValidation, item.Kind)
+			require.Equal(t, test.Extra["message"], item.Message)
+			require.Equal(t, []*plugins.ErrorLocation{{Filepath: test.Filepaths[0]}}, item.Locations)
 		},
 
-		Tests: []tests.Test[coreConfig.PluginConfig]{
+		Tests: []tests.Test[reactConfig.PluginConfig]{
 			{
 				Name: "required variable backed by a route segment is allowed",
 				Pass: true,
@@ -72,6 +78,171 @@ func TestValidateRouteVariables(t *testing.T) {
 					"query Q($q: String!) {\n\tsearch(q: $q) {\n\t\tid\n\t}\n}\n",
 				},
 				Filepaths: []string{"src/routes/search/+page.gql"},
+				Extra: map[string]any{
+					"message": `required variable $q on "Q" can't be provided by the router: ` +
+						"add a [q] route segment, give it a default value, or make it nullable " +
+						"so it can be supplied via search params",
+				},
+			},
+		},
+	})
+}
+
+func TestValidateRoutes(t *testing.T) {
+	tests.RunTable(t, tests.Table[reactConfig.PluginConfig, *plugin.HoudiniReact]{
+		Schema: `
+			type Query {
+				id: ID
+			}
+		`,
+		SetupAlwaysPasses: true,
+
+		SetupTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			fs := p.Filesystem()
+			for _, fp := range test.Extra["views"].([]string) {
+				abs := filepath.Join("/project", fp)
+				require.NoError(t, fs.MkdirAll(filepath.Dir(abs), 0755))
+				require.NoError(t, afero.WriteFile(fs, abs, []byte(mockView([]string{})), 0644))
+			}
+
+			if i18n, ok := test.Extra["i18n"]; ok {
+				conn, err := p.DB.Take(context.Background())
+				require.NoError(t, err)
+				defer p.DB.Put(conn)
+				stmt, err := conn.Prepare(`INSERT INTO router_config (i18n, session_keys) VALUES ($i18n, '')`)
+				require.NoError(t, err)
+				defer stmt.Finalize()
+				require.NoError(t, p.DB.ExecStatement(stmt, map[string]any{"i18n": i18n}))
+			}
+		},
+
+		PerformTest: func(t *testing.T, p *plugin.HoudiniReact, test tests.Test[reactConfig.PluginConfig]) {
+			err := p.Validate(context.Background())
+			if test.Pass {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			list, ok := err.(*plugins.ErrorList)
+			require.True(t, ok, "expected an ErrorList")
+			require.Equal(t, 1, list.Len())
+
+			item := list.GetItems()[0]
+			require.Equal(t, plugins.ErrorKindValidation, item.Kind)
+			require.Equal(t, test.Extra["message"], item.Message)
+			files := []string{}
+			for _, location := range item.Locations {
+				files = append(files, location.Filepath)
+			}
+			require.Equal(t, test.Extra["files"], files)
+		},
+
+		Tests: []tests.Test[reactConfig.PluginConfig]{
+			{
+				Name: "static page before a dynamic sibling",
+				Pass: true,
+				Extra: map[string]any{
+					"views": []string{
+						"src/routes/+page.tsx",
+						"src/routes/docs/+page.tsx",
+						"src/routes/docs/[[lang]]/+page.tsx",
+						"src/routes/users/+page.tsx",
+						"src/routes/users/[id]/+page.tsx",
+						"src/routes/users/[id]/edit/+page.tsx",
+						"src/routes/(app)/settings/+page.tsx",
+						"src/routes/(app)/+layout.tsx",
+						"src/routes/+layout.tsx",
+					},
+				},
+			},
+			{
+				Name: "locale prefixes next to a root dynamic page",
+				Pass: true,
+				Extra: map[string]any{
+					"i18n": `{"locales": ["en", "fr"]}`,
+					"views": []string{
+						"src/routes/+page.tsx",
+						"src/routes/[slug]/+page.tsx",
+						"src/routes/[slug]/edit/+page.tsx",
+					},
+				},
+			},
+			{
+				Name: "page that collides with a locale prefix",
+				Pass: false,
+				Extra: map[string]any{
+					"i18n": `{"locales": ["en", "fr"]}`,
+					"views": []string{
+						"src/routes/+page.tsx",
+						"src/routes/fr/+page.tsx",
+					},
+					"message": "more than one page is served at /fr",
+					"files": []string{
+						"src/routes/+page.tsx",
+						"src/routes/fr/+page.tsx",
+					},
+				},
+			},
+			{
+				Name: "route groups with the same url",
+				Pass: false,
+				Extra: map[string]any{
+					"views": []string{
+						"src/routes/(app)/settings/+page.tsx",
+						"src/routes/(marketing)/settings/+page.tsx",
+					},
+					"message": "more than one page is served at /settings",
+					"files": []string{
+						"src/routes/(app)/settings/+page.tsx",
+						"src/routes/(marketing)/settings/+page.tsx",
+					},
+				},
+			},
+			{
+				Name: "param names that don't agree",
+				Pass: false,
+				Extra: map[string]any{
+					"views": []string{
+						"src/routes/users/[id]/+page.tsx",
+						"src/routes/users/[slug]/edit/+page.tsx",
+					},
+					"message": "[id] and [slug] use different names for the same part of the url",
+					"files": []string{
+						"src/routes/users/[id]/+page.tsx",
+						"src/routes/users/[slug]/edit/+page.tsx",
+					},
+				},
+			},
+			{
+				Name: "dynamic segment shadows a static page",
+				Pass: false,
+				Extra: map[string]any{
+					"views": []string{
+						"src/routes/users/[id]/+page.tsx",
+						"src/routes/users/new/+page.tsx",
+					},
+					"message": "/users/[id] shadows /users/new",
+					"files": []string{
+						"src/routes/users/[id]/+page.tsx",
+						"src/routes/users/new/+page.tsx",
+					},
+				},
+			},
+			{
+				Name: "rest param shadows a static page",
+				Pass: false,
+				Extra: map[string]any{
+					"views": []string{
+						"src/routes/files/[...path]/+page.tsx",
+						"src/routes/files/readme/+page.tsx",
+					},
+					"message": "/files/[...path] shadows /files/readme",
+					"files": []string{
+						"src/routes/files/[...path]/+page.tsx",
+						"src/routes/files/readme/+page.tsx",
+					},
+				},
 			},
 		},
 	})
@@ -11,6 +11,7 @@ import {
 } from './routing/index.js'
 
 export * from './hooks/index.js'
+export type { Session } from './sessionType.js'
 export {
 	router_cache,
 	useCache,
@@ -29,11 +30,14 @@ export {
 } from './routing/index.js'
 export type { GenericRoute } from './routing/index.js'
 export * from './Link.js'
+export { route } from './route.js'
+export type { RouteID, RouteParams, RouteSearch } from './route.js'
 export { createMock } from './mock.js'
 
 export function Router({
 	cache,
 	initialURL,
+	initialHost,
 	artifact_cache,
 	component_cache,
 	data_cache,
@@ -46,6 +50,7 @@ export function Router({
 	injectToStream,
 }: {
 	initialURL: string
+	initialHost?: string
 	initialVariables: GraphQLObject
 	cache: Cache
 	session?: App.Session
@@ -70,6 +75,7 @@ export function Router({
 		>
 			<RouterImpl
 				initialURL={initialURL}
+				initialHost={initialHost}
 				manifest={manifest}
 				assetPrefix={assetPrefix}
 				injectToStream={injectToStream}
@@ -0,0 +1,12 @@
+export type RouteID = string
+export type RouteParams = Record<RouteID, Record<string, unknown>>
+export type RouteSearch = Record<RouteID, Record<string, unknown>>
+
+export function route(
+	_id: RouteID,
+	_params?: Record<string, unknown>,
+	_search?: Record<string, unknown>,
+	_options?: { locale?: string }
+): string {
+	throw new Error('route: no routes have been generated yet. Run `houdini generate` first.')
+}
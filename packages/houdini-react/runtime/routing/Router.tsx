@@ -137,11 +137,15 @@ function useLoadingState({
 export function Router({
 	manifest,
 	initialURL,
+	initialHost,
 	assetPrefix,
 	injectToStream,
 }: {
 	manifest: RouterManifest<ComponentType>
 	initialURL?: string
+	// the host the request was served on. locale routing by domain uses it to pick the
+	// locale during server rendering
+	initialHost?: string
 	assetPrefix: string
 	injectToStream?: undefined | ((chunk: string) => void)
 }) {
@@ -157,6 +161,9 @@ export function Router({
 	// fast navigations never show it, and once shown it stays up for `minDuration`.
 	const [isNavigating, startNavigation] = React.useTransition()
 
+	// the host never changes without a full page load so we only need to look it up once
+	const host = initialHost ?? (typeof window !== 'undefined' ? window.location.host : undefined)
+
 	// pendingURL tracks the navigation target *urgently* (outside the transition), so a
 	// render that happens while the transition is still pending can tell whether it is
 	// looking at the destination (transition lane: currentURL === pendingURL) or at the
@@ -169,7 +176,7 @@ export function Router({
 	// and search params arrive in their url transport form, so we unmarshal them once here
 	// — the rich values feed both the query variables (which marshalInputs re-marshals for
 	// the request) and useRoute()'s params/search.
-	const [page, rawVariables, rawSearch] = find_match(manifest, currentURL)
+	const [page, rawVariables, rawSearch] = find_match(manifest, currentURL, true, host)
 	const unmarshalers = scalarUnmarshalers(
 		[...(page?.params ?? []), ...(page?.searchParams ?? [])],
 		getCurrentConfig()?.scalars
@@ -209,7 +216,7 @@ export function Router({
 	// for the whole transition, and arming the invisible state anyway would keep
 	// useNavigation().pending true for up to minDuration after the content committed.
 	const routerConfig = getCurrentConfig()?.router ?? {}
-	const [pendingPage] = pendingURL !== null ? find_match(manifest, pendingURL) : [null]
+	const [pendingPage] = pendingURL !== null ? find_match(manifest, pendingURL, true, host) : [null]
 	const destinationHasFrame = pendingPage
 		? Object.values(pendingPage.documents).some((document) => document.loading)
 		: false
@@ -411,7 +418,7 @@ export function Router({
 			// there are 2 things that we could preload: the page component and the data
 
 			// look for the matching route information
-			const [page, rawVariables] = find_match(manifest, url)
+			const [page, rawVariables] = find_match(manifest, url, true, host)
 			if (!page) {
 				return
 			}
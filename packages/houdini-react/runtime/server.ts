@@ -0,0 +1,102 @@
+import type { GraphQLError, GraphQLObject, GraphQLVariables, QueryArtifact } from 'houdini/runtime'
+import { Cache } from 'houdini/runtime/cache'
+import type { HoudiniClient } from 'houdini/runtime/client'
+import configFile from '$houdini/runtime/imports/config'
+import { cache } from 'react'
+
+// This module is imported by the server units generated in rsc mode. It runs in the
+// react-server environment, where hooks and contexts don't exist, so it must never import
+// anything from ./routing or ./hooks.
+
+// requestState holds the houdini cache for a single server render. React.cache scopes the
+// value to the request being rendered, so every server unit in the tree shares one cache
+// (and one send per query) without leaking data between requests.
+const requestState = cache(() => ({
+	cache: new Cache({ ...configFile, disabled: false }),
+	pending: new Map<string, Promise<GraphQLObject | null>>(),
+}))
+
+// loadServerQuery sends a query during a server render and resolves with its data. A page
+// and its layouts receive the same layout queries, so the send is shared by name and
+// variables for the rest of the request.
+export function loadServerQuery<_Data extends GraphQLObject>({
+	client,
+	artifact,
+	variables,
+	session,
+}: {
+	client: HoudiniClient
+	artifact: QueryArtifact
+	variables: GraphQLVariables
+	session?: App.Session
+}): Promise<_Data | null> {
+	const state = requestState()
+
+	const key = `${artifact.name}@${stableStringify(variables)}`
+	let pending = state.pending.get(key)
+	if (!pending) {
+		pending = sendQuery(state.cache, { client, artifact, variables, session })
+		state.pending.set(key, pending)
+	}
+	return pending as Promise<_Data | null>
+}
+
+async function sendQuery(
+	cache: Cache,
+	{
+		client,
+		artifact,
+		variables,
+		session,
+	}: {
+		client: HoudiniClient
+		artifact: QueryArtifact
+		variables: GraphQLVariables
+		session?: App.Session
+	}
+): Promise<GraphQLObject | null> {
+	const observer = client.observe({ artifact, cache })
+	const { errors } = await observer.send({ variables, session })
+	if (errors && errors.length > 0) {
+		throw graphqlErrors(errors)
+	}
+
+	// server components can't read the cache the way useFragment does, so the result is read
+	// back without masking: the fields every fragment selects travel with the query as plain,
+	// serializable props
+	return cache.read({
+		selection: artifact.selection,
+		variables: observer.state.variables ?? {},
+		ignoreMasking: true,
+	}).data
+}
+
+// stableStringify serializes with sorted keys so the same variables always give the same key
+function stableStringify(value: any): string {
+	if (Array.isArray(value)) {
+		return '[' + value.map(stableStringify).join(',') + ']'
+	}
+	if (value && typeof value === 'object') {
+		return (
+			'{' +
+			Object.keys(value)
+				.sort()
+				.map((k) => JSON.stringify(k) + ':' + stableStringify(value[k]))
+				.join(',') +
+			'}'
+		)
+	}
+	return JSON.stringify(value ?? null)
+}
+
+// graphqlErrors builds the error the render entry reports for a failed query. It has the
+// same shape as routing's GraphQLErrors (which imports contexts, so it can't be used here)
+// and the server handler matches on the name, not the class.
+function graphqlErrors(errors: GraphQLError[]): Error & { graphqlErrors: GraphQLError[] } {
+	const error = new Error(errors.map((e) => e.message).join('\n')) as Error & {
+		graphqlErrors: GraphQLError[]
+	}
+	error.name = 'GraphQLErrors'
+	error.graphqlErrors = errors
+	return error
+}
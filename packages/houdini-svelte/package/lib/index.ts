@@ -36,6 +36,14 @@ export type HoudiniSvelteConfig = {
 	 */
 	forceRunesMode?: boolean
 
+	/**
+	 * The kind of value generated for each document. `stores` generates classes that implement the
+	 * svelte/store contract. `runes` generates classes that hold their value in `$state` and expose it through
+	 * `current`. They aren't stores, so they can't be subscribed to or read with `$`.
+	 * @default 'stores'
+	 */
+	storeMode?: 'stores' | 'runes'
+
 	/**
 	 * Override the classes used when building stores for documents. Values should take the form package.export
 	 * For example, if you have a store exported from $lib/stores you should set the value to "$lib/stores.CustomStore".
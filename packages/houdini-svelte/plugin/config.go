@@ -49,41 +49,12 @@ func (p *HoudiniSvelte) DefaultConfig(
 		pluginConfig.ClientPath = "./src/client"
 	}
 
-	if pluginConfig.CustomStores.Query == "" {
-		pluginConfig.CustomStores.Query = "$houdini/plugins/houdini-svelte/runtime/stores/query.js#QueryStore"
+	if pluginConfig.StoreMode == "" {
+		pluginConfig.StoreMode = config.StoreModeStores
 	}
 
-	if pluginConfig.CustomStores.Mutation == "" {
-		pluginConfig.CustomStores.Mutation = "$houdini/plugins/houdini-svelte/runtime/stores/mutation.js#MutationStore"
-	}
-
-	if pluginConfig.CustomStores.Fragment == "" {
-		pluginConfig.CustomStores.Fragment = "$houdini/plugins/houdini-svelte/runtime/stores/fragment.js#FragmentStore"
-	}
-
-	if pluginConfig.CustomStores.Subscription == "" {
-		pluginConfig.CustomStores.Subscription = "$houdini/plugins/houdini-svelte/runtime/stores/subscription.js#SubscriptionStore"
-	}
-
-	if pluginConfig.CustomStores.QueryCursor == "" {
-		pluginConfig.CustomStores.QueryCursor = "$houdini/plugins/houdini-svelte/runtime/stores/pagination/query.js#QueryStoreCursor"
-	}
-
-	if pluginConfig.CustomStores.QueryOffset == "" {
-		pluginConfig.CustomStores.QueryOffset = "$houdini/plugins/houdini-svelte/runtime/stores/pagination/query.js#QueryStoreOffset"
-	}
-
-	if pluginConfig.CustomStores.FragmentOffset == "" {
-		pluginConfig.CustomStores.FragmentOffset = "$houdini/plugins/houdini-svelte/runtime/stores/pagination/fragment.js#FragmentStoreOffset"
-	}
-
-	if pluginConfig.CustomStores.FragmentCursor == "" {
-		pluginConfig.CustomStores.FragmentCursor = "$houdini/plugins/houdini-svelte/runtime/stores/pagination/fragment.js#FragmentStoreCursor"
-	}
-
-	if pluginConfig.CustomStores.FragmentRefetchable == "" {
-		pluginConfig.CustomStores.FragmentRefetchable = "$houdini/plugins/houdini-svelte/runtime/stores/refetchable.js#FragmentStoreRefetchable"
-	}
+	// any store class that hasn't been customized points to the runtime class for the mode
+	pluginConfig.CustomStores = pluginConfig.CustomStores.WithDefaults(pluginConfig.StoreMode)
 
 	return pluginConfig, nil
 }
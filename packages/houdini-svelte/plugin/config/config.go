@@ -22,9 +22,20 @@ const (
 	StorePaginationTypeRefetchable = "refetchable"
 )
 
+// StoreMode picks the kind of reactive value generated for each document
+type StoreMode = string
+
+const (
+	// StoreModeStores generates classes that implement the svelte/store contract
+	StoreModeStores = "stores"
+	// StoreModeRunes generates classes whose values are backed by $state
+	StoreModeRunes = "runes"
+)
+
 type PluginConfig struct {
 	Framework    PluginFramework        `json:"framework"`
 	ClientPath   string                 `json:"client"`
+	StoreMode    StoreMode              `json:"storeMode"`
 	CustomStores PluginConfigStorePaths `json:"customStores"`
 }
 
@@ -40,6 +51,56 @@ type PluginConfigStorePaths struct {
 	FragmentRefetchable string `json:"fragmentRefetchable"`
 }
 
+// DefaultStorePaths returns the runtime classes that generated documents extend in the given mode
+func DefaultStorePaths(mode StoreMode) PluginConfigStorePaths {
+	if mode == StoreModeRunes {
+		return PluginConfigStorePaths{
+			Query:               "$houdini/plugins/houdini-svelte/runtime/runes/query.js#QueryRunes",
+			Mutation:            "$houdini/plugins/houdini-svelte/runtime/runes/mutation.js#MutationRunes",
+			Fragment:            "$houdini/plugins/houdini-svelte/runtime/runes/fragment.js#FragmentRunes",
+			Subscription:        "$houdini/plugins/houdini-svelte/runtime/runes/subscription.js#SubscriptionRunes",
+			QueryCursor:         "$houdini/plugins/houdini-svelte/runtime/runes/query.js#QueryRunesCursor",
+			QueryOffset:         "$houdini/plugins/houdini-svelte/runtime/runes/query.js#QueryRunesOffset",
+			FragmentCursor:      "$houdini/plugins/houdini-svelte/runtime/runes/fragment.js#FragmentRunesCursor",
+			FragmentOffset:      "$houdini/plugins/houdini-svelte/runtime/runes/fragment.js#FragmentRunesOffset",
+			FragmentRefetchable: "$houdini/plugins/houdini-svelte/runtime/runes/fragment.js#FragmentRunesRefetchable",
+		}
+	}
+
+	return PluginConfigStorePaths{
+		Query:               "$houdini/plugins/houdini-svelte/runtime/stores/query.js#QueryStore",
+		Mutation:            "$houdini/plugins/houdini-svelte/runtime/stores/mutation.js#MutationStore",
+		Fragment:            "$houdini/plugins/houdini-svelte/runtime/stores/fragment.js#FragmentStore",
+		Subscription:        "$houdini/plugins/houdini-svelte/runtime/stores/subscription.js#SubscriptionStore",
+		QueryCursor:         "$houdini/plugins/houdini-svelte/runtime/stores/pagination/query.js#QueryStoreCursor",
+		QueryOffset:         "$houdini/plugins/houdini-svelte/runtime/stores/pagination/query.js#QueryStoreOffset",
+		FragmentCursor:      "$houdini/plugins/houdini-svelte/runtime/stores/pagination/fragment.js#FragmentStoreCursor",
+		FragmentOffset:      "$houdini/plugins/houdini-svelte/runtime/stores/pagination/fragment.js#FragmentStoreOffset",
+		FragmentRefetchable: "$houdini/plugins/houdini-svelte/runtime/stores/refetchable.js#FragmentStoreRefetchable",
+	}
+}
+
+// WithDefaults fills every class that hasn't been customized with the default for the mode
+func (p PluginConfigStorePaths) WithDefaults(mode StoreMode) PluginConfigStorePaths {
+	defaults := DefaultStorePaths(mode)
+	for _, slot := range []struct{ value, fallback *string }{
+		{&p.Query, &defaults.Query},
+		{&p.Mutation, &defaults.Mutation},
+		{&p.Fragment, &defaults.Fragment},
+		{&p.Subscription, &defaults.Subscription},
+		{&p.QueryCursor, &defaults.QueryCursor},
+		{&p.QueryOffset, &defaults.QueryOffset},
+		{&p.FragmentCursor, &defaults.FragmentCursor},
+		{&p.FragmentOffset, &defaults.FragmentOffset},
+		{&p.FragmentRefetchable, &defaults.FragmentRefetchable},
+	} {
+		if *slot.value == "" {
+			*slot.value = *slot.fallback
+		}
+	}
+	return p
+}
+
 type Import struct {
 	Name   string
 	Module string
@@ -49,32 +110,33 @@ func (c PluginConfig) StoreBaseClassImport(
 	documentType string,
 	paginated StorePaginationType,
 ) (Import, error) {
-	// the import specification is defined in the plugin config as <module>.<name>
+	// the import specification is defined in the plugin config as <module>#<name>
+	stores := c.CustomStores.WithDefaults(c.StoreMode)
 	var importString string
 	switch documentType {
 	case "mutation":
-		importString = c.CustomStores.Mutation
+		importString = stores.Mutation
 	case "subscription":
-		importString = c.CustomStores.Subscription
+		importString = stores.Subscription
 	case "fragment":
 		switch paginated {
 		case StorePaginationTypeNone:
-			importString = c.CustomStores.Fragment
+			importString = stores.Fragment
 		case StorePaginationTypeCursor:
-			importString = c.CustomStores.FragmentCursor
+			importString = stores.FragmentCursor
 		case StorePaginationTypeOffset:
-			importString = c.CustomStores.FragmentOffset
+			importString = stores.FragmentOffset
 		case StorePaginationTypeRefetchable:
-			importString = c.CustomStores.FragmentRefetchable
+			importString = stores.FragmentRefetchable
 		}
 	case "query":
 		switch paginated {
 		case StorePaginationTypeNone:
-			importString = c.CustomStores.Query
+			importString = stores.Query
 		case StorePaginationTypeCursor:
-			importString = c.CustomStores.QueryCursor
+			importString = stores.QueryCursor
 		case StorePaginationTypeOffset:
-			importString = c.CustomStores.QueryOffset
+			importString = stores.QueryOffset
 		}
 	}
 
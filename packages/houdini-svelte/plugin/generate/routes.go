@@ -0,0 +1,490 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/config"
+	"code.houdinigraphql.com/plugins"
+)
+
+// Route is a directory under src/routes that holds a +page.gql or +layout.gql file
+type Route struct {
+	// Dir is the directory relative to src/routes, using forward slashes ("." for the root)
+	Dir    string
+	Params []RouteParam
+	Page   []RouteQuery
+	Layout []RouteQuery
+}
+
+// RouteParam is a dynamic segment of a route directory ([id], [[lang]], [...rest], or [id=matcher])
+type RouteParam struct {
+	Name     string
+	Optional bool
+}
+
+// RouteQuery is a query defined in a route's +page.gql or +layout.gql file
+type RouteQuery struct {
+	Name      string
+	Variables []RouteVariable
+}
+
+// RouteVariable describes where the load function reads a query variable from
+type RouteVariable struct {
+	Name string
+	// Source is either "route" or "search"
+	Source string
+	// Kind is the scalar the raw string has to be parsed into (Int, Float, Boolean, or String)
+	Kind string
+}
+
+const (
+	RouteVariableSourceRoute  = "route"
+	RouteVariableSourceSearch = "search"
+)
+
+// routeParamPattern matches the parameters that SvelteKit recognizes inside of a route segment
+var routeParamPattern = regexp.MustCompile(`\[(\[)?(\.\.\.)?([A-Za-z_$][A-Za-z0-9_$]*)(=[A-Za-z0-9_]+)?\]`)
+
+// LoadRoutes looks at every query defined in a +page.gql or +layout.gql file under src/routes
+// and figures out how its load function provides each variable:
+//   - variables named after a dynamic segment of the route are read from the params
+//   - other nullable scalars are read from the search params
+//   - required variables that the route can't provide need a default value
+//
+// Anything that can't be satisfied is added to errs with the location of the document.
+func LoadRoutes(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	errs *plugins.ErrorList,
+) ([]Route, error) {
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routesDir := filepath.Join(projectConfig.ProjectRoot, "src", "routes")
+
+	type variable struct {
+		name       string
+		typ        string
+		modifiers  string
+		typeKind   string
+		hasDefault bool
+	}
+	type document struct {
+		name      string
+		filepath  string
+		line      int
+		column    int
+		page      bool
+		dir       string
+		variables []variable
+	}
+	documents := map[int64]*document{}
+	order := []int64{}
+
+	err = db.StepQuery(ctx, `
+		SELECT
+			d.id,
+			d.name,
+			rd.filepath,
+			rd.offset_line,
+			rd.offset_column,
+			dv.name,
+			dv.type,
+			COALESCE(dv.type_modifiers, ''),
+			COALESCE(t.kind, ''),
+			dv.default_value IS NOT NULL
+		FROM documents d
+			JOIN raw_documents rd ON rd.id = d.raw_document
+			LEFT JOIN document_variables dv ON dv.document = d.id
+			LEFT JOIN types t ON t.name = dv.type
+		WHERE d.kind = 'query'
+			AND (rd.filepath LIKE '%+page.gql' OR rd.filepath LIKE '%+layout.gql')
+		ORDER BY d.id, dv.id
+	`, nil, func(row plugins.Row) {
+		id := row.ColumnInt64(0)
+		doc, ok := documents[id]
+		if !ok {
+			fp := row.ColumnText(2)
+			dir, err := filepath.Rel(routesDir, filepath.Dir(filepath.Join(projectConfig.ProjectRoot, fp)))
+			if err != nil || strings.HasPrefix(dir, "..") {
+				// the file isn't a route
+				documents[id] = nil
+				return
+			}
+			doc = &document{
+				name:     row.ColumnText(1),
+				filepath: fp,
+				line:     row.ColumnInt(3),
+				column:   row.ColumnInt(4),
+				page:     strings.HasSuffix(fp, "+page.gql"),
+				dir:      filepath.ToSlash(dir),
+			}
+			documents[id] = doc
+			order = append(order, id)
+		}
+		if doc == nil {
+			return
+		}
+
+		// the variable columns are empty when the query doesn't have any
+		if name := row.ColumnText(5); name != "" {
+			doc.variables = append(doc.variables, variable{
+				name:       name,
+				typ:        row.ColumnText(6),
+				modifiers:  row.ColumnText(7),
+				typeKind:   row.ColumnText(8),
+				hasDefault: row.ColumnBool(9),
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routes := map[string]*Route{}
+	for _, id := range order {
+		doc := documents[id]
+		route, ok := routes[doc.dir]
+		if !ok {
+			route = &Route{Dir: doc.dir, Params: routeParams(doc.dir)}
+			routes[doc.dir] = route
+		}
+		params := map[string]RouteParam{}
+		for _, param := range route.Params {
+			params[param.Name] = param
+		}
+
+		location := []*plugins.ErrorLocation{{
+			Filepath: doc.filepath,
+			Line:     doc.line,
+			Column:   doc.column,
+		}}
+
+		query := RouteQuery{Name: doc.name, Variables: []RouteVariable{}}
+		for _, v := range doc.variables {
+			required := strings.HasSuffix(v.modifiers, "!")
+			// route and search params are strings so they can only provide scalars
+			scalar := !strings.Contains(v.modifiers, "]") && (v.typeKind == "SCALAR" || v.typeKind == "ENUM")
+
+			param, fromRoute := params[v.name]
+			switch {
+			case fromRoute && !scalar:
+				errs.Append(&plugins.Error{
+					Message: fmt.Sprintf(
+						"route parameter %q can't provide variable $%s of %s since it isn't a scalar",
+						v.name, v.name, doc.name,
+					),
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+
+			case fromRoute && param.Optional && required && !v.hasDefault:
+				errs.Append(&plugins.Error{
+					Message: fmt.Sprintf(
+						"variable $%s of %s is required but the route parameter %q is optional",
+						v.name, doc.name, v.name,
+					),
+					Detail:    "Make the variable nullable or give it a default value.",
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+
+			case fromRoute:
+				query.Variables = append(query.Variables, RouteVariable{
+					Name:   v.name,
+					Source: RouteVariableSourceRoute,
+					Kind:   routeVariableKind(v.typ, v.typeKind),
+				})
+
+			case !required && scalar:
+				query.Variables = append(query.Variables, RouteVariable{
+					Name:   v.name,
+					Source: RouteVariableSourceSearch,
+					Kind:   routeVariableKind(v.typ, v.typeKind),
+				})
+
+			case required && !v.hasDefault:
+				errs.Append(&plugins.Error{
+					Message: fmt.Sprintf(
+						"variable $%s of %s is required but the route /%s does not have a parameter with that name",
+						v.name, doc.name, strings.TrimPrefix(doc.dir, "."),
+					),
+					Detail:    "Add a matching route segment, make the variable nullable, or give it a default value.",
+					Kind:      plugins.ErrorKindValidation,
+					Locations: location,
+				})
+			}
+		}
+
+		if doc.page {
+			route.Page = append(route.Page, query)
+		} else {
+			route.Layout = append(route.Layout, query)
+		}
+	}
+	// sort the routes so the output is stable
+	result := make([]Route, 0, len(routes))
+	for _, route := range routes {
+		result = append(result, *route)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Dir < result[j].Dir
+	})
+	return result, nil
+}
+
+// GenerateRoutes writes a load function for every page and layout that has a colocated query,
+// along with a $houdini.d.ts type root that exposes PageData, LayoutData, and the route
+// params next to the route (tsconfig's rootDirs merges .houdini/types with the project).
+// Files left behind by a route that lost its query are removed and their paths are returned
+// along with the ones that were written.
+func GenerateRoutes(
+	ctx context.Context,
+	db plugins.DatabasePool[config.PluginConfig],
+	fs afero.Fs,
+) ([]string, error) {
+	projectConfig, err := db.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pluginConfig, err := db.PluginConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := &plugins.ErrorList{}
+	routes, err := LoadRoutes(ctx, db, errs)
+	if err != nil {
+		return nil, err
+	}
+	if errs.Len() > 0 {
+		return nil, errs
+	}
+
+	routesDir := filepath.Join(projectConfig.PluginDirectory("houdini-svelte"), "routes")
+	typesDir := filepath.Join(projectConfig.ProjectRoot, projectConfig.RuntimeDir, "types", "src", "routes")
+
+	files := []string{}
+	expected := map[string]bool{}
+	write := func(path string, content string) error {
+		expected[path] = true
+		if existing, err := afero.ReadFile(fs, path); err == nil && string(existing) == content {
+			return nil
+		}
+		if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := plugins.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			return err
+		}
+		files = append(files, path)
+		return nil
+	}
+
+	for _, route := range routes {
+		loadDir := filepath.Join(routesDir, filepath.FromSlash(route.Dir))
+		typeDir := filepath.Join(typesDir, filepath.FromSlash(route.Dir))
+
+		var typeRoot strings.Builder
+		for _, unit := range []struct {
+			file    string
+			prefix  string
+			queries []RouteQuery
+		}{
+			{"+layout", "Layout", route.Layout},
+			{"+page", "Page", route.Page},
+		} {
+			if len(unit.queries) == 0 {
+				continue
+			}
+
+			err := write(
+				filepath.Join(loadDir, unit.file+".ts"),
+				generateRouteLoad(pluginConfig.StoreMode, unit.prefix, route.Params, unit.queries),
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			importPath, err := filepath.Rel(typeDir, filepath.Join(loadDir, unit.file))
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(
+				&typeRoot,
+				"export type { %sData, %sParams } from '%s'\n",
+				unit.prefix,
+				unit.prefix,
+				filepath.ToSlash(importPath),
+			)
+		}
+
+		err := write(filepath.Join(typeDir, "$houdini.d.ts"), typeRoot.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a load function that outlives its query still imports the query's store. everything in
+	// the routes directory is ours but the type roots share their directory with other types
+	removed, err := removeStaleFiles(fs, routesDir, func(path string) bool {
+		return !expected[path]
+	})
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, removed...)
+
+	removed, err = removeStaleFiles(fs, typesDir, func(path string) bool {
+		return filepath.Base(path) == "$houdini.d.ts" && !expected[path]
+	})
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, removed...)
+
+	return files, nil
+}
+
+// removeStaleFiles deletes every file under the directory that stale returns true for and
+// returns their paths. A directory that doesn't exist has nothing to remove.
+func removeStaleFiles(fs afero.Fs, dir string, stale func(string) bool) ([]string, error) {
+	removed := []string{}
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !stale(path) {
+			return nil
+		}
+		if err := fs.Remove(path); err != nil {
+			return err
+		}
+		removed = append(removed, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// generateRouteLoad builds the module for a single page or layout: the type of its params and
+// data, and a load function that fetches every query with the variables pulled from the event.
+// The data holds the class generated for each query, which is a store unless the project
+// uses runes.
+func generateRouteLoad(
+	storeMode config.StoreMode,
+	prefix string,
+	params []RouteParam,
+	queries []RouteQuery,
+) string {
+	var b strings.Builder
+
+	b.WriteString("import type { LoadEvent } from '@sveltejs/kit'\n")
+	b.WriteString("import { routeVariable } from '$houdini/plugins/houdini-svelte/runtime/routes'\n")
+	for _, query := range queries {
+		fmt.Fprintf(
+			&b,
+			"import { load_%s, type %sStore } from '$houdini/plugins/houdini-svelte/stores/%s'\n",
+			query.Name,
+			query.Name,
+			query.Name,
+		)
+	}
+
+	// the params that SvelteKit passes to the load function
+	fmt.Fprintf(&b, "\nexport type %sParams = {\n", prefix)
+	for _, param := range params {
+		if param.Optional {
+			fmt.Fprintf(&b, "    %s?: string\n", param.Name)
+		} else {
+			fmt.Fprintf(&b, "    %s: string\n", param.Name)
+		}
+	}
+	b.WriteString("}\n")
+
+	// the data returned by the load function is the document of every query
+	b.WriteString("\n")
+	if storeMode == config.StoreModeRunes {
+		b.WriteString("// runes documents aren't stores: read their value from current\n")
+	}
+	fmt.Fprintf(&b, "export type %sData = {\n", prefix)
+	for _, query := range queries {
+		fmt.Fprintf(&b, "    %s: %sStore\n", query.Name, query.Name)
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(
+		&b,
+		"\nexport async function load(event: LoadEvent<%sParams>): Promise<%sData> {\n",
+		prefix,
+		prefix,
+	)
+	b.WriteString("    const results = await Promise.all([\n")
+	for _, query := range queries {
+		fmt.Fprintf(&b, "        load_%s({\n            event,\n            variables: {", query.Name)
+		for _, variable := range query.Variables {
+			value := fmt.Sprintf("event.params.%s", variable.Name)
+			if variable.Source == RouteVariableSourceSearch {
+				value = fmt.Sprintf("event.url.searchParams.get('%s')", variable.Name)
+			}
+			fmt.Fprintf(
+				&b,
+				"\n                %s: routeVariable(%s, '%s'),",
+				variable.Name,
+				value,
+				variable.Kind,
+			)
+		}
+		if len(query.Variables) > 0 {
+			b.WriteString("\n            ")
+		}
+		b.WriteString("},\n        }),\n")
+	}
+	b.WriteString("    ])\n\n")
+	fmt.Fprintf(&b, "    return Object.assign({}, ...results) as %sData\n}\n", prefix)
+
+	return b.String()
+}
+
+// routeParams extracts the dynamic segments of a route directory
+func routeParams(dir string) []RouteParam {
+	params := []RouteParam{}
+	for _, segment := range strings.Split(dir, "/") {
+		for _, match := range routeParamPattern.FindAllStringSubmatch(segment, -1) {
+			// a rest parameter is always present (it's an empty string when nothing matches)
+			params = append(params, RouteParam{
+				Name:     match[3],
+				Optional: match[1] != "",
+			})
+		}
+	}
+	return params
+}
+
+// routeVariableKind returns the scalar that a raw param has to be parsed into before
+// it's passed to the query. Everything that isn't a number or boolean is sent as a string.
+func routeVariableKind(typ string, typeKind string) string {
+	if typeKind != "SCALAR" {
+		return "String"
+	}
+	switch typ {
+	case "Int", "Float", "Boolean":
+		return typ
+	}
+	return "String"
+}
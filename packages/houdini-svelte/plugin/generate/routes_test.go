@@ -0,0 +1,367 @@
+package generate_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin"
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/config"
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/generate"
+	"code.houdinigraphql.com/plugins"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestGenerateRoutes(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniSvelte]{
+		Plugin: tests.Plugin[config.PluginConfig]{
+			Name:   "houdini-svelte",
+			Config: config.PluginConfig{Framework: config.PluginFrameworkKit},
+		},
+		Schema: `
+			type Query {
+				user(id: ID!, posts: Int, published: Boolean): User
+				users(filter: UserFilter, page: Int!): [User!]!
+				viewer(lang: String): User
+			}
+
+			input UserFilter {
+				name: String
+			}
+
+			type User {
+				id: ID!
+				name: String!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniSvelte, test tests.Test[config.PluginConfig]) {
+			ctx := context.Background()
+			projectConfig, err := p.DB.ProjectConfig(ctx)
+			require.NoError(t, err)
+
+			_, err = generate.GenerateRoutes(ctx, p.DB, p.Fs)
+			if !test.Pass {
+				require.Error(t, err)
+				errs, ok := err.(*plugins.ErrorList)
+				require.True(t, ok, "expected an error list")
+				require.Contains(t, errs.Error(), test.Extra["error"])
+				require.Equal(t, plugins.ErrorKindValidation, errs.GetItems()[0].Kind)
+				return
+			}
+			require.NoError(t, err)
+
+			for file, expected := range test.Extra {
+				contents, err := afero.ReadFile(p.Fs, filepath.Join(projectConfig.ProjectRoot, projectConfig.RuntimeDir, file))
+				require.NoError(t, err)
+				require.Equal(t, expected.(string)+"\n", string(contents))
+			}
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "page and layout queries",
+				Pass: true,
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+					"src/routes/users/+layout.gql",
+				},
+				Input: []string{
+					`query UserInfo($id: ID!, $posts: Int, $published: Boolean = true) {
+						user(id: $id, posts: $posts, published: $published) { name }
+					}`,
+					`query AllUsers($page: Int! = 1, $filter: UserFilter) {
+						users(page: $page, filter: $filter) { id }
+					}`,
+				},
+				Extra: map[string]any{
+					"plugins/houdini-svelte/routes/users/[id]/+page.ts": tests.Dedent(`
+						import type { LoadEvent } from '@sveltejs/kit'
+						import { routeVariable } from '$houdini/plugins/houdini-svelte/runtime/routes'
+						import { load_UserInfo, type UserInfoStore } from '$houdini/plugins/houdini-svelte/stores/UserInfo'
+
+						export type PageParams = {
+						    id: string
+						}
+
+						export type PageData = {
+						    UserInfo: UserInfoStore
+						}
+
+						export async function load(event: LoadEvent<PageParams>): Promise<PageData> {
+						    const results = await Promise.all([
+						        load_UserInfo({
+						            event,
+						            variables: {
+						                id: routeVariable(event.params.id, 'String'),
+						                posts: routeVariable(event.url.searchParams.get('posts'), 'Int'),
+						                published: routeVariable(event.url.searchParams.get('published'), 'Boolean'),
+						            },
+						        }),
+						    ])
+
+						    return Object.assign({}, ...results) as PageData
+						}
+					`),
+					"plugins/houdini-svelte/routes/users/+layout.ts": tests.Dedent(`
+						import type { LoadEvent } from '@sveltejs/kit'
+						import { routeVariable } from '$houdini/plugins/houdini-svelte/runtime/routes'
+						import { load_AllUsers, type AllUsersStore } from '$houdini/plugins/houdini-svelte/stores/AllUsers'
+
+						export type LayoutParams = {
+						}
+
+						export type LayoutData = {
+						    AllUsers: AllUsersStore
+						}
+
+						export async function load(event: LoadEvent<LayoutParams>): Promise<LayoutData> {
+						    const results = await Promise.all([
+						        load_AllUsers({
+						            event,
+						            variables: {},
+						        }),
+						    ])
+
+						    return Object.assign({}, ...results) as LayoutData
+						}
+					`),
+					"types/src/routes/users/[id]/$houdini.d.ts": "export type { PageData, PageParams } from '../../../../../plugins/houdini-svelte/routes/users/[id]/+page'",
+					"types/src/routes/users/$houdini.d.ts":      "export type { LayoutData, LayoutParams } from '../../../../plugins/houdini-svelte/routes/users/+layout'",
+				},
+			},
+			{
+				Name: "optional and matched params",
+				Pass: true,
+				Filepaths: []string{
+					"src/routes/[[lang]]/(app)/[id=integer]/+page.gql",
+				},
+				Input: []string{
+					`query Viewer($lang: String, $id: ID!) {
+						viewer(lang: $lang) { id }
+						user(id: $id) { id }
+					}`,
+				},
+				Extra: map[string]any{
+					"plugins/houdini-svelte/routes/[[lang]]/(app)/[id=integer]/+page.ts": tests.Dedent(`
+						import type { LoadEvent } from '@sveltejs/kit'
+						import { routeVariable } from '$houdini/plugins/houdini-svelte/runtime/routes'
+						import { load_Viewer, type ViewerStore } from '$houdini/plugins/houdini-svelte/stores/Viewer'
+
+						export type PageParams = {
+						    lang?: string
+						    id: string
+						}
+
+						export type PageData = {
+						    Viewer: ViewerStore
+						}
+
+						export async function load(event: LoadEvent<PageParams>): Promise<PageData> {
+						    const results = await Promise.all([
+						        load_Viewer({
+						            event,
+						            variables: {
+						                lang: routeVariable(event.params.lang, 'String'),
+						                id: routeVariable(event.params.id, 'String'),
+						            },
+						        }),
+						    ])
+
+						    return Object.assign({}, ...results) as PageData
+						}
+					`),
+				},
+			},
+			{
+				Name:      "required variable without a route param",
+				Pass:      false,
+				Filepaths: []string{"src/routes/users/+page.gql"},
+				Input: []string{
+					`query UserInfo($id: ID!) { user(id: $id) { name } }`,
+				},
+				Extra: map[string]any{
+					"error": "variable $id of UserInfo is required but the route /users does not have a parameter with that name",
+				},
+			},
+			{
+				Name:      "required variable from an optional param",
+				Pass:      false,
+				Filepaths: []string{"src/routes/users/[[id]]/+page.gql"},
+				Input: []string{
+					`query UserInfo($id: ID!) { user(id: $id) { name } }`,
+				},
+				Extra: map[string]any{
+					"error": `variable $id of UserInfo is required but the route parameter "id" is optional`,
+				},
+			},
+			{
+				Name:      "route param for an input object",
+				Pass:      false,
+				Filepaths: []string{"src/routes/users/[filter]/+page.gql"},
+				Input: []string{
+					`query AllUsers($filter: UserFilter) { users(page: 1, filter: $filter) { id } }`,
+				},
+				Extra: map[string]any{
+					"error": `route parameter "filter" can't provide variable $filter of AllUsers since it isn't a scalar`,
+				},
+			},
+		},
+	})
+}
+
+func TestGenerateRoutes_removedQuery(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniSvelte]{
+		Plugin: tests.Plugin[config.PluginConfig]{
+			Name:   "houdini-svelte",
+			Config: config.PluginConfig{Framework: config.PluginFrameworkKit},
+		},
+		Schema: `
+			type Query {
+				user(id: ID!): User
+				users: [User!]!
+			}
+
+			type User {
+				id: ID!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniSvelte, test tests.Test[config.PluginConfig]) {
+			ctx := context.Background()
+			projectConfig, err := p.DB.ProjectConfig(ctx)
+			require.NoError(t, err)
+			runtimeDir := filepath.Join(projectConfig.ProjectRoot, projectConfig.RuntimeDir)
+
+			// another generator owns the rest of the type roots
+			other := filepath.Join(runtimeDir, "types", "src", "routes", "users", "[id]", "other.d.ts")
+			require.NoError(t, afero.WriteFile(p.Fs, other, []byte("export {}\n"), 0644))
+
+			_, err = generate.GenerateRoutes(ctx, p.DB, p.Fs)
+			require.NoError(t, err)
+
+			// the user deletes the page query
+			conn, err := p.DB.Take(ctx)
+			require.NoError(t, err)
+			stmt, err := conn.Prepare("DELETE FROM documents WHERE name = 'UserInfo'")
+			require.NoError(t, err)
+			require.NoError(t, p.DB.ExecStatement(stmt, nil))
+			stmt.Finalize()
+			p.DB.Put(conn)
+
+			files, err := generate.GenerateRoutes(ctx, p.DB, p.Fs)
+			require.NoError(t, err)
+
+			gone := []string{
+				filepath.Join(runtimeDir, "plugins", "houdini-svelte", "routes", "users", "[id]", "+page.ts"),
+				filepath.Join(runtimeDir, "types", "src", "routes", "users", "[id]", "$houdini.d.ts"),
+			}
+			require.ElementsMatch(t, gone, files)
+			for _, file := range gone {
+				exists, err := afero.Exists(p.Fs, file)
+				require.NoError(t, err)
+				require.False(t, exists, "expected %s to be removed", file)
+			}
+
+			// the other route and the files we don't own are still there
+			for _, file := range []string{
+				filepath.Join(runtimeDir, "plugins", "houdini-svelte", "routes", "users", "+layout.ts"),
+				filepath.Join(runtimeDir, "types", "src", "routes", "users", "$houdini.d.ts"),
+				other,
+			} {
+				exists, err := afero.Exists(p.Fs, file)
+				require.NoError(t, err)
+				require.True(t, exists, "expected %s to exist", file)
+			}
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "page query is removed",
+				Pass: true,
+				Filepaths: []string{
+					"src/routes/users/[id]/+page.gql",
+					"src/routes/users/+layout.gql",
+				},
+				Input: []string{
+					`query UserInfo($id: ID!) { user(id: $id) { id } }`,
+					`query AllUsers { users { id } }`,
+				},
+			},
+		},
+	})
+}
+
+func TestGenerateRoutes_runes(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniSvelte]{
+		Plugin: tests.Plugin[config.PluginConfig]{
+			Name: "houdini-svelte",
+			Config: config.PluginConfig{
+				Framework: config.PluginFrameworkKit,
+				StoreMode: config.StoreModeRunes,
+			},
+		},
+		Schema: `
+			type Query {
+				user(id: ID!): User
+			}
+
+			type User {
+				id: ID!
+				name: String!
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniSvelte, test tests.Test[config.PluginConfig]) {
+			ctx := context.Background()
+			projectConfig, err := p.DB.ProjectConfig(ctx)
+			require.NoError(t, err)
+
+			_, err = generate.GenerateRoutes(ctx, p.DB, p.Fs)
+			require.NoError(t, err)
+
+			for file, expected := range test.Extra {
+				contents, err := afero.ReadFile(p.Fs, filepath.Join(projectConfig.ProjectRoot, projectConfig.RuntimeDir, file))
+				require.NoError(t, err)
+				require.Equal(t, expected.(string)+"\n", string(contents))
+			}
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name:      "page data holds runes documents",
+				Pass:      true,
+				Filepaths: []string{"src/routes/users/[id]/+page.gql"},
+				Input: []string{
+					`query UserInfo($id: ID!) { user(id: $id) { name } }`,
+				},
+				Extra: map[string]any{
+					"plugins/houdini-svelte/routes/users/[id]/+page.ts": tests.Dedent(`
+						import type { LoadEvent } from '@sveltejs/kit'
+						import { routeVariable } from '$houdini/plugins/houdini-svelte/runtime/routes'
+						import { load_UserInfo, type UserInfoStore } from '$houdini/plugins/houdini-svelte/stores/UserInfo'
+
+						export type PageParams = {
+						    id: string
+						}
+
+						// runes documents aren't stores: read their value from current
+						export type PageData = {
+						    UserInfo: UserInfoStore
+						}
+
+						export async function load(event: LoadEvent<PageParams>): Promise<PageData> {
+						    const results = await Promise.all([
+						        load_UserInfo({
+						            event,
+						            variables: {
+						                id: routeVariable(event.params.id, 'String'),
+						            },
+						        }),
+						    ])
+
+						    return Object.assign({}, ...results) as PageData
+						}
+					`),
+				},
+			},
+		},
+	})
+}
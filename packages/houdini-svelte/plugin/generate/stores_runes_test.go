@@ -0,0 +1,184 @@
+package generate_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin"
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/config"
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/generate"
+	"code.houdinigraphql.com/plugins/tests"
+)
+
+func TestGenerateStores_runes(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniSvelte]{
+		Plugin: tests.Plugin[config.PluginConfig]{
+			Name:   "houdini-svelte",
+			Config: config.PluginConfig{StoreMode: config.StoreModeRunes},
+		},
+		Schema: `
+			type Query {
+				usersByCursor(first: Int, after: String): UserConnection!
+				node(id: ID!): Node
+			}
+
+			type Mutation {
+				addUser(name: String!): User!
+			}
+
+			type Subscription {
+				newUser: User!
+			}
+
+			interface Node {
+				id: ID!
+			}
+
+			type User implements Node {
+				id: ID!
+				name: String!
+				friendsList(limit: Int, offset: Int): [User!]!
+			}
+
+			type UserConnection {
+				edges: [UserEdge!]!
+				pageInfo: PageInfo!
+			}
+
+			type UserEdge {
+				node: User!
+				cursor: String!
+			}
+
+			type PageInfo {
+				hasNextPage: Boolean!
+				hasPreviousPage: Boolean!
+				startCursor: String
+				endCursor: String
+			}
+		`,
+		PerformTest: func(t *testing.T, p *plugin.HoudiniSvelte, test tests.Test[config.PluginConfig]) {
+			ctx := context.Background()
+			projectConfig, err := p.DB.ProjectConfig(ctx)
+			require.NoError(t, err)
+
+			_, err = generate.GenerateStores(ctx, p.DB, p.Fs)
+			require.NoError(t, err)
+
+			for name, expected := range test.Extra {
+				storePath := filepath.Join(projectConfig.PluginDirectory("houdini-svelte"), "stores", name+".ts")
+				contents, err := afero.ReadFile(p.Fs, storePath)
+				require.NoError(t, err)
+				require.Equal(t, expected, string(contents))
+			}
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "every document extends a runes class",
+				Pass: true,
+				Input: []string{
+					`query AllUsers {
+						usersByCursor(first: 10) @paginate {
+							edges { node { id } }
+						}
+					}`,
+					`mutation AddUser { addUser(name: "a") { id } }`,
+					`subscription NewUser { newUser { id } }`,
+					`fragment UserInfo on User { name }`,
+					`fragment UserFriends on User {
+						friendsList(limit: 10) @paginate { id }
+					}`,
+				},
+				Extra: map[string]any{
+					"AllUsers": tests.Dedent(`
+						import type { QueryStoreFetchParams } from '$houdini'
+						import { QueryRunesCursor } from '$houdini/plugins/houdini-svelte/runtime/runes/query.js'
+						import artifact from '$houdini/artifacts/AllUsers.js'
+						import type { AllUsers$result, AllUsers$input } from '$houdini/artifacts/AllUsers.js'
+
+						export class AllUsersStore extends QueryRunesCursor<AllUsers$result, AllUsers$input, typeof artifact> {
+						    constructor() {
+						        super({
+						            artifact,
+						            storeName: "AllUsersStore",
+						            variables: false,
+						        })
+						    }
+						}
+
+						export async function load_AllUsers(params: QueryStoreFetchParams<AllUsers$result, AllUsers$input>): Promise<{AllUsers: AllUsersStore}>{
+						    const store = new AllUsersStore()
+						    await store.fetch(params)
+						    return { AllUsers: store }
+						}
+					`),
+					"AddUser": tests.Dedent(`
+						import artifact from '$houdini/artifacts/AddUser.js'
+						import type { AddUser$result, AddUser$input, AddUser$optimistic } from '$houdini/artifacts/AddUser.js'
+						import { MutationRunes } from '$houdini/plugins/houdini-svelte/runtime/runes/mutation.js'
+
+						export class AddUserStore extends MutationRunes<AddUser$result, AddUser$input, AddUser$optimistic> {
+						    constructor() {
+						        super({
+						            artifact,
+						        })
+						    }
+						}
+					`),
+					"NewUser": tests.Dedent(`
+						import artifact from '$houdini/artifacts/NewUser.js'
+						import type { NewUser$result, NewUser$input }from '$houdini/artifacts/NewUser.js'
+						import { SubscriptionRunes } from '$houdini/plugins/houdini-svelte/runtime/runes/subscription.js'
+
+						export class NewUserStore extends SubscriptionRunes<NewUser$result, NewUser$input> {
+						    constructor() {
+						        super({
+						            artifact,
+						        })
+						    }
+						}
+					`),
+					"UserInfo": tests.Dedent(`
+						import { FragmentRunes } from '$houdini/plugins/houdini-svelte/runtime/runes/fragment.js'
+						import artifact from '$houdini/artifacts/UserInfo.js'
+						import type { UserInfo, UserInfo$data, UserInfo$input } from '$houdini/artifacts/UserInfo.js'
+
+						export type { UserInfo }
+
+						export class UserInfoStore extends FragmentRunes<UserInfo$data, { UserInfo: any }, UserInfo$input, typeof artifact> {
+						    constructor() {
+						        super({
+						            artifact,
+						            storeName: "UserInfoStore",
+						        })
+						    }
+						}
+					`),
+					"UserFriends": tests.Dedent(`
+						import { FragmentRunesOffset } from '$houdini/plugins/houdini-svelte/runtime/runes/fragment.js'
+						import artifact from '$houdini/artifacts/UserFriends.js'
+						import type { UserFriends, UserFriends$data, UserFriends$input } from '$houdini/artifacts/UserFriends.js'
+						import _PaginationArtifact from '$houdini/artifacts/UserFriends_Pagination_Query.js'
+
+						export type { UserFriends }
+
+						export class UserFriendsStore extends FragmentRunesOffset<UserFriends$data, { UserFriends: any }, UserFriends$input, typeof artifact> {
+						    constructor() {
+						        super({
+						            artifact,
+						            storeName: "UserFriendsStore",
+						            variables: true,
+						            paginationArtifact: _PaginationArtifact,
+						        })
+						    }
+						}
+					`),
+				},
+			},
+		},
+	})
+}
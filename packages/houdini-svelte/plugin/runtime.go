@@ -8,6 +8,7 @@ import (
 
 	"code.houdinigraphql.com/packages/houdini-svelte/plugin/config"
 	"code.houdinigraphql.com/packages/houdini-svelte/plugin/generate"
+	"code.houdinigraphql.com/plugins"
 	"github.com/spf13/afero"
 )
 
@@ -105,6 +106,35 @@ func (p *HoudiniSvelte) GenerateRuntime(ctx context.Context) ([]string, error) {
 	// keep the slice of files up to date
 	files = append(files, storeFiles...)
 
+	// the keys that @session mutations write to the session
+	projectConfig, err := p.DB.ProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessionType, err := plugins.GenerateSessionType(
+		ctx,
+		p.DB,
+		p.Filesystem(),
+		filepath.Join(projectConfig.PluginRuntimeDirectory(p.Name()), "sessionType.ts"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, sessionType...)
+
+	// kit projects get a load function for every route with a colocated query
+	pluginConfig, err := p.DB.PluginConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pluginConfig.Framework == config.PluginFrameworkKit {
+		routeFiles, err := generate.GenerateRoutes(ctx, p.Database(), p.Filesystem())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, routeFiles...)
+	}
+
 	// we're done
 	return files, nil
 }
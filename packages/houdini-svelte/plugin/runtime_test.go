@@ -66,3 +66,93 @@ export function graphql<_Payload, _Result = _Payload>(str: string): _Result;
 		},
 	})
 }
+
+func TestRuntime_sessionType(t *testing.T) {
+	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniSvelte]{
+		Plugin: tests.Plugin[config.PluginConfig]{
+			Name:   "houdini-svelte",
+			Config: config.PluginConfig{Framework: config.PluginFrameworkSvelte},
+		},
+		Schema: `
+			type Query { viewer: User }
+			type Mutation {
+				login: LoginOutput!
+				setTheme(theme: String!): Preferences
+			}
+			type LoginOutput { session: Session }
+			type Session { user: User! token: String! }
+			type Preferences { theme: String }
+			type User { id: ID! name: String! }
+		`,
+		SetupTest: func(t *testing.T, plugin *plugin.HoudiniSvelte, test tests.Test[config.PluginConfig]) {
+			config, err := plugin.DB.ProjectConfig(context.Background())
+			require.NoError(t, err)
+
+			// the runtime index that GenerateRuntime adds the graphql overloads to
+			require.NoError(t, afero.WriteFile(
+				plugin.Fs,
+				filepath.Join(config.ProjectRoot, config.RuntimeDir, "runtime", "index.ts"),
+				[]byte("export function graphql<_Payload, _Result = _Payload>(str: string): _Result;\n"),
+				0644,
+			))
+		},
+		Tests: []tests.Test[config.PluginConfig]{
+			{
+				Name: "no session mutations",
+				Pass: true,
+				Input: []string{
+					`query Viewer { viewer { id } }`,
+				},
+				Extra: map[string]any{
+					"expected": tests.Dedent(`
+						// this file is generated by houdini — do not edit
+
+						// no mutation writes to the session with @session
+						export type Session = {}
+					`),
+				},
+			},
+			{
+				Name: "replacing and merging mutations",
+				Pass: true,
+				Input: []string{
+					`mutation Login @session(path: "login.session") { login { session { user { id name } token } } }`,
+					`mutation SetTheme($theme: String!) @session(path: "setTheme", merge: true) { setTheme(theme: $theme) { theme } }`,
+				},
+				Extra: map[string]any{
+					"expected": tests.Dedent(`
+						// this file is generated by houdini — do not edit
+						import type { Login$result } from '$houdini/artifacts/Login'
+						import type { SetTheme$result } from '$houdini/artifacts/SetTheme'
+
+						// Login replaces the session
+						type LoginSession = NonNullable<NonNullable<Login$result['login']>['session']>
+						// SetTheme merges into the session
+						type SetThemeSession = NonNullable<SetTheme$result['setTheme']>
+
+						// Session holds every key a @session mutation can write. App.Session is an interface that
+						// extends it and an interface can only extend an object type, so Session can't be a union of
+						// the shape each mutation leaves behind. A mutation that replaces the session drops the keys
+						// it doesn't write and the session starts out empty, so every key is optional.
+						export type Session = {
+							theme?: SetThemeSession['theme']
+							token?: LoginSession['token']
+							user?: LoginSession['user']
+						}
+					`),
+				},
+			},
+		},
+		VerifyTest: func(t *testing.T, plugin *plugin.HoudiniSvelte, test tests.Test[config.PluginConfig]) {
+			config, err := plugin.DB.ProjectConfig(context.Background())
+			require.NoError(t, err)
+
+			content, err := afero.ReadFile(
+				plugin.Fs,
+				filepath.Join(config.PluginRuntimeDirectory(plugin.Name()), "sessionType.ts"),
+			)
+			require.NoError(t, err)
+			require.Equal(t, test.Extra["expected"].(string)+"\n", string(content))
+		},
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/config"
+	"code.houdinigraphql.com/packages/houdini-svelte/plugin/generate"
 	"code.houdinigraphql.com/plugins"
 )
 
@@ -66,6 +68,32 @@ func (p *HoudiniSvelte) Validate(ctx context.Context) error {
 		return err
 	}
 
+	pluginConfig, err := p.DB.PluginConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch pluginConfig.StoreMode {
+	case "", config.StoreModeStores, config.StoreModeRunes:
+	default:
+		errs.Append(&plugins.Error{
+			Message: fmt.Sprintf("unknown store mode %q", pluginConfig.StoreMode),
+			Detail: fmt.Sprintf(
+				`storeMode in the houdini-svelte config must be "%s" or "%s"`,
+				config.StoreModeStores, config.StoreModeRunes,
+			),
+			Kind: plugins.ErrorKindValidation,
+		})
+	}
+
+	// kit projects need every route query to get its variables from the route
+	if pluginConfig.Framework == config.PluginFrameworkKit {
+		_, err := generate.LoadRoutes(ctx, p.DB, errs)
+		if err != nil {
+			return err
+		}
+	}
+
 	if errs.Len() > 0 {
 		return errs
 	}
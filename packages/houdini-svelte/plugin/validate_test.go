@@ -14,8 +14,12 @@ import (
 
 func TestValidate_svelte(t *testing.T) {
 	tests.RunTable(t, tests.Table[config.PluginConfig, *plugin.HoudiniSvelte]{
+		Plugin: tests.Plugin[config.PluginConfig]{
+			Name:   "houdini-svelte",
+			Config: config.PluginConfig{Framework: config.PluginFrameworkKit},
+		},
 		Schema: `
-			type Query { hello: String }
+			type Query { hello: String, greeting(name: String!): String }
 			type Mutation { hello: String }
 			type Subscription { hello: String }
 		`,
@@ -110,6 +114,26 @@ func TestValidate_svelte(t *testing.T) {
 					`,
 				},
 			},
+			{
+				Name:      "route query variables come from the route",
+				Pass:      true,
+				Filepaths: []string{"src/routes/hello/[name]/+page.gql"},
+				Input: []string{
+					`
+						query Greeting($name: String!) { greeting(name: $name) }
+					`,
+				},
+			},
+			{
+				Name:      "route query with an unprovided variable",
+				Pass:      false,
+				Filepaths: []string{"src/routes/hello/+page.gql"},
+				Input: []string{
+					`
+						query Greeting($name: String!) { greeting(name: $name) }
+					`,
+				},
+			},
 		},
 	})
 }
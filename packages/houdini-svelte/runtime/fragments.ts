@@ -67,6 +67,17 @@ export function fragment<_Data extends GraphQLObject>(
 	// Fragment<_Data> structurally satisfies the { [fragmentKey]: _ReferenceType } branch at runtime.
 	const fragmentStore = store.get(ref)
 
+	// runes documents aren't stores: their handle holds the value in current, a getter that
+	// spreading the handle doesn't copy
+	const current = Object.getOwnPropertyDescriptor(fragmentStore, 'current')
+	if (current) {
+		return Object.defineProperty(
+			{ ...fragmentStore, artifact: store.artifact },
+			'current',
+			current
+		)
+	}
+
 	return {
 		...fragmentStore,
 		artifact: store.artifact,
@@ -1,12 +1,15 @@
+import type { QueryRunes } from './runes/index.js'
 import type { QueryStore } from './stores/index.js'
 
 export * from './adapter.js'
 export * from './stores/index.js'
+export * from './runes/index.js'
 export * from './fragments.js'
 export * from './types.js'
 export * from './session.js'
+export type { Session } from './sessionType.js'
 
-type LoadResult = Promise<{ [key: string]: QueryStore<any, any> }>
+type LoadResult = Promise<{ [key: string]: QueryStore<any, any> | QueryRunes<any, any> }>
 type LoadAllInput = LoadResult | Record<string, LoadResult>
 
 // gets all the values from an object
@@ -46,7 +49,7 @@ export async function loadAll<
 // its really the only thing from lib that users should import so it makes sense to have it here....
 export async function loadAll(
 	...loads: LoadAllInput[]
-): Promise<Record<string, QueryStore<any, any>>> {
+): Promise<Record<string, QueryStore<any, any> | QueryRunes<any, any>>> {
 	// we need to collect all of the promises in a single list that we will await in promise.all and then build up
 	const promises: LoadResult[] = []
 
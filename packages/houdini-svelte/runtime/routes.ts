@@ -0,0 +1,22 @@
+// routeVariable turns a route or search param into the value of a query variable.
+// The generated load functions call it for every variable they read from the url.
+export function routeVariable(
+	value: string | null | undefined,
+	kind: 'Int' | 'Float' | 'Boolean' | 'String'
+): string | number | boolean | null | undefined {
+	// missing values are left to the query's defaults
+	if (value === null || value === undefined) {
+		return undefined
+	}
+
+	switch (kind) {
+		case 'Int':
+			return parseInt(value, 10)
+		case 'Float':
+			return parseFloat(value)
+		case 'Boolean':
+			return value === 'true'
+		default:
+			return value
+	}
+}
@@ -0,0 +1,129 @@
+import type { FragmentArtifact, GraphQLObject, GraphQLVariables } from 'houdini/runtime'
+import { CompiledFragmentKind, fragmentKey } from 'houdini/runtime'
+
+import type { FragmentStoreInstance } from '../types.js'
+import { FragmentStore } from '../stores/fragment.js'
+import { FragmentStoreCursor, FragmentStoreOffset } from '../stores/pagination/fragment.js'
+import { FragmentStoreRefetchable } from '../stores/refetchable.js'
+import { handle } from './state.svelte.js'
+import type { Handle } from './state.svelte.js'
+
+// a fragment document is shared by every object the fragment is mixed into so get returns a
+// handle for one of them, holding its value in $state (current). Reading the fragment out of
+// the cache is left to the store version so both modes behave the same.
+class BaseFragmentRunes<
+	_Artifact extends FragmentArtifact,
+	_Store extends { artifact: _Artifact; name: string },
+> {
+	kind = CompiledFragmentKind
+
+	protected store: _Store
+
+	constructor(store: _Store) {
+		this.store = store
+	}
+
+	get artifact(): _Artifact {
+		return this.store.artifact
+	}
+
+	get name() {
+		return this.store.name
+	}
+}
+
+export class FragmentRunes<
+	_Data extends GraphQLObject,
+	_ReferenceType extends {},
+	_Input extends GraphQLVariables = GraphQLVariables,
+	_Artifact extends FragmentArtifact = FragmentArtifact,
+> extends BaseFragmentRunes<_Artifact, FragmentStore<_Data, _ReferenceType, _Input, _Artifact>> {
+	constructor(config: { artifact: _Artifact; storeName: string }) {
+		super(new FragmentStore(config))
+	}
+
+	get(
+		initialValue: ReadonlyArray<_Data | { [fragmentKey]: _ReferenceType }> | null
+	): Handle<FragmentStoreInstance<_Data[] | null, _Input> & { initialValue: _Data[] | null }>
+	get(
+		initialValue: _Data | { [fragmentKey]: _ReferenceType } | null
+	): Handle<FragmentStoreInstance<_Data | null, _Input> & { initialValue: _Data | null }>
+	get(initialValue: any): any {
+		return handle(this.store.get(initialValue))
+	}
+}
+
+export class FragmentRunesCursor<
+	_Data extends GraphQLObject,
+	_ReferenceType extends {},
+	_Input extends GraphQLVariables,
+	_Artifact extends FragmentArtifact = FragmentArtifact,
+> extends BaseFragmentRunes<
+	_Artifact,
+	FragmentStoreCursor<_Data, _ReferenceType, _Input, _Artifact>
+> {
+	// all paginated documents need to have a flag to distinguish from other fragments
+	paginated = true
+
+	constructor(
+		config: ConstructorParameters<
+			typeof FragmentStoreCursor<_Data, _ReferenceType, _Input, _Artifact>
+		>[0]
+	) {
+		super(new FragmentStoreCursor(config))
+	}
+
+	get(initialValue: _Data | { [fragmentKey]: _ReferenceType } | null) {
+		return handle(this.store.get(initialValue))
+	}
+}
+
+export class FragmentRunesOffset<
+	_Data extends GraphQLObject,
+	_ReferenceType extends {},
+	_Input extends GraphQLVariables,
+	_Artifact extends FragmentArtifact = FragmentArtifact,
+> extends BaseFragmentRunes<
+	_Artifact,
+	FragmentStoreOffset<_Data, _ReferenceType, _Input, _Artifact>
+> {
+	// all paginated documents need to have a flag to distinguish from other fragments
+	paginated = true
+
+	constructor(
+		config: ConstructorParameters<
+			typeof FragmentStoreOffset<_Data, _ReferenceType, _Input, _Artifact>
+		>[0]
+	) {
+		super(new FragmentStoreOffset(config))
+	}
+
+	get(initialValue: _Data | null) {
+		return handle(this.store.get(initialValue))
+	}
+}
+
+export class FragmentRunesRefetchable<
+	_Data extends GraphQLObject,
+	_ReferenceType extends {},
+	_Input extends GraphQLVariables,
+	_Artifact extends FragmentArtifact = FragmentArtifact,
+> extends BaseFragmentRunes<
+	_Artifact,
+	FragmentStoreRefetchable<_Data, _ReferenceType, _Input, _Artifact>
+> {
+	// a flag the refetchableFragment() helper looks for to validate the document
+	refetchable = true
+
+	constructor(
+		config: ConstructorParameters<
+			typeof FragmentStoreRefetchable<_Data, _ReferenceType, _Input, _Artifact>
+		>[0]
+	) {
+		super(new FragmentStoreRefetchable(config))
+	}
+
+	get(initialValue: _Data | { [fragmentKey]: _ReferenceType } | null) {
+		return handle(this.store.get(initialValue))
+	}
+}
@@ -0,0 +1,6 @@
+export { FragmentRunes, FragmentRunesCursor, FragmentRunesOffset, FragmentRunesRefetchable } from './fragment.js'
+export { MutationRunes } from './mutation.js'
+export { QueryRunes, QueryRunesCursor, QueryRunesOffset } from './query.js'
+export { SubscriptionRunes } from './subscription.js'
+export { DocumentState } from './state.svelte.js'
+export type { Handle } from './state.svelte.js'
@@ -0,0 +1,45 @@
+import type {
+	GraphQLObject,
+	GraphQLVariables,
+	MutationArtifact,
+	QueryResult,
+} from 'houdini/runtime'
+
+import { MutationStore } from '../stores/mutation.js'
+import { DocumentState } from './state.svelte.js'
+
+// MutationRunes holds the latest result of a mutation in $state (current). Sending the
+// mutation is left to the store version so both modes behave the same.
+export class MutationRunes<
+	_Data extends GraphQLObject,
+	_Input extends GraphQLVariables | undefined | null,
+	_Optimistic extends GraphQLObject,
+> {
+	kind = 'HoudiniMutation' as const
+
+	#store: MutationStore<_Data, _Input, _Optimistic>
+	#state: DocumentState<QueryResult<_Data, _Input>>
+
+	constructor({ artifact }: { artifact: MutationArtifact }) {
+		this.#store = new MutationStore({ artifact })
+		this.#state = new DocumentState(this.#store)
+	}
+
+	get artifact() {
+		return this.#store.artifact
+	}
+
+	get name() {
+		return this.#store.name
+	}
+
+	get current(): QueryResult<_Data, _Input> {
+		return this.#state.current
+	}
+
+	mutate(
+		...args: Parameters<MutationStore<_Data, _Input, _Optimistic>['mutate']>
+	): Promise<QueryResult<_Data, _Input>> {
+		return this.#store.mutate(...args)
+	}
+}
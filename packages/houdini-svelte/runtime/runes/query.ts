@@ -0,0 +1,141 @@
+import type {
+	GraphQLObject,
+	GraphQLVariables,
+	QueryArtifact,
+	QueryResult,
+} from 'houdini/runtime'
+import { CompiledQueryKind } from 'houdini/runtime'
+
+import { QueryStoreCursor, QueryStoreOffset } from '../stores/pagination/query.js'
+import type { CursorStoreResult } from '../stores/pagination/query.js'
+import { QueryStore } from '../stores/query.js'
+import type { StoreConfig } from '../stores/query.js'
+import type {
+	ClientFetchParams,
+	LoadEventFetchParams,
+	QueryStoreFetchParams,
+	RequestEventFetchParams,
+} from '../types.js'
+import { DocumentState } from './state.svelte.js'
+
+// a runes query isn't a store: its latest result lives in $state and is read from current.
+// Sending the query is left to a store that the document holds onto so both modes load
+// their data the same way.
+class BaseQueryRunes<
+	_Data extends GraphQLObject,
+	_Input extends GraphQLVariables | null | undefined,
+	_Artifact extends QueryArtifact,
+	_Store extends QueryStore<_Data, _Input, _Artifact>,
+	_Result,
+> {
+	// identify it as a query document
+	kind = CompiledQueryKind
+
+	protected store: _Store
+	#state: DocumentState<_Result>
+
+	constructor(store: _Store) {
+		this.store = store
+		this.#state = new DocumentState<_Result>(store as any)
+	}
+
+	get artifact(): _Artifact {
+		return this.store.artifact
+	}
+
+	get name() {
+		return this.store.name
+	}
+
+	// whether the query requires variables for input
+	get variables() {
+		return this.store.variables
+	}
+
+	get current(): _Result {
+		return this.#state.current
+	}
+
+	/**
+	 * Fetch the data from the server
+	 */
+	fetch(params?: RequestEventFetchParams<_Data, _Input>): Promise<QueryResult<_Data, _Input>>
+	fetch(params?: LoadEventFetchParams<_Data, _Input>): Promise<QueryResult<_Data, _Input>>
+	fetch(params?: ClientFetchParams<_Data, _Input>): Promise<QueryResult<_Data, _Input>>
+	fetch(params?: QueryStoreFetchParams<_Data, _Input>): Promise<QueryResult<_Data, _Input>>
+	fetch(params?: QueryStoreFetchParams<_Data, _Input>): Promise<QueryResult<_Data, _Input>> {
+		return this.store.fetch(params)
+	}
+}
+
+export class QueryRunes<
+	_Data extends GraphQLObject,
+	_Input extends GraphQLVariables | null | undefined,
+	_Artifact extends QueryArtifact = QueryArtifact,
+> extends BaseQueryRunes<
+	_Data,
+	_Input,
+	_Artifact,
+	QueryStore<_Data, _Input, _Artifact>,
+	QueryResult<_Data, _Input>
+> {
+	constructor(config: StoreConfig<_Data, _Input, _Artifact>) {
+		super(new QueryStore(config))
+	}
+}
+
+export class QueryRunesCursor<
+	_Data extends GraphQLObject,
+	_Input extends GraphQLVariables | null | undefined,
+	_Artifact extends QueryArtifact = QueryArtifact,
+> extends BaseQueryRunes<
+	_Data,
+	_Input,
+	_Artifact,
+	QueryStoreCursor<_Data, _Input, _Artifact>,
+	CursorStoreResult<_Data, _Input>
+> {
+	// all paginated documents need to have a flag to distinguish from other queries
+	paginated = true
+
+	constructor(config: StoreConfig<_Data, _Input, _Artifact>) {
+		super(new QueryStoreCursor(config))
+	}
+
+	loadPreviousPage(
+		...args: Parameters<QueryStoreCursor<_Data, _Input, _Artifact>['loadPreviousPage']>
+	) {
+		return this.store.loadPreviousPage(...args)
+	}
+
+	loadNextPage(
+		...args: Parameters<QueryStoreCursor<_Data, _Input, _Artifact>['loadNextPage']>
+	) {
+		return this.store.loadNextPage(...args)
+	}
+}
+
+export class QueryRunesOffset<
+	_Data extends GraphQLObject,
+	_Input extends GraphQLVariables | null | undefined,
+	_Artifact extends QueryArtifact = QueryArtifact,
+> extends BaseQueryRunes<
+	_Data,
+	_Input,
+	_Artifact,
+	QueryStoreOffset<_Data, _Input, _Artifact>,
+	QueryResult<_Data, _Input>
+> {
+	// all paginated documents need to have a flag to distinguish from other queries
+	paginated = true
+
+	constructor(config: StoreConfig<_Data, _Input, _Artifact>) {
+		super(new QueryStoreOffset(config))
+	}
+
+	loadNextPage(
+		...args: Parameters<QueryStoreOffset<_Data, _Input, _Artifact>['loadNextPage']>
+	) {
+		return this.store.loadNextPage(...args)
+	}
+}
@@ -0,0 +1,69 @@
+import { createSubscriber } from 'svelte/reactivity'
+
+// Observable is anything that pushes the latest value of a document to its subscribers
+// (a houdini document observer or one of the instances a fragment's get returns)
+export type Observable<_Value> = {
+	subscribe(run: (value: _Value) => void, ...args: any[]): () => void
+}
+
+// the value an observable pushes
+export type ObservableValue<_Observable> =
+	_Observable extends Observable<infer _Value> ? _Value : never
+
+// DocumentState holds the latest value of a document in $state. The document is only observed
+// while something reactive reads the value so it still stops listening to the cache when the
+// last component using it is destroyed.
+export class DocumentState<_Value> {
+	#document: Observable<_Value>
+	#value = $state.raw<_Value>()
+	#listening = false
+	#listen: () => void
+
+	constructor(document: Observable<_Value>) {
+		this.#document = document
+		this.#listen = createSubscriber(() => {
+			this.#listening = true
+			const stop = document.subscribe((value) => {
+				this.#value = value
+			})
+
+			return () => {
+				this.#listening = false
+				stop()
+			}
+		})
+	}
+
+	get current(): _Value {
+		if ($effect.tracking()) {
+			this.#listen()
+		} else if (!this.#listening) {
+			// nothing keeps the value up to date outside of an effect so we read it once
+			this.#document.subscribe((value) => {
+				this.#value = value
+			})()
+		}
+
+		return this.#value as _Value
+	}
+}
+
+// Handle is what a runes fragment's get returns: the methods of the instance along with its
+// value in current. The readable fields of the store version are part of current.
+export type Handle<_Instance extends Observable<any>> = Omit<
+	_Instance,
+	'subscribe' | 'data' | 'fetching'
+> & {
+	readonly current: ObservableValue<_Instance>
+}
+
+// handle turns an instance returned by a fragment store's get into a runes handle
+export function handle<_Instance extends Observable<any>>(instance: _Instance): Handle<_Instance> {
+	const { subscribe, data, fetching, ...methods } = instance as any
+	const state = new DocumentState<ObservableValue<_Instance>>(instance)
+
+	// current isn't enumerable so that spreading the handle doesn't read (and observe) it
+	return Object.defineProperty(methods, 'current', {
+		get: () => state.current,
+	}) as Handle<_Instance>
+}
@@ -0,0 +1,47 @@
+import type {
+	GraphQLObject,
+	GraphQLVariables,
+	QueryResult,
+	SubscriptionArtifact,
+} from 'houdini/runtime'
+import { CompiledSubscriptionKind } from 'houdini/runtime'
+
+import { SubscriptionStore } from '../stores/subscription.js'
+import { DocumentState } from './state.svelte.js'
+
+// SubscriptionRunes holds the latest payload of a subscription in $state (current). Listening
+// is left to the store version so both modes behave the same.
+export class SubscriptionRunes<
+	_Data extends GraphQLObject,
+	_Input extends GraphQLVariables | null | undefined,
+> {
+	kind = CompiledSubscriptionKind
+
+	#store: SubscriptionStore<_Data, _Input>
+	#state: DocumentState<QueryResult<_Data, _Input>>
+
+	constructor({ artifact }: { artifact: SubscriptionArtifact }) {
+		this.#store = new SubscriptionStore({ artifact })
+		this.#state = new DocumentState(this.#store)
+	}
+
+	get artifact() {
+		return this.#store.artifact
+	}
+
+	get name() {
+		return this.#store.name
+	}
+
+	get current(): QueryResult<_Data, _Input> {
+		return this.#state.current
+	}
+
+	listen(...args: Parameters<SubscriptionStore<_Data, _Input>['listen']>) {
+		return this.#store.listen(...args)
+	}
+
+	unlisten() {
+		return this.#store.unlisten()
+	}
+}
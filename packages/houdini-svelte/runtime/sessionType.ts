@@ -0,0 +1,2 @@
+// this file will get replaced by the build system with the keys that @session mutations write
+export type Session = {}
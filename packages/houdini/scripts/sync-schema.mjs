@@ -2,15 +2,20 @@
 //
 // The canonical schema is `create_schema` in src/lib/database.ts (node is the
 // authority since it's what actually runs in production). This script extracts
-// it verbatim into plugins/tests/schema.sql, which the Go test harness embeds.
-// Run it whenever create_schema changes; a vitest guards against drift.
+// it verbatim into plugins/schema.sql, which the Go plugins embed to build the database
+// when they run without the orchestrator (and the test harness uses for its fixtures).
+// The `migrations` next to it are written to plugins/migrations, one file per
+// version, which the Go plugins embed to check and upgrade the database.
+// Run it whenever either changes; a vitest guards against drift.
 import fs from 'node:fs'
 import path from 'node:path'
 import { fileURLToPath } from 'node:url'
 
 const here = path.dirname(fileURLToPath(import.meta.url))
+const root = path.join(here, '../../..')
 const dbTs = path.join(here, '../src/lib/database.ts')
-const out = path.join(here, '../../../plugins/tests/schema.sql')
+const out = path.join(root, 'plugins/schema.sql')
+const migrationsDir = path.join(root, 'plugins/migrations')
 
 const src = fs.readFileSync(dbTs, 'utf-8')
 const match = src.match(/create_schema = `([\s\S]*?)`/)
@@ -19,4 +24,25 @@ if (!match) {
 }
 
 fs.writeFileSync(out, match[1])
-console.log('wrote', path.relative(path.join(here, '../../..'), out))
+console.log('wrote', path.relative(root, out))
+
+const list = src.match(/export const migrations: Array<Migration> = \[([\s\S]*?)\n\]/)
+if (!list) {
+	throw new Error('could not find the `migrations` list in database.ts')
+}
+
+// replace every migration file so removed and renamed ones don't linger
+fs.rmSync(migrationsDir, { recursive: true, force: true })
+fs.mkdirSync(migrationsDir, { recursive: true })
+for (const [, version, description, sql] of list[1].matchAll(
+	/version: (\d+),\s*description: '([^']*)',\s*sql: `([\s\S]*?)`/g
+)) {
+	const file = path.join(migrationsDir, migration_filename(Number(version), description))
+	fs.writeFileSync(file, `-- ${description}\n${sql}`)
+	console.log('wrote', path.relative(root, file))
+}
+
+// src/lib/schema.test.ts expects the same layout
+function migration_filename(version, description) {
+	return `${String(version).padStart(4, '0')}_${description.replace(/[^a-z0-9]+/gi, '_')}.sql`
+}
@@ -0,0 +1,26 @@
+import { spawn } from 'node:child_process'
+
+import { get_config } from '../lib/project.js'
+import { db_path } from '../router/conventions.js'
+
+export default async function (args: { json?: boolean; preload?: string }) {
+	const config = await get_config({ skip_schema: true })
+
+	// the analysis is done by houdini-react from the database that generate leaves behind
+	const react = config.plugins.find((plugin) => plugin.name === 'houdini-react')
+	if (!react) {
+		console.log('❌ Could not find houdini-react. Analyzing routes is only supported for react projects.')
+		process.exit(1)
+	}
+
+	const cmd_args = ['analyze-routes', '-database', db_path(config)]
+	if (args.json) {
+		cmd_args.push('-json')
+	}
+	if (args.preload) {
+		cmd_args.push('-preload', args.preload)
+	}
+
+	const child = spawn(react.executable, cmd_args, { stdio: 'inherit' })
+	child.on('exit', (code) => process.exit(code ?? 1))
+}
@@ -0,0 +1,23 @@
+import { spawn } from 'node:child_process'
+
+import { get_config } from '../lib/project.js'
+import { db_path } from '../router/conventions.js'
+
+export default async function (args: { json?: boolean }) {
+	const config = await get_config({ skip_schema: true })
+
+	// the report is computed by houdini-core from the database that generate leaves behind
+	const core = config.plugins.find((plugin) => plugin.name === 'houdini-core')
+	if (!core) {
+		console.log('❌ Could not find houdini-core.')
+		process.exit(1)
+	}
+
+	const cmd_args = ['dead-fields', '-database', db_path(config)]
+	if (args.json) {
+		cmd_args.push('-json')
+	}
+
+	const child = spawn(core.executable, cmd_args, { stdio: 'inherit' })
+	child.on('exit', (code) => process.exit(code ?? 1))
+}
@@ -0,0 +1,29 @@
+import { spawn } from 'node:child_process'
+
+import { get_config } from '../lib/project.js'
+import { db_path } from '../router/conventions.js'
+
+export default async function (args: { out?: string; format?: string; baseUrl?: string }) {
+	const config = await get_config({ skip_schema: true })
+
+	// the routes are exported by houdini-react from the database that generate leaves behind
+	const react = config.plugins.find((plugin) => plugin.name === 'houdini-react')
+	if (!react) {
+		console.log('❌ Could not find houdini-react. Exporting routes is only supported for react projects.')
+		process.exit(1)
+	}
+
+	const cmd_args = ['export-routes', '-database', db_path(config)]
+	if (args.out) {
+		cmd_args.push('-out', args.out)
+	}
+	if (args.format) {
+		cmd_args.push('-format', args.format)
+	}
+	if (args.baseUrl) {
+		cmd_args.push('-base-url', args.baseUrl)
+	}
+
+	const child = spawn(react.executable, cmd_args, { stdio: 'inherit' })
+	child.on('exit', (code) => process.exit(code ?? 1))
+}
@@ -1,7 +1,11 @@
 import { Command } from 'commander'
 import { yellow } from 'kleur/colors'
 import type { HoudiniError } from '../lib/error.js'
+import analyzeRoutes from './analyzeRoutes.js'
+import deadFields from './deadFields.js'
+import exportRoutes from './exportRoutes.js'
 import { generate } from './generate.js'
+import inspect from './inspect.js'
 import pullSchema from './pullSchema.js'
 
 // build up the cli
@@ -54,6 +58,45 @@ program
 	)
 	.action(pullSchema)
 
+// register the dead fields command
+program
+	.command('dead-fields')
+	.usage('[options]')
+	.description('list the schema fields, arguments, and enum values that no document uses')
+	.option('--json', 'print the report as json')
+	.action(deadFields)
+
+// register the inspect command
+program
+	.command('inspect <question> [subject]')
+	.usage('[options] <document|field|lists|selection> [subject]')
+	.description(
+		'answer questions about the project: where a document is defined (document <name>), ' +
+			'which documents select a field (field <Type.field>), the lists in the project (lists), ' +
+			'or the flattened selection of a document (selection <name>)'
+	)
+	.option('--json', 'print the answer as json')
+	.action(inspect)
+
+// register the export routes command
+program
+	.command('export-routes')
+	.usage('[options]')
+	.description('write a route manifest, prerender plan, and sitemap.xml for a react project')
+	.option('-o, --out [outDir]', 'the directory to write the files to (default: build/routes)')
+	.option('-f, --format [format]', 'the format of the route manifest: json or yaml (default: json)')
+	.option('--base-url [baseUrl]', 'the origin used for the urls in sitemap.xml')
+	.action(exportRoutes)
+
+// register the analyze routes command
+program
+	.command('analyze-routes')
+	.usage('[options]')
+	.description('find the queries a react route fetches in sequence or fetches more than once')
+	.option('--json', 'print the report as json')
+	.option('--preload [file]', 'write the preload plan of every route to a json file')
+	.action(analyzeRoutes)
+
 // start the command
 program.parse()
 
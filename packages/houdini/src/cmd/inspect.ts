@@ -0,0 +1,31 @@
+import { spawn } from 'node:child_process'
+
+import { get_config } from '../lib/project.js'
+import { db_path } from '../router/conventions.js'
+
+export default async function (
+	question: string,
+	subject: string | undefined,
+	args: { json?: boolean }
+) {
+	const config = await get_config({ skip_schema: true })
+
+	// the answers come from houdini-core which reads the database that generate leaves behind
+	const core = config.plugins.find((plugin) => plugin.name === 'houdini-core')
+	if (!core) {
+		console.log('❌ Could not find houdini-core.')
+		process.exit(1)
+	}
+
+	const cmd_args = ['inspect', '-database', db_path(config)]
+	if (args.json) {
+		cmd_args.push('-json')
+	}
+	cmd_args.push(question)
+	if (subject) {
+		cmd_args.push(subject)
+	}
+
+	const child = spawn(core.executable, cmd_args, { stdio: 'inherit' })
+	child.on('exit', (code) => process.exit(code ?? 1))
+}
@@ -61,12 +61,20 @@ process.exit(0);}
 
 import * as conventions from '../router/conventions.js'
 import type { Config } from './config.js'
-import { create_schema, schema_version, write_config } from './database.js'
+import {
+	create_schema,
+	migrate_db,
+	read_schema_version,
+	schema_version,
+	stamp_schema_version,
+	write_config,
+} from './database.js'
 import { type Db, openDb } from './db.js'
 import type { HookError } from './error.js'
 import { PluginHookError, format_hook_error } from './error.js'
 import * as fs from './fs.js'
 import { Logger } from './logger.js'
+import { plugin_version } from './plugins.js'
 import type { ProjectManifest } from './types.js'
 import { LogLevel } from './types.js'
 
@@ -106,29 +114,32 @@ export async function connect_db(config: Config, db_file?: string): Promise<[Db,
 	const filepath = db_file ?? conventions.db_path(config)
 	let db = await openDb(filepath)
 
-	// if a database persisted from an older compiler (eg. via --preserve-database
-	// or across WASM sessions), its schema may not match what we expect. we don't
-	// migrate — we detect the mismatch via the version stamp and rebuild from scratch.
-	// a fresh/empty database has no prior schema to be stale, so skip the rebuild
-	// there (avoids a redundant wipe + reopen on the common non-preserve path).
-	const stamped = db.get<{ user_version: number }>('PRAGMA user_version')?.user_version ?? 0
+	// a database persisted from an older compiler (eg. via --preserve-database, across WASM
+	// sessions, or the language server's) is brought up to date with the migrations it's
+	// missing. if we can't tell where it sits in the migration history, or a newer compiler
+	// wrote it, we rebuild it from scratch. a fresh/empty database has nothing to migrate.
 	const existing = db.get(`SELECT 1 FROM sqlite_master WHERE type = 'table' LIMIT 1`)
-	if (existing && stamped !== schema_version) {
-		db.close()
-		try {
-			await fs.remove(filepath)
-		} catch (_e) {}
-		try {
-			await fs.remove(`${filepath}-shm`)
-		} catch (_e) {}
-		try {
-			await fs.remove(`${filepath}-wal`)
-		} catch (_e) {}
-		db = await openDb(filepath)
+	if (existing) {
+		const version = read_schema_version(db)
+		if (version === null || version > schema_version) {
+			db.close()
+			try {
+				await fs.remove(filepath)
+			} catch (_e) {}
+			try {
+				await fs.remove(`${filepath}-shm`)
+			} catch (_e) {}
+			try {
+				await fs.remove(`${filepath}-wal`)
+			} catch (_e) {}
+			db = await openDb(filepath)
+		} else if (version < schema_version) {
+			migrate_db(db, version)
+		}
 	}
 
 	db.exec(create_schema)
-	db.exec(`PRAGMA user_version = ${schema_version}`)
+	stamp_schema_version(db)
 	db.flush()
 	return [db, filepath]
 }
@@ -251,10 +262,10 @@ export async function codegen_setup(
 						clearInterval(interval)
 						clearTimeout(timeout)
 
-						pollDb.run('UPDATE plugins SET config = ? WHERE name = ?', [
-							JSON.stringify(
-								config.plugins.find((p) => p.name === configKey)?.config ?? {}
-							),
+						const meta = config.plugins.find((p) => p.name === configKey)
+						pollDb.run('UPDATE plugins SET config = ?, version = ? WHERE name = ?', [
+							JSON.stringify(meta?.config ?? {}),
+							meta ? plugin_version(meta) : null,
 							dbKey,
 						])
 						pollDb.flush()
@@ -356,10 +367,10 @@ export async function codegen_setup(
 								msg.clientPlugins ?? null,
 							]
 						)
-						_db.run('UPDATE plugins SET config = ? WHERE name = ?', [
-							JSON.stringify(
-								config.plugins.find((p) => p.name === name)?.config ?? {}
-							),
+						const meta = config.plugins.find((p) => p.name === name)
+						_db.run('UPDATE plugins SET config = ?, version = ? WHERE name = ?', [
+							JSON.stringify(meta?.config ?? {}),
+							meta ? plugin_version(meta) : null,
 							spec.name,
 						])
 						_db.flush()
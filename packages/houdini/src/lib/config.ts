@@ -6,7 +6,7 @@ import type { OAuthProvider, OAuthUser, OAuthTokens } from '../oauth/index.js'
 import { plugin_dir } from '../router/conventions.js'
 import * as path from './path.js'
 import type { PluginMeta } from './project.js'
-import type { CachePolicies, PaginateModes } from './types.js'
+import type { CachePolicies, PaginateModes, SubscriptionTransports } from './types.js'
 
 // the values we can take in from the config file
 export type ConfigFile = {
@@ -52,6 +52,36 @@ export type ConfigFile = {
 		 * @default 400
 		 */
 		minDuration?: number
+		/**
+		 * Serve every page in more than one locale. The locale is passed to any route query
+		 * that declares the locale variable.
+		 */
+		i18n?: {
+			/**
+			 * Every locale the application ships in, e.g. ['en', 'fr']
+			 */
+			locales: string[]
+			/**
+			 * The locale served without a prefix (or on a domain not listed in `domains`).
+			 * @default the first entry of `locales`
+			 */
+			defaultLocale?: string
+			/**
+			 * `prefix` serves each locale under `/[locale]/...`. `domain` serves each locale from
+			 * the domain listed for it in `domains`.
+			 * @default 'prefix'
+			 */
+			strategy?: 'prefix' | 'domain'
+			/**
+			 * The host of each locale when using the `domain` strategy, e.g. { fr: 'example.fr' }
+			 */
+			domains?: Record<string, string>
+			/**
+			 * The name of the query variable that receives the locale.
+			 * @default 'locale'
+			 */
+			variable?: string
+		}
 	}
 
 	/**
@@ -126,6 +156,44 @@ export type ConfigFile = {
 	 */
 	defaultFragmentMasking?: 'enable' | 'disable'
 
+	/**
+	 * Report fragments that are never spread, operations in .graphql files that no other file
+	 * references, and fragments that are only spread from outside of their own directory tree.
+	 * Set to `warn` to log these findings or `error` to fail validation. Leave unset to skip the checks.
+	 */
+	documentUsage?: 'error' | 'warn'
+
+	/**
+	 * The protocol used to send subscriptions. `transport` applies to every subscription
+	 * and `operations` overrides it for specific documents (by name). A @transport directive
+	 * on the document takes precedence over both.
+	 */
+	subscriptions?: {
+		transport?: SubscriptionTransports
+		operations?: Record<string, SubscriptionTransports>
+	}
+
+	/**
+	 * The directory generated artifacts are cached in, relative to your project directory. Every
+	 * entry is keyed by a hash of everything the artifact depends on, so the directory can be
+	 * shared between machines (eg. restored in CI). Set to `false` to turn the cache off.
+	 * @default `node_modules/.cache/houdini`
+	 */
+	cacheDir?: string | false
+
+	/**
+	 * Skip the files that the ignore files (eg. `.gitignore`) in your project exclude when looking
+	 * for documents. Pass a list of names to read other files; `true` reads `.gitignore` and `.ignore`.
+	 * @default `false`
+	 */
+	readIgnoreFiles?: boolean | string[]
+
+	/**
+	 * Look for documents inside of the directories that symbolic links point to.
+	 * @default `false`
+	 */
+	followSymlinks?: boolean
+
 	/**
 	 * The URL the CLIENT sends GraphQL requests to. Set this when the API is REMOTE; the client
 	 * queries it directly and `@session` mutations are proxied through Houdini to it. It's public
@@ -260,9 +328,30 @@ export type TypeConfig = {
 			queryField: string
 			arguments?: (data: any) => { [key: string]: any }
 		}
+		/**
+		 * Cache rules for records of this type. The type-level values apply to every field
+		 * and `fields` overrides them for individual fields. A field's own @cache directive
+		 * takes precedence over both.
+		 */
+		cache?: CacheRules & {
+			fields?: { [fieldName: string]: CacheRules }
+		}
 	}
 }
 
+export type CacheRules = {
+	/**
+	 * The cache policy to use for the field. NetworkOnly and NoCache mean the cached value
+	 * is always considered stale.
+	 */
+	policy?: CachePolicies
+
+	/**
+	 * How long the value stays fresh in milliseconds. Overrides `defaultLifetime`.
+	 */
+	lifetime?: number
+}
+
 export type WatchSchemaConfig = {
 	/**
 	 * A url to use to pull the schema. For more information: https://www.houdinigraphql.com/api/cli#generate
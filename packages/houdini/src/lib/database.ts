@@ -9,6 +9,13 @@ import { default_config } from './project.js'
 import { LogLevel } from './types.js'
 
 export const create_schema = `
+-- the migrations that brought the database to its current version (see migrations below)
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER NOT NULL PRIMARY KEY,
+    description TEXT NOT NULL,
+    applied_at INTEGER NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS plugins (
     name TEXT NOT NULL PRIMARY KEY UNIQUE,
     port INTEGER NOT NULL,
@@ -18,7 +25,8 @@ CREATE TABLE IF NOT EXISTS plugins (
     include_static_runtime TEXT,
     config JSON,
 	  config_module TEXT,
-		client_plugins JSON
+		client_plugins JSON,
+    version TEXT
 );
 
 -- Watch Schema Config
@@ -36,7 +44,8 @@ CREATE TABLE IF NOT EXISTS router_config (
     session_keys TEXT NOT NULL UNIQUE,
     url TEXT,
     mutation TEXT UNIQUE,
-    providers TEXT
+    providers TEXT,
+    i18n JSON
 );
 
 -- Runtime Scalar Definition
@@ -78,7 +87,13 @@ CREATE TABLE IF NOT EXISTS config (
     persisted_queries_path TEXT NOT NULL,
     project_root TEXT,
     runtime_dir TEXT,
-		path TEXT
+		path TEXT,
+    document_usage TEXT CHECK (document_usage IS NULL OR document_usage IN ('error', 'warn')),
+    subscription_transport TEXT,
+    subscription_transports JSON,
+    cache_dir TEXT,
+    ignore_files JSON,
+    follow_symlinks BOOLEAN
 );
 
 CREATE TABLE IF NOT EXISTS scalar_config (
@@ -93,7 +108,10 @@ CREATE TABLE IF NOT EXISTS scalar_config (
 CREATE TABLE IF NOT EXISTS type_configs (
     name TEXT NOT NULL,
     keys JSON NOT NULL,
-	resolve_query TEXT
+	resolve_query TEXT,
+	cache_policy TEXT,
+	cache_lifetime INTEGER,
+	field_cache JSON
 );
 
 -- A table of original document contents (to be populated by plugins)
@@ -107,6 +125,18 @@ CREATE TABLE IF NOT EXISTS raw_documents (
     loaded_with TEXT
 );
 
+-- What every file looked like the last time its documents were extracted. Files whose mtime and
+-- size (or content hash) still match are skipped by the next walk. A row only counts when its
+-- version matches the extractor that reads it.
+CREATE TABLE IF NOT EXISTS file_fingerprints (
+    filepath TEXT NOT NULL PRIMARY KEY,
+    mtime INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    documents INTEGER NOT NULL,
+    version INTEGER NOT NULL
+);
+
 -----------------------------------------------------------
 -- Schema Definition Tables
 -----------------------------------------------------------
@@ -525,13 +555,104 @@ CREATE INDEX IF NOT EXISTS idx_argument_value_children_value ON argument_value_c
 CREATE INDEX IF NOT EXISTS idx_argument_value_children_document ON argument_value_children(document);
 `
 
-// schema_version is a checksum of create_schema, stamped into the database's
-// PRAGMA user_version. When the schema changes the checksum changes, so a
-// persisted database from an older compiler is detected as stale and rebuilt
-// from scratch (we deliberately don't migrate — see connect_db). The value is
-// masked to 31 bits to fit SQLite's signed 32-bit user_version field.
-export const schema_version =
-	parseInt(createHash('sha1').update(create_schema).digest('hex').slice(0, 8), 16) & 0x7fffffff
+export type Migration = {
+	version: number
+	description: string
+	sql: string
+}
+
+// migrations bring a database written by an older compiler up to date. They run in order and
+// each one is recorded in the schema_version table. create_schema always describes the latest
+// version so a fresh database never runs them, which means every change to create_schema needs
+// a migration here too. `pnpm sync-schema` copies them to plugins/migrations for the Go side.
+export const migrations: Array<Migration> = [
+	{
+		version: 1,
+		description: 'track the schema version',
+		sql: `
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER NOT NULL PRIMARY KEY,
+    description TEXT NOT NULL,
+    applied_at INTEGER NOT NULL
+);
+`,
+	},
+	{
+		version: 2,
+		description: 'read ignore files and follow symlinks',
+		sql: `
+ALTER TABLE config ADD COLUMN ignore_files JSON;
+ALTER TABLE config ADD COLUMN follow_symlinks BOOLEAN;
+`,
+	},
+	{
+		version: 3,
+		description: 'version file fingerprints',
+		sql: `
+DROP TABLE IF EXISTS file_fingerprints;
+CREATE TABLE file_fingerprints (
+    filepath TEXT NOT NULL PRIMARY KEY,
+    mtime INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    documents INTEGER NOT NULL,
+    version INTEGER NOT NULL
+);
+`,
+	},
+]
+
+// schema_version is the version of a database built by create_schema
+export const schema_version = migrations[migrations.length - 1].version
+
+// before the schema_version table existed, the compiler stamped a checksum of create_schema
+// into PRAGMA user_version. A database with this stamp is at version 0.
+export const unversioned_checksum = 0x6443be8e
+
+// read_schema_version returns the version of an existing database, or null when it was
+// written by a compiler that's too old to migrate from
+export function read_schema_version(db: Db): number | null {
+	const tracked = db.get(
+		`SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'`
+	)
+	if (tracked) {
+		return (
+			db.get<{ version: number | null }>('SELECT MAX(version) AS version FROM schema_version')
+				?.version ?? null
+		)
+	}
+
+	const stamped = db.get<{ user_version: number }>('PRAGMA user_version')?.user_version ?? 0
+	return stamped === unversioned_checksum ? 0 : null
+}
+
+// migrate_db applies every migration after the given version
+export function migrate_db(db: Db, from: number) {
+	db.transaction(() => {
+		for (const migration of migrations) {
+			if (migration.version <= from) {
+				continue
+			}
+			db.exec(migration.sql)
+			db.run(
+				'INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)',
+				[migration.version, migration.description, Date.now()]
+			)
+		}
+	})
+}
+
+// stamp_schema_version records that a database built by create_schema doesn't need any of
+// the migrations
+export function stamp_schema_version(db: Db) {
+	const applied_at = Date.now()
+	for (const migration of migrations) {
+		db.run(
+			'INSERT OR IGNORE INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)',
+			[migration.version, migration.description, applied_at]
+		)
+	}
+}
 
 export async function write_config(
 	db: Db,
@@ -587,8 +708,10 @@ export async function write_config(
 			default_cache_policy, default_partial, default_lifetime,
 			default_list_position, default_list_target, default_paginate_mode,
 			suppress_pagination_deduplication, log_level, default_fragment_masking,
-			default_keys, persisted_queries_path, project_root, runtime_dir, path
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			default_keys, persisted_queries_path, project_root, runtime_dir, path,
+			document_usage, subscription_transport, subscription_transports, cache_dir,
+			ignore_files, follow_symlinks
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		[
 			JSON.stringify(config.include),
 			JSON.stringify(config.exclude),
@@ -609,6 +732,17 @@ export async function write_config(
 			config.root_dir ?? null,
 			config_file.runtimeDir ?? null,
 			config.filepath ?? null,
+			config_file.documentUsage ?? null,
+			config_file.subscriptions?.transport ?? null,
+			JSON.stringify(config_file.subscriptions?.operations ?? {}),
+			config_file.cacheDir === false
+				? null
+				: config_file.cacheDir ?? path.join('node_modules', '.cache', 'houdini'),
+			// an empty list reads the default ignore files
+			config_file.readIgnoreFiles
+				? JSON.stringify(config_file.readIgnoreFiles === true ? [] : config_file.readIgnoreFiles)
+				: null,
+			config_file.followSymlinks ? 1 : 0,
 		]
 	)
 
@@ -623,8 +757,8 @@ export async function write_config(
 	{
 		const auth = config.server_config.auth
 		db.run(
-			`INSERT INTO router_config (api_endpoint, redirect, session_keys, url, mutation, providers)
-			 VALUES (?, ?, ?, ?, ?, ?)`,
+			`INSERT INTO router_config (api_endpoint, redirect, session_keys, url, mutation, providers, i18n)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
 			[
 				config.server_config.endpoint ?? null,
 				// the trusted redirect-login integration url (enables /login + the loginURL helper)
@@ -635,6 +769,8 @@ export async function write_config(
 				// the configured first-class OAuth provider names — codegen bakes these into the
 				// typed `provider` argument of loginURL, and /login gates on them too
 				auth?.providers ? Object.keys(auth.providers).join(',') : null,
+				// locale routing is public config: codegen turns it into locale variants of every page
+				config_file.router?.i18n ? JSON.stringify(config_file.router.i18n) : null,
 			]
 		)
 	}
@@ -677,11 +813,17 @@ export async function write_config(
 	}
 
 	// write the type configs
-	for (const [name, { keys, resolve }] of Object.entries(config.config_file.types ?? {})) {
-		db.run('INSERT INTO type_configs (name, keys, resolve_query) VALUES (?, ?, ?)', [
-			name,
-			JSON.stringify(keys || config_file.defaultKeys || []),
-			resolve?.queryField || null,
-		])
+	for (const [name, { keys, resolve, cache }] of Object.entries(config.config_file.types ?? {})) {
+		db.run(
+			'INSERT INTO type_configs (name, keys, resolve_query, cache_policy, cache_lifetime, field_cache) VALUES (?, ?, ?, ?, ?, ?)',
+			[
+				name,
+				JSON.stringify(keys || config_file.defaultKeys || []),
+				resolve?.queryField || null,
+				cache?.policy ?? null,
+				cache?.lifetime ?? null,
+				cache?.fields ? JSON.stringify(cache.fields) : null,
+			]
+		)
 	}
 }
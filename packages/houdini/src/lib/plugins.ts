@@ -90,6 +90,29 @@ export async function plugin_path(
 	}
 }
 
+/**
+ * Identify the build of a plugin: the version in its package.json, or the size and mtime of
+ * its executable for local plugins that don't have one.
+ */
+export function plugin_version(plugin: { executable: string; directory: string }): string {
+	const package_json_src = fs.readFileSync(path.join(plugin.directory, 'package.json'))
+	if (package_json_src) {
+		try {
+			const { version } = JSON.parse(package_json_src)
+			if (version) {
+				return version
+			}
+		} catch {}
+	}
+
+	try {
+		const stat = fs.statSync(plugin.executable)
+		return `${stat.size}-${stat.mtimeMs}`
+	} catch {
+		return ''
+	}
+}
+
 /**
  * Find a module using Node.js's built-in module resolution.
  * This works correctly with all package managers (npm, yarn, pnpm, yarn PnP).
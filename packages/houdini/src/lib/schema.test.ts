@@ -3,14 +3,37 @@ import path from 'node:path'
 import { fileURLToPath } from 'node:url'
 import { test, expect } from 'vitest'
 
-import { create_schema } from './database.js'
+import { create_schema, migrations } from './database.js'
 
-// plugins/tests/schema.sql is generated from create_schema (the canonical
-// source) and embedded by the Go test harness. If this fails, run
+// plugins/schema.sql is generated from create_schema (the canonical
+// source) and embedded by the Go plugins. If this fails, run
 // `pnpm sync-schema` to regenerate it.
-test('plugins/tests/schema.sql is in sync with create_schema', () => {
+test('plugins/schema.sql is in sync with create_schema', () => {
 	const here = path.dirname(fileURLToPath(import.meta.url))
-	const sqlPath = path.join(here, '../../../../plugins/tests/schema.sql')
+	const sqlPath = path.join(here, '../../../../plugins/schema.sql')
 	const onDisk = fs.readFileSync(sqlPath, 'utf-8')
 	expect(onDisk).toBe(create_schema)
 })
+
+// plugins/migrations holds one file per migration for the Go plugins, written by
+// `pnpm sync-schema` too
+test('plugins/migrations is in sync with migrations', () => {
+	const here = path.dirname(fileURLToPath(import.meta.url))
+	const dir = path.join(here, '../../../../plugins/migrations')
+	const expected = Object.fromEntries(
+		migrations.map((migration) => [
+			`${String(migration.version).padStart(4, '0')}_${migration.description.replace(/[^a-z0-9]+/gi, '_')}.sql`,
+			`-- ${migration.description}\n${migration.sql}`,
+		])
+	)
+	const onDisk = Object.fromEntries(
+		fs.readdirSync(dir).map((file) => [file, fs.readFileSync(path.join(dir, file), 'utf-8')])
+	)
+	expect(onDisk).toEqual(expected)
+})
+
+test('migrations are numbered in order', () => {
+	expect(migrations.map((migration) => migration.version)).toEqual(
+		migrations.map((_, i) => i + 1)
+	)
+})
@@ -82,6 +82,14 @@ export const CachePolicy = {
 
 export type CachePolicies = ValuesOf<typeof CachePolicy>
 
+export const SubscriptionTransport = {
+	SSE: 'sse',
+	GraphQLWS: 'graphql-ws',
+	Multipart: 'multipart',
+} as const
+
+export type SubscriptionTransports = ValuesOf<typeof SubscriptionTransport>
+
 export type Maybe<T> = T | null | undefined
 
 export type Script = Program
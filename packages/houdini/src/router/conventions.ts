@@ -41,6 +41,15 @@ export function vite_render_path(config: Config, base?: string) {
 	return path.join(units_dir(config, base), 'render', 'vite.js')
 }
 
+export function rsc_render_path(config: Config, base?: string) {
+	return path.join(units_dir(config, base), 'render', 'rsc.js')
+}
+
+// the components that render on the client in rsc mode, listed by the houdini-react plugin
+export function client_boundaries_path(config: Config, base?: string) {
+	return path.join(units_dir(config, base), 'server', 'clientBoundaries.json')
+}
+
 export function app_component_path(config: Config, base?: string) {
 	return path.join(units_dir(config, base), 'render', 'App.jsx')
 }
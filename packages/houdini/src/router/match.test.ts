@@ -279,3 +279,79 @@ describe('find_match search params', () => {
 		expect(searchOf(page, '/search')).toEqual({})
 	})
 })
+
+describe('find_match locales', () => {
+	const documents = {
+		Home: {
+			artifact: () => Promise.resolve({ default: {} as any }),
+			loading: false,
+			variables: { locale: { type: 'String' } },
+		},
+	}
+
+	function pageFor(url: string, locale?: string): RouterManifest<any>['pages'][string] {
+		const parsed = parse_page_pattern(url)
+		return {
+			id: url,
+			url,
+			locale,
+			pattern: parsed.pattern,
+			params: parsed.params,
+			searchParams: [],
+			documents,
+			component: () => Promise.resolve({ default: (() => null) as any }),
+		}
+	}
+
+	test('prefix variants pass their locale', () => {
+		const manifest: RouterManifest<any> = {
+			pages: { '/': pageFor('/', 'en'), '/fr': pageFor('/fr', 'fr') },
+			pagesByUrl: {},
+			i18n: { strategy: 'prefix', variable: 'locale', defaultLocale: 'en', locales: ['en', 'fr'] },
+		}
+		expect(find_match(manifest, '/')[1]).toEqual({ locale: 'en' })
+		expect(find_match(manifest, '/fr')[1]).toEqual({ locale: 'fr' })
+	})
+
+	test('prefix variants are tried before a root dynamic page', () => {
+		// the generated manifest lists the locale variants ahead of the unprefixed pages
+		const manifest: RouterManifest<any> = {
+			pages: {
+				'/fr': pageFor('/fr', 'fr'),
+				'/': pageFor('/', 'en'),
+				'/[slug]': pageFor('/[slug]', 'en'),
+			},
+			pagesByUrl: {},
+			i18n: { strategy: 'prefix', variable: 'locale', defaultLocale: 'en', locales: ['en', 'fr'] },
+		}
+		expect(find_match(manifest, '/fr')[0].id).toEqual('/fr')
+		expect(find_match(manifest, '/fr')[1]).toEqual({ locale: 'fr' })
+		expect(find_match(manifest, '/about')[0].id).toEqual('/[slug]')
+	})
+
+	test('domains pick the locale from the host', () => {
+		const manifest: RouterManifest<any> = {
+			pages: { '/': pageFor('/') },
+			pagesByUrl: {},
+			i18n: {
+				strategy: 'domain',
+				variable: 'locale',
+				defaultLocale: 'en',
+				locales: ['en', 'fr'],
+				domains: { fr: 'example.fr' },
+			},
+		}
+		expect(find_match(manifest, '/', false, 'example.fr')[1]).toEqual({ locale: 'fr' })
+		expect(find_match(manifest, '/', false, 'example.com')[1]).toEqual({ locale: 'en' })
+	})
+
+	test('leaves documents without the variable alone', () => {
+		const page = { ...pageFor('/', 'fr'), documents: {} }
+		const manifest: RouterManifest<any> = {
+			pages: { '/': page },
+			pagesByUrl: {},
+			i18n: { strategy: 'prefix', variable: 'locale', defaultLocale: 'en', locales: ['en', 'fr'] },
+		}
+		expect(find_match(manifest, '/')[1]).toEqual({})
+	})
+})
@@ -1,4 +1,4 @@
-import type { RouterManifest, RouterPageManifest } from './types.js'
+import type { RouterI18n, RouterManifest, RouterPageManifest } from './types.js'
 
 /**
  * This file is copied from the SvelteKit source code under the MIT license found at the bottom of the file
@@ -65,17 +65,20 @@ export function find_prefix_match<_ComponentType>(
 export function find_match<_ComponentType>(
 	manifest: RouterManifest<_ComponentType>,
 	current: string,
-	allowNull: true
+	allowNull: true,
+	host?: string
 ): [RouterPageManifest<_ComponentType> | null, GraphQLVariables, Record<string, any>]
 export function find_match<_ComponentType>(
 	manifest: RouterManifest<_ComponentType>,
 	current: string,
-	allowNull?: false
+	allowNull?: false,
+	host?: string
 ): [RouterPageManifest<_ComponentType>, GraphQLVariables, Record<string, any>]
 export function find_match<_ComponentType>(
 	manifest: RouterManifest<_ComponentType>,
 	current: string,
-	allowNull: boolean = true
+	allowNull: boolean = true,
+	host?: string
 ): [RouterPageManifest<_ComponentType> | null, GraphQLVariables, Record<string, any>] {
 	// the patterns only describe the path, so split the query string off before
 	// matching and keep it around to populate search params below
@@ -122,6 +125,19 @@ export function find_match<_ComponentType>(
 		}
 	}
 
+	// with locale routing on, any document that declares the locale variable gets the
+	// locale of the request. prefix variants know their locale, otherwise we look the
+	// host up in the configured domains and fall back to the default locale.
+	const i18n = manifest.i18n
+	if (i18n && match) {
+		const locale = match.locale ?? localeForHost(i18n, host) ?? i18n.defaultLocale
+		for (const document of Object.values(match.documents)) {
+			if (i18n.variable in document.variables) {
+				variables[i18n.variable] = locale
+			}
+		}
+	}
+
 	// the parsed query string, surfaced to consumers via useRoute().search. every
 	// key present in the query string is included: declared search params are coerced
 	// (reusing the work below that fills query variables) while any other UI-only keys
@@ -363,6 +379,19 @@ export function parseScalar(type: string, value?: string): string | number | boo
 	return value
 }
 
+// localeForHost returns the locale that is served on the given host
+export function localeForHost(i18n: RouterI18n, host?: string): string | undefined {
+	if (!host || !i18n.domains) {
+		return undefined
+	}
+	for (const [locale, domain] of Object.entries(i18n.domains)) {
+		if (domain === host) {
+			return locale
+		}
+	}
+	return undefined
+}
+
 /**
 Copyright (c) 2020 [these people](https://github.com/sveltejs/kit/graphs/contributors)
 
@@ -372,3 +401,4 @@ The above copyright notice and this permission notice shall be included in all c
 
 THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 */
+
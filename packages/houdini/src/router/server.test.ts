@@ -4,6 +4,7 @@ import {
 	_serverHandler,
 	apply_antiframing_defaults,
 	collect_response_headers,
+	pickFields,
 	signFormToken,
 } from './server.js'
 
@@ -175,6 +176,122 @@ describe('_serverHandler form submissions', () => {
 	})
 })
 
+describe('_serverHandler endpoints', () => {
+	const artifact = {
+		name: 'CreateUser',
+		kind: 'HoudiniMutation',
+		raw: 'mutation CreateUser($name: String!, $role: String) { createUser(name: $name) { id } }',
+		input: {
+			fields: { name: 'String', role: 'String' },
+			types: {},
+			defaults: {},
+			runtimeScalars: {},
+		},
+		endpoint: { fields: ['name'] },
+	}
+
+	function endpointHandlerFor(graphqlResult: any) {
+		const requestHandler = vi.fn(
+			async (_: Request) => new Response(JSON.stringify(graphqlResult))
+		)
+		const handler = _serverHandler({
+			schema: {} as any,
+			server: { init: () => requestHandler } as any,
+			client: { componentCache: {}, registerProxy: vi.fn() } as any,
+			production: true,
+			manifest: {
+				pages: {},
+				pagesByUrl: {},
+				formActions: { CreateUser: () => Promise.resolve({ default: artifact }) },
+			} as any,
+			assetPrefix: '',
+			graphqlEndpoint: '/_api',
+			componentCache: {},
+			config_file: { router: { auth: {} } } as any,
+			server_config: { auth: { sessionKeys: [TOKEN_KEY] } },
+			on_render: () => new Response('page'),
+		})
+		return { handler, requestHandler }
+	}
+
+	test('runs the mutation with a JSON body', async () => {
+		const { handler, requestHandler } = endpointHandlerFor({ data: { createUser: { id: '7' } } })
+		const res = await handler(
+			new Request('http://localhost/_api/CreateUser', {
+				method: 'POST',
+				headers: { 'content-type': 'application/json' },
+				body: JSON.stringify({ name: 'Alice', role: 'admin' }),
+			})
+		)
+		expect(res.status).toBe(200)
+		expect(await res.json()).toEqual({ data: { createUser: { id: '7' } } })
+
+		// role isn't in the allowlist so it never reaches the mutation
+		const sent = await requestHandler.mock.calls[0][0].json()
+		expect(sent.variables).toEqual({ name: 'Alice' })
+	})
+
+	test('runs the mutation with a form body', async () => {
+		const { handler, requestHandler } = endpointHandlerFor({ data: { createUser: { id: '7' } } })
+		const res = await handler(
+			new Request('http://localhost/_api/CreateUser', {
+				method: 'POST',
+				headers: { 'content-type': 'application/x-www-form-urlencoded' },
+				body: new URLSearchParams({ name: 'Alice' }),
+			})
+		)
+		expect(res.status).toBe(200)
+		const sent = await requestHandler.mock.calls[0][0].json()
+		expect(sent.variables).toEqual({ name: 'Alice' })
+	})
+
+	test('only forwards the session to allowed origins', async () => {
+		const { handler, requestHandler } = endpointHandlerFor({ data: {} })
+		await handler(
+			new Request('http://localhost/_api/CreateUser', {
+				method: 'POST',
+				headers: { 'content-type': 'application/json', authorization: 'Bearer abc' },
+				body: JSON.stringify({ name: 'Alice' }),
+			})
+		)
+		const sent = requestHandler.mock.calls[0][0]
+		expect(sent.headers.get('cookie')).toBeNull()
+		expect(sent.headers.get('authorization')).toBe('Bearer abc')
+	})
+
+	test('errors → 422 with the result', async () => {
+		const { handler } = endpointHandlerFor({ data: null, errors: [{ message: 'nope' }] })
+		const res = await handler(
+			new Request('http://localhost/_api/CreateUser', {
+				method: 'POST',
+				headers: { 'content-type': 'application/json' },
+				body: JSON.stringify({ name: 'Alice' }),
+			})
+		)
+		expect(res.status).toBe(422)
+		expect(await res.json()).toEqual({ data: null, errors: [{ message: 'nope' }] })
+	})
+
+	test('unknown endpoints → 404, other methods → 405', async () => {
+		const { handler } = endpointHandlerFor({})
+		const missing = await handler(new Request('http://localhost/_api/Nope', { method: 'POST' }))
+		expect(missing.status).toBe(404)
+		const get = await handler(new Request('http://localhost/_api/CreateUser'))
+		expect(get.status).toBe(405)
+	})
+})
+
+describe('pickFields', () => {
+	test('keeps listed paths and filters nested objects', () => {
+		expect(
+			pickFields(
+				{ name: 'A', tags: ['x'], user: { email: 'e', admin: true }, admin: true },
+				new Set(['name', 'tags[]', 'user.email'])
+			)
+		).toEqual({ name: 'A', tags: ['x'], user: { email: 'e' } })
+	})
+})
+
 describe('apply_antiframing_defaults', () => {
 	test('blocks cross-origin framing by default', () => {
 		const headers: Record<string, string> = {}
@@ -219,6 +219,8 @@ export function _serverHandler<ComponentType = unknown>({
 	componentCache: Record<string, any>
 	on_render: (args: {
 		url: string
+		// the host the request was sent to. locale routing by domain reads the locale from it
+		host: string
 		match: RouterPageManifest<ComponentType> | null
 		is404: boolean
 		manifest: RouterManifest<unknown>
@@ -329,7 +331,8 @@ export function _serverHandler<ComponentType = unknown>({
 	): Promise<Response> => {
 		// find the matching url; fall back to the deepest prefix match so that
 		// 404 pages render inside the correct layout chain
-		const [exactMatch] = find_match(nonNullManifest, url)
+		const host = new URL(request.url).host
+		const [exactMatch] = find_match(nonNullManifest, url, true, host)
 		const is404 = !exactMatch
 		const match = exactMatch ?? find_prefix_match(nonNullManifest, url)
 
@@ -347,6 +350,7 @@ export function _serverHandler<ComponentType = unknown>({
 
 		const rendered = await on_render({
 			url,
+			host,
 			match,
 			is404,
 			session,
@@ -516,6 +520,108 @@ export function _serverHandler<ComponentType = unknown>({
 		return formResponse
 	}
 
+	// handleEndpoint serves every @endpoint mutation at {graphqlEndpoint}/{name} for clients
+	// that don't speak GraphQL (webhooks, mobile shells, ...). The body is either JSON, whose
+	// keys are the mutation's variables in their transport form, or the same flat form keys the
+	// no-JS form posts. The @endpoint(fields:) allowlist applies to both. There's no CSRF token
+	// here, so the caller's session is only used when the request comes from an allowed origin;
+	// everyone else runs session-less and can authenticate with their own headers (e.g.
+	// Authorization), which are forwarded. Returns undefined when the url isn't an endpoint.
+	const handleEndpoint = async (
+		nonNullManifest: RouterManifest<ComponentType>,
+		request: Request,
+		parsedURL: URL
+	): Promise<Response | undefined> => {
+		const prefix = graphqlEndpoint.replace(/\/$/, '') + '/'
+		if (!parsedURL.pathname.startsWith(prefix)) {
+			return undefined
+		}
+		const mutationName = decodeURIComponent(parsedURL.pathname.slice(prefix.length))
+		const loadArtifact = nonNullManifest.formActions?.[mutationName]
+		if (!loadArtifact) {
+			return new Response('Not Found', { status: 404 })
+		}
+		if (request.method !== 'POST') {
+			return new Response('Method Not Allowed', { status: 405, headers: { Allow: 'POST' } })
+		}
+		const handler = requestHandler
+		if (!handler) {
+			return new Response('Endpoints require a local GraphQL server', { status: 500 })
+		}
+
+		// same DoS guard as the form handler
+		const maxBodyBytes = server_config?.formMaxBodyBytes ?? 10 * 1024 * 1024
+		const contentLength = Number(request.headers.get('content-length'))
+		if (Number.isFinite(contentLength) && contentLength > maxBodyBytes) {
+			return new Response('Payload Too Large', { status: 413 })
+		}
+		const limited = await readBodyWithLimit(request, maxBodyBytes)
+		if (limited === null) {
+			return new Response('Payload Too Large', { status: 413 })
+		}
+
+		const { default: artifact } = await loadArtifact()
+		const allowedFields = artifact.endpoint?.fields
+
+		let variables: Record<string, any>
+		const contentType = request.headers.get('content-type') ?? ''
+		if (contentType.includes('application/json')) {
+			let body: unknown
+			try {
+				body = await limited.json()
+			} catch {
+				return new Response('Bad Request', { status: 400 })
+			}
+			if (!body || typeof body !== 'object' || Array.isArray(body)) {
+				return new Response('Bad Request', { status: 400 })
+			}
+			variables = allowedFields
+				? pickFields(body as Record<string, any>, new Set(allowedFields))
+				: (body as Record<string, any>)
+		} else if (
+			contentType.includes('application/x-www-form-urlencoded') ||
+			contentType.includes('multipart/form-data')
+		) {
+			const input = artifact.input ?? { fields: {}, types: {}, defaults: {}, runtimeScalars: {} }
+			const coerced = coerceFormData(await limited.formData(), input, config_file, allowedFields)
+			variables = (marshalInputs({ artifact, input: coerced, config: config_file }) ??
+				{}) as Record<string, any>
+		} else {
+			return new Response('Unsupported Media Type', { status: 415 })
+		}
+
+		const headers: Record<string, string> = {
+			// the caller gets the result as JSON, never a session token to relay
+			[INTERNAL_FORM_HEADER]: '1',
+		}
+		const authorization = request.headers.get('authorization')
+		if (authorization) {
+			headers.Authorization = authorization
+		}
+		if (isAllowedOrigin(request, server_config)) {
+			const session = await get_session(request.headers, session_keys)
+			headers.Cookie = encodeCookie(
+				session_cookie_name,
+				await sign_session(session ?? {}, session_keys[0]),
+				{ httpOnly: true }
+			)
+		}
+
+		const { contentType: bodyContentType, body } = buildGraphQLBody(artifact.raw, variables)
+		if (bodyContentType) {
+			headers['Content-Type'] = bodyContentType
+		}
+		const response = await handler(
+			new Request(`http://localhost/${graphqlEndpoint}`, { method: 'POST', headers, body })
+		)
+		const result = (await response.json()) as { data?: any; errors?: any[] }
+
+		return new Response(JSON.stringify({ data: result.data ?? null, errors: result.errors }), {
+			status: result.errors && result.errors.length > 0 ? 422 : 200,
+			headers: { 'Content-Type': 'application/json' },
+		})
+	}
+
 	// handleSessionProxy is the server half of @session for a REMOTE api (no local schema). The
 	// client routes a @session mutation here instead of straight to `apiEndpoint`; the proxy
 	// forwards it to that same upstream, then writes the session cookie from the result so the value
@@ -662,6 +768,12 @@ export function _serverHandler<ComponentType = unknown>({
 			return requestHandler(request, ...extraContext)
 		}
 
+		// maybe it's one of the @endpoint mutations served as a plain http endpoint
+		const endpointResponse = await handleEndpoint(manifest, request, parsedURL)
+		if (endpointResponse) {
+			return endpointResponse
+		}
+
 		// maybe it's a @session mutation proxied through us for a remote api. Checked BEFORE
 		// handle_request because the proxy path lives under the auth url (handle_request would
 		// otherwise claim it via its startsWith match).
@@ -692,6 +804,29 @@ export function _serverHandler<ComponentType = unknown>({
 	}
 }
 
+// pickFields applies an @endpoint(fields:) allowlist to a JSON body. The allowlist holds the
+// flat keys a form would post (user.name, tags[]), so a value is kept when its path is listed
+// and nested objects are filtered key by key.
+export function pickFields(
+	body: Record<string, any>,
+	allowed: Set<string>,
+	prefix: string = ''
+): Record<string, any> {
+	const result: Record<string, any> = {}
+	for (const [key, value] of Object.entries(body)) {
+		const path = prefix ? `${prefix}.${key}` : key
+		if (allowed.has(path) || allowed.has(`${path}[]`)) {
+			result[key] = value
+		} else if (value && typeof value === 'object' && !Array.isArray(value)) {
+			const nested = pickFields(value, allowed, path)
+			if (Object.keys(nested).length > 0) {
+				result[key] = nested
+			}
+		}
+	}
+	return result
+}
+
 export const serverAdapterFactory = (
 	args: Parameters<typeof _serverHandler>[0]
 ): ReturnType<typeof createServerAdapter> => {
@@ -21,6 +21,21 @@ export type RouterManifest<_ComponentType> = {
 	// session. Server-only; consumed by the session-mint plugin and the no-JS form handler.
 	// Independent of formActions — a session-writing mutation need not be a form.
 	sessionMutations?: Record<string, { sessionPath: string; merge?: boolean }>
+	// the locale routing config (router.i18n). absent when locale routing is off
+	i18n?: RouterI18n
+}
+
+// how the router picks the locale of a request. With the prefix strategy every
+// non-default locale has its own page variants (/fr/...) that carry their locale.
+// With the domain strategy the locale comes from the host the page was served on.
+export type RouterI18n = {
+	strategy: 'prefix' | 'domain'
+	// the query variable that receives the locale
+	variable: string
+	defaultLocale: string
+	locales: string[]
+	// maps each locale to the host that serves it (domain strategy only)
+	domains?: Record<string, string>
 }
 
 export type { ServerAdapterFactory } from './server.js'
@@ -29,6 +44,8 @@ export type RouterPageManifest<_ComponentType> = {
 	id: string
 	// the navigable url for this page (route groups stripped), e.g. "/users/[id]"
 	url: string
+	// the locale this variant of the page renders (prefix locale routing only)
+	locale?: string
 
 	// the url pattern to match against. created from './match/parse_page_pattern'
 	pattern: RegExp
@@ -5,6 +5,9 @@ export class GarbageCollector {
 
 	private lifetimes: Map<string, Map<string, number>> = new Map()
 
+	// the fields whose lifetime was configured with @cache or the types config
+	private maxTimes: Map<string, Map<string, number>> = new Map()
+
 	// the number of ticks of the garbage collector that a piece of data will
 	get cacheBufferSize() {
 		return this.cache._internal_unstable.config.cacheBufferSize ?? 10
@@ -16,10 +19,12 @@ export class GarbageCollector {
 
 	reset() {
 		this.lifetimes.clear()
+		this.maxTimes.clear()
 	}
 
 	delete(id: string) {
 		this.lifetimes.delete(id)
+		this.maxTimes.delete(id)
 	}
 
 	resetLifetime(id: string, field: string) {
@@ -32,6 +37,20 @@ export class GarbageCollector {
 		this.lifetimes.get(id)!.set(field, 0)
 	}
 
+	// setMaxTime remembers the lifetime of the selection that last wrote the field so it can go
+	// stale before the default. a write without one puts the field back on the default lifetime
+	setMaxTime(id: string, field: string, maxTime?: number) {
+		if (!maxTime) {
+			this.maxTimes.get(id)?.delete(field)
+			return
+		}
+
+		if (!this.maxTimes.get(id)) {
+			this.maxTimes.set(id, new Map())
+		}
+		this.maxTimes.get(id)!.set(field, maxTime)
+	}
+
 	tick() {
 		// get the current time of the tick
 		const dt_tick = Date.now().valueOf()
@@ -65,10 +84,12 @@ export class GarbageCollector {
 
 					// delete the entry in lifetime map
 					fieldMap.delete(field)
+					this.maxTimes.get(id)?.delete(field)
 
 					// if there are no more entries for the id, delete the id info
 					if ([...fieldMap.keys()].length === 0) {
 						this.lifetimes.delete(id)
+						this.maxTimes.delete(id)
 					}
 
 					// remove the field from the stale manager
@@ -78,13 +99,14 @@ export class GarbageCollector {
 				// --- ------------------- ---
 				// --- Part 2 : fieldTimes ---
 				// --- ------------------- ---
-				if (config_max_time && config_max_time > 0) {
+				const max_time = this.maxTimes.get(id)?.get(field) ?? config_max_time
+				if (max_time && max_time > 0) {
 					// if the field is older than x... mark it as stale
 					const dt_valueOf = this.cache.getFieldTime(id, field)
 
 					// if we have no dt_valueOf, it's already stale
 					// check if more than the max time has passed since it was marked stale
-					if (dt_valueOf && dt_tick - dt_valueOf > config_max_time) {
+					if (dt_valueOf && dt_tick - dt_valueOf > max_time) {
 						this.cache._internal_unstable.staleManager.markFieldStale(id, field)
 					}
 				}
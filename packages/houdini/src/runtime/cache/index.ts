@@ -18,7 +18,7 @@ import type {
 	ValueMap,
 	ValueNode,
 } from '../types.js'
-import { ArtifactKind, fragmentKey } from '../types.js'
+import { ArtifactKind, CachePolicy, fragmentKey } from '../types.js'
 import { GarbageCollector } from './gc.js'
 import type { ListCollection } from './lists.js'
 import { ListManager, opaqueListID } from './lists.js'
@@ -562,6 +562,7 @@ class CacheInternal {
 				operations,
 				abstract: isAbstract,
 				updates,
+				cache: cacheRules,
 			} = targetSelection[field]
 			const key = evaluateKey(keyRaw, variables)
 
@@ -591,6 +592,7 @@ class CacheInternal {
 			// if we are writing to the display layer we need to refresh the lifetime of the value
 			if (displayLayer) {
 				this.lifetimes.resetLifetime(parent, key)
+				this.lifetimes.setMaxTime(parent, key, cacheRules?.lifetime)
 
 				// update the stale status
 				if (forceStale) {
@@ -1246,6 +1248,7 @@ class CacheInternal {
 				loading: fieldLoading,
 				abstractHasRequired,
 				component,
+				cache: cacheRules,
 			},
 		] of Object.entries(targetSelection)) {
 			// skip masked fields when reading values
@@ -1305,6 +1308,14 @@ class CacheInternal {
 				stale = true
 			}
 
+			// fields that opted out of the cache are never fresh
+			if (
+				cacheRules?.policy === CachePolicy.NetworkOnly ||
+				cacheRules?.policy === CachePolicy.NoCache
+			) {
+				stale = true
+			}
+
 			// a loading state has no real values
 			if (generateLoading) {
 				value = undefined
@@ -444,3 +444,162 @@ test('ticks of gc clean up listsByOpaqueID', () => {
 		cache._internal_unstable.lists.getByOpaqueID(opaqueListID('User:1', 'All_Users'))
 	).toBeNull()
 })
+
+test('fields with their own lifetime go stale before the default', () => {
+	const cache = new Cache(config)
+
+	const userFields: SubscriptionSelection = {
+		fields: {
+			id: {
+				type: 'ID',
+				visible: true,
+				keyRaw: 'id',
+			},
+			firstName: {
+				type: 'String',
+				visible: true,
+				keyRaw: 'firstName',
+				cache: {
+					lifetime: 1000,
+				},
+			},
+		},
+	}
+
+	const now = Date.now()
+	const clock = vi.spyOn(Date, 'now').mockReturnValue(now)
+
+	cache.write({
+		selection: {
+			fields: {
+				viewer: {
+					type: 'User',
+					visible: true,
+					keyRaw: 'viewer',
+					selection: userFields,
+				},
+			},
+		},
+		data: {
+			viewer: {
+				id: '1',
+				firstName: 'bob',
+			},
+		},
+	})
+
+	// nothing is stale before the lifetime is up
+	clock.mockReturnValue(now + 500)
+	cache._internal_unstable.collectGarbage()
+	expect(cache.read({ selection: userFields, parent: 'User:1' }).stale).toBe(false)
+
+	// once the field's lifetime has passed the next tick marks it stale
+	clock.mockReturnValue(now + 1500)
+	cache._internal_unstable.collectGarbage()
+	expect(cache.read({ selection: userFields, parent: 'User:1' }).stale).toBe(true)
+	expect(cache._internal_unstable.staleManager.getFieldTime('User:1', 'id')).not.toBeNull()
+
+	clock.mockRestore()
+})
+
+test("a write without a field's lifetime puts it back on the default", () => {
+	const cache = new Cache(config)
+
+	const withLifetime: SubscriptionSelection = {
+		fields: {
+			id: {
+				type: 'ID',
+				visible: true,
+				keyRaw: 'id',
+			},
+			firstName: {
+				type: 'String',
+				visible: true,
+				keyRaw: 'firstName',
+				cache: {
+					lifetime: 1000,
+				},
+			},
+		},
+	}
+	const withoutLifetime: SubscriptionSelection = {
+		fields: {
+			id: {
+				type: 'ID',
+				visible: true,
+				keyRaw: 'id',
+			},
+			firstName: {
+				type: 'String',
+				visible: true,
+				keyRaw: 'firstName',
+			},
+		},
+	}
+
+	const now = Date.now()
+	const clock = vi.spyOn(Date, 'now').mockReturnValue(now)
+
+	// one document gives the field a short lifetime
+	cache.write({
+		selection: withLifetime,
+		parent: 'User:1',
+		data: {
+			id: '1',
+			firstName: 'bob',
+		},
+	})
+
+	// another document writes the same field without one
+	cache.write({
+		selection: withoutLifetime,
+		parent: 'User:1',
+		data: {
+			id: '1',
+			firstName: 'bob',
+		},
+	})
+
+	// the first document's lifetime no longer applies
+	clock.mockReturnValue(now + 1500)
+	cache._internal_unstable.collectGarbage()
+	expect(cache.read({ selection: withoutLifetime, parent: 'User:1' }).stale).toBe(false)
+
+	clock.mockRestore()
+})
+
+test('fields that opt out of the cache always read as stale', () => {
+	const cache = new Cache(config)
+
+	const userFields: SubscriptionSelection = {
+		fields: {
+			id: {
+				type: 'ID',
+				visible: true,
+				keyRaw: 'id',
+			},
+			firstName: {
+				type: 'String',
+				visible: true,
+				keyRaw: 'firstName',
+				cache: {
+					policy: 'NetworkOnly',
+				},
+			},
+		},
+	}
+
+	cache.write({
+		selection: userFields,
+		parent: 'User:1',
+		data: {
+			id: '1',
+			firstName: 'bob',
+		},
+	})
+
+	expect(cache.read({ selection: userFields, parent: 'User:1' })).toMatchObject({
+		data: { id: '1', firstName: 'bob' },
+		stale: true,
+	})
+})
@@ -95,7 +95,11 @@ export type FragmentArtifact = BaseCompiledDocument<'HoudiniFragment'> & {
 	plural?: boolean
 }
 
-export type SubscriptionArtifact = BaseCompiledDocument<'HoudiniSubscription'>
+export type SubscriptionArtifact = BaseCompiledDocument<'HoudiniSubscription'> & {
+	// the protocol the subscription is sent over, picked by @transport or the config file.
+	// when it's missing, the client uses whatever handler it was given by default
+	transport?: SubscriptionTransports
+}
 
 export const RefetchUpdateMode = {
 	append: 'append',
@@ -300,6 +304,11 @@ export type SubscriptionSelection = Readonly<{
 				variables: ValueMap | null
 			}
 			optimisticKey?: boolean
+			// cache rules for the field (from @cache or the types config)
+			cache?: {
+				policy?: CachePolicies
+				lifetime?: number
+			}
 		}>
 	}
 	abstractFields?: {
@@ -575,6 +584,14 @@ export const CachePolicy = {
 
 export type CachePolicies = ValuesOf<typeof CachePolicy>
 
+export const SubscriptionTransport = {
+	SSE: 'sse',
+	GraphQLWS: 'graphql-ws',
+	Multipart: 'multipart',
+} as const
+
+export type SubscriptionTransports = ValuesOf<typeof SubscriptionTransport>
+
 // CacheTypeDef is an interface so the generated runtime and frameworks can augment it.
 // - scalars: declared by the generated runtime with the project's scalar output types
 //   (it can't be declared here — a merged redeclaration would have to match exactly)
@@ -46,11 +46,30 @@ export function refresh_on_schema(ctx: VitePluginContext): PluginOption {
 			}
 
 			// trigger_hook handles flush before Go runs and reload after.
-			// The Schema hook itself clears stale type_fields before re-inserting.
+			// The Schema hook itself clears stale type_fields before re-inserting. Since it runs
+			// as part of a task, it also adds every document that depends on a changed type or
+			// field to the task, so the rest of the pipeline leaves the other artifacts alone.
+			// The documents haven't changed so there's nothing to extract.
+			const task_id = `schema-${Date.now()}`
 			try {
-				await compiler.pipeline_lock(() =>
-					run_pipeline(compiler.trigger_hook, { start: 'Schema' })
-				)
+				await compiler.pipeline_lock(async () => {
+					try {
+						await run_pipeline(compiler.trigger_hook, {
+							task_id,
+							start: 'Schema',
+							through: 'Schema',
+						})
+						await run_pipeline(compiler.trigger_hook, {
+							task_id,
+							after: 'ExtractDocuments',
+						})
+					} finally {
+						// clear the task so it doesn't bleed into the next run
+						ctx.db.run(`UPDATE raw_documents SET current_task = NULL WHERE current_task = ?`, [
+							task_id,
+						])
+					}
+				})
 			} catch (e) {
 				console.error(e)
 			}
@@ -0,0 +1,157 @@
+package perf_test
+
+// Usage: pnpm bench:database
+//
+// Builds a generated project with houdini-core running inside the benchmark, once against an
+// in-memory database and once against a database file, to see what the file costs a one-shot
+// build. Pass -benchtime to trade precision for time.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/packages/houdini-core/config"
+	"code.houdinigraphql.com/packages/houdini-core/plugin"
+	"code.houdinigraphql.com/plugins"
+)
+
+// the number of types in the generated schema. every type gets a query and a fragment.
+const benchmarkTypes = 50
+
+func BenchmarkDatabase(b *testing.B) {
+	coreDirectory, err := filepath.Abs(filepath.Join("..", "packages", "houdini-core"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	project := writeProject(b)
+	// plugins run from the root of the project, like they do when the orchestrator starts them
+	b.Chdir(project)
+
+	for _, mode := range []string{"memory", "file"} {
+		b.Run(mode, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				path := ""
+				if mode == "file" {
+					path = filepath.Join(b.TempDir(), "houdini.db")
+				}
+				if err := build(project, coreDirectory, path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func build(project string, coreDirectory string, path string) error {
+	ctx := context.Background()
+
+	host, err := plugins.NewHost(path)
+	if err != nil {
+		return err
+	}
+	defer host.Close()
+
+	if err := writeConfig(ctx, host.Database(), project); err != nil {
+		return err
+	}
+
+	core := &plugin.HoudiniCore{}
+	core.SetFilesystem(afero.NewOsFs())
+	err = plugins.HostPlugin[config.PluginConfig](host, core, coreDirectory)
+	if err != nil {
+		return err
+	}
+
+	return host.RunPipeline(ctx, plugins.PipelineOptions{})
+}
+
+func writeConfig(ctx context.Context, db plugins.DatabasePool[struct{}], project string) error {
+	include, _ := json.Marshal([]string{"src/**/*.gql"})
+	keys, _ := json.Marshal([]string{"id"})
+	return db.ExecQuery(ctx, `
+		INSERT INTO config (
+			include, exclude, schema_path, default_keys, default_paginate_mode,
+			persisted_queries_path, project_root, runtime_dir, path
+		) VALUES (
+			$include, '[]', 'schema.graphql', $keys, 'Infinite',
+			'.houdini/persisted_queries.json', $project_root, '.houdini', $path
+		)
+	`, map[string]any{
+		"include":      string(include),
+		"keys":         string(keys),
+		"project_root": project,
+		"path":         filepath.Join(project, "houdini.config.js"),
+	})
+}
+
+// writeProject creates a schema and a handful of documents for every type in it
+func writeProject(b *testing.B) string {
+	project := b.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, "src"), 0o755); err != nil {
+		b.Fatal(err)
+	}
+
+	var schema strings.Builder
+	schema.WriteString("type Query {\n")
+	for i := 0; i < benchmarkTypes; i++ {
+		fmt.Fprintf(&schema, "  type%d(id: ID!): Type%d\n", i, i)
+		fmt.Fprintf(&schema, "  all%d(first: Int, after: String): [Type%d!]!\n", i, i)
+	}
+	schema.WriteString("}\n")
+	for i := 0; i < benchmarkTypes; i++ {
+		fmt.Fprintf(&schema, `
+type Type%[1]d {
+  id: ID!
+  name: String!
+  count: Int
+  next: Type%[2]d
+}
+`, i, (i+1)%benchmarkTypes)
+	}
+	err := os.WriteFile(filepath.Join(project, "schema.graphql"), []byte(schema.String()), 0o644)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < benchmarkTypes; i++ {
+		documents := fmt.Sprintf(`
+fragment Type%[1]dInfo on Type%[1]d {
+  name
+  count
+  next {
+    name
+  }
+}
+
+query Type%[1]dQuery($id: ID!) {
+  type%[1]d(id: $id) {
+    ...Type%[1]dInfo
+  }
+}
+
+query All%[1]dQuery {
+  all%[1]d @list(name: "All%[1]d") {
+    id
+    name
+  }
+}
+`, i)
+		err := os.WriteFile(
+			filepath.Join(project, "src", fmt.Sprintf("type%d.gql", i)),
+			[]byte(documents),
+			0o644,
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return project
+}
@@ -0,0 +1,39 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+)
+
+// Commands are what a plugin binary can run instead of the plugin, keyed by name. Each command
+// gets the arguments that follow its name.
+type Commands map[string]func(args []string) error
+
+// DefaultDatabasePath is where codegen leaves the database in a project that uses the default
+// runtime directory
+const DefaultDatabasePath = ".houdini/db.sqlite"
+
+// RunCommand runs the command named by the first argument. The orchestrator only passes flags to
+// a plugin so a leading name means the binary was invoked as a command. The boolean reports
+// whether a command ran so the caller knows not to start the plugin.
+func RunCommand(commands Commands, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return false, nil
+	}
+	return true, command(args[1:])
+}
+
+// OpenExistingPool opens the database codegen left behind at path. A missing database means
+// codegen hasn't run in the project so the error says as much.
+func OpenExistingPool[PC any](path string) (DatabasePool[PC], error) {
+	if _, err := os.Stat(path); err != nil {
+		return DatabasePool[PC]{}, fmt.Errorf(
+			"could not find the database at %s. run houdini generate first", path,
+		)
+	}
+	return OpenPool[PC](path)
+}
@@ -0,0 +1,56 @@
+//go:build !wasip1
+
+package plugins
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommand(t *testing.T) {
+	var got []string
+	commands := Commands{
+		"inspect": func(args []string) error {
+			got = args
+			return nil
+		},
+		"fail": func(args []string) error {
+			return errors.New("failed")
+		},
+	}
+
+	// flags are meant for the plugin
+	ran, err := RunCommand(commands, []string{"-database", "db.sqlite"})
+	require.NoError(t, err)
+	require.False(t, ran)
+
+	ran, err = RunCommand(commands, nil)
+	require.NoError(t, err)
+	require.False(t, ran)
+
+	// a command gets the arguments after its name
+	ran, err = RunCommand(commands, []string{"inspect", "lists", "-json"})
+	require.NoError(t, err)
+	require.True(t, ran)
+	require.Equal(t, []string{"lists", "-json"}, got)
+
+	ran, err = RunCommand(commands, []string{"fail"})
+	require.True(t, ran)
+	require.EqualError(t, err, "failed")
+}
+
+func TestOpenExistingPool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.sqlite")
+
+	_, err := OpenExistingPool[struct{}](path)
+	require.EqualError(t, err, "could not find the database at "+path+". run houdini generate first")
+
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	db, err := OpenExistingPool[struct{}](path)
+	require.NoError(t, err)
+	db.Close()
+}
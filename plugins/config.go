@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"path/filepath"
+	"slices"
+
+	"code.houdinigraphql.com/plugins/glob"
 )
 
 type ProjectConfig struct {
@@ -29,6 +32,79 @@ type ProjectConfig struct {
 	Scalars                         map[string]ScalarConfig
 	TypeConfig                      map[string]TypeConfig
 	Filepath                        string
+	DocumentUsage                   DocumentUsage
+	SubscriptionTransport           SubscriptionTransport
+	SubscriptionTransports          map[string]SubscriptionTransport
+	CacheDir                        string
+	// skip the files that ignore files leave out. An empty IgnoreFiles reads glob.DefaultIgnoreFiles
+	ReadIgnoreFiles bool
+	IgnoreFiles     []string
+	FollowSymlinks  bool
+}
+
+// DocumentUsage controls how unused and misplaced documents are reported
+type DocumentUsage = string
+
+const (
+	DocumentUsageOff   DocumentUsage = ""
+	DocumentUsageWarn  DocumentUsage = "warn"
+	DocumentUsageError DocumentUsage = "error"
+)
+
+// SubscriptionTransport is the protocol a subscription is sent over
+type SubscriptionTransport = string
+
+const (
+	SubscriptionTransportSSE       SubscriptionTransport = "sse"
+	SubscriptionTransportGraphQLWS SubscriptionTransport = "graphql-ws"
+	SubscriptionTransportMultipart SubscriptionTransport = "multipart"
+)
+
+// KnownSubscriptionTransports lists every transport the client knows how to use
+var KnownSubscriptionTransports = []SubscriptionTransport{
+	SubscriptionTransportSSE,
+	SubscriptionTransportGraphQLWS,
+	SubscriptionTransportMultipart,
+}
+
+// TransportFor returns the transport the config file assigns to the subscription with
+// the given name. An empty string means the client should use its default.
+func (config ProjectConfig) TransportFor(document string) SubscriptionTransport {
+	if transport, ok := config.SubscriptionTransports[document]; ok {
+		return transport
+	}
+	return config.SubscriptionTransport
+}
+
+// ArtifactCacheDirectory is where generated artifacts are kept between runs. An empty string
+// means the cache is turned off.
+func (config ProjectConfig) ArtifactCacheDirectory() string {
+	if config.CacheDir == "" || filepath.IsAbs(config.CacheDir) {
+		return config.CacheDir
+	}
+	return filepath.Join(config.ProjectRoot, config.CacheDir)
+}
+
+// Walker builds the walker that finds the files of the project
+func (config ProjectConfig) Walker() (*glob.Walker, error) {
+	walker := glob.NewWalker()
+	for _, pattern := range config.Include {
+		if err := walker.AddInclude(pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range config.Exclude {
+		if err := walker.AddExclude(pattern); err != nil {
+			return nil, err
+		}
+	}
+	if config.ReadIgnoreFiles {
+		walker.ReadIgnoreFiles(config.IgnoreFiles...)
+	}
+	if config.FollowSymlinks {
+		walker.FollowSymlinks()
+	}
+	return walker, nil
 }
 
 func (config ProjectConfig) PluginDirectory(name string) string {
@@ -97,7 +173,13 @@ func (db *DatabasePool[PluginConfig]) ReloadProjectConfig(ctx context.Context) e
 		project_root,
 		runtime_dir,
 		schema_path,
-		path
+		path,
+		document_usage,
+		subscription_transport,
+		subscription_transports,
+		cache_dir,
+		ignore_files,
+		follow_symlinks
 	FROM config LIMIT 1`)
 	if err != nil {
 		return err
@@ -139,6 +221,23 @@ func (db *DatabasePool[PluginConfig]) ReloadProjectConfig(ctx context.Context) e
 		config.RuntimeDir = stmt.ColumnText(16)
 		config.SchemaPath = stmt.ColumnText(17)
 		config.Filepath = stmt.GetText("path")
+		config.DocumentUsage = stmt.GetText("document_usage")
+		config.SubscriptionTransport = stmt.GetText("subscription_transport")
+		config.CacheDir = stmt.GetText("cache_dir")
+		config.FollowSymlinks = stmt.GetInt64("follow_symlinks") == 1
+		if ignoreFiles := stmt.GetText("ignore_files"); ignoreFiles != "" {
+			config.ReadIgnoreFiles = true
+			err = json.Unmarshal([]byte(ignoreFiles), &config.IgnoreFiles)
+			if err != nil {
+				return err
+			}
+		}
+		if transports := stmt.GetText("subscription_transports"); transports != "" {
+			err = json.Unmarshal([]byte(transports), &config.SubscriptionTransports)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// load runtime scalar information
@@ -159,7 +258,9 @@ func (db *DatabasePool[PluginConfig]) ReloadProjectConfig(ctx context.Context) e
 	}
 
 	// load type config information
-	typeConfigSearch, err := conn.Prepare(`SELECT name, keys, resolve_query FROM type_configs`)
+	typeConfigSearch, err := conn.Prepare(`
+		SELECT name, keys, resolve_query, cache_policy, cache_lifetime, field_cache FROM type_configs
+	`)
 	if err != nil {
 		return err
 	}
@@ -179,9 +280,22 @@ func (db *DatabasePool[PluginConfig]) ReloadProjectConfig(ctx context.Context) e
 			return err
 		}
 
+		fieldCache := map[string]CacheRules{}
+		if raw := typeConfigSearch.ColumnText(5); raw != "" {
+			err = json.Unmarshal([]byte(raw), &fieldCache)
+			if err != nil {
+				return err
+			}
+		}
+
 		config.TypeConfig[typeConfigSearch.ColumnText(0)] = TypeConfig{
 			Keys:         keys,
 			ResolveQuery: typeConfigSearch.ColumnText(2),
+			Cache: CacheRules{
+				Policy:   typeConfigSearch.ColumnText(3),
+				Lifetime: typeConfigSearch.ColumnInt(4),
+			},
+			FieldCache: fieldCache,
 		}
 	}
 
@@ -255,13 +369,15 @@ func (db *DatabasePool[PluginConfig]) ReloadPluginConfig(ctx context.Context) er
 	if err != nil {
 		return err
 	}
+	// a plugin that the user didn't configure gets the zero value
+	if db._pluginConfig == nil {
+		db._pluginConfig = new(PluginConfig)
+	}
 	if hasRow {
 		result := stmt.ColumnText(0)
-		// Allocate a new PluginConfig if it is nil.
-		if db._pluginConfig == nil {
-			db._pluginConfig = new(PluginConfig)
+		if result == "" {
+			return nil
 		}
-		// Now unmarshal into the allocated value.
 		err = json.Unmarshal([]byte(result), db._pluginConfig)
 		if err != nil {
 			return err
@@ -273,6 +389,54 @@ func (db *DatabasePool[PluginConfig]) ReloadPluginConfig(ctx context.Context) er
 type TypeConfig struct {
 	ResolveQuery string
 	Keys         []string
+	// the cache rules for every field of the type
+	Cache CacheRules
+	// cache rules for individual fields, these take precedence over the type's rules
+	FieldCache map[string]CacheRules
+}
+
+// CacheRules describe how long a field's value can be trusted. The zero value means the
+// project defaults apply.
+type CacheRules struct {
+	Policy   string `json:"policy"`
+	Lifetime int    `json:"lifetime"`
+}
+
+// CacheRules returns the rules configured for the field of the given type (if any). Fields
+// with their own entry take precedence over the rules for the whole type. The fields that
+// identify a record never inherit the type's rules since expiring them would orphan the record.
+func (config ProjectConfig) CacheRules(typeName string, field string) CacheRules {
+	typeConfig, ok := config.TypeConfig[typeName]
+	if !ok {
+		return CacheRules{}
+	}
+
+	rules := typeConfig.Cache
+	if config.IdentifiesRecord(typeName, field) {
+		rules = CacheRules{}
+	}
+	if fieldRules, ok := typeConfig.FieldCache[field]; ok {
+		if fieldRules.Policy != "" {
+			rules.Policy = fieldRules.Policy
+		}
+		if fieldRules.Lifetime != 0 {
+			rules.Lifetime = fieldRules.Lifetime
+		}
+	}
+	return rules
+}
+
+// IdentifiesRecord returns true for the fields that make up a record's identity: __typename
+// and the keys of the type
+func (config ProjectConfig) IdentifiesRecord(typeName string, field string) bool {
+	if field == "__typename" {
+		return true
+	}
+	keys := config.DefaultKeys
+	if typeConfig, ok := config.TypeConfig[typeName]; ok && len(typeConfig.Keys) > 0 {
+		keys = typeConfig.Keys
+	}
+	return slices.Contains(keys, field)
 }
 
 type ScalarConfig struct {
@@ -0,0 +1,493 @@
+//go:build !wasip1
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is a static version of houdini.config.js that can be written as json or yaml.
+// It lets a project run codegen without node (see Host). Every key has the same name and
+// meaning as in the javascript config, but values that need javascript (functions, the
+// config modules that plugins contribute) are not supported.
+type ConfigFile struct {
+	SchemaPath                     string     `json:"schemaPath" yaml:"schemaPath"`
+	Include                        StringList `json:"include" yaml:"include"`
+	Exclude                        StringList `json:"exclude" yaml:"exclude"`
+	DefinitionsPath                string     `json:"definitionsPath" yaml:"definitionsPath"`
+	RuntimeDir                     string     `json:"runtimeDir" yaml:"runtimeDir"`
+	CacheBufferSize                *int       `json:"cacheBufferSize" yaml:"cacheBufferSize"`
+	DefaultCachePolicy             string     `json:"defaultCachePolicy" yaml:"defaultCachePolicy"`
+	DefaultPartial                 bool       `json:"defaultPartial" yaml:"defaultPartial"`
+	DefaultLifetime                *int       `json:"defaultLifetime" yaml:"defaultLifetime"`
+	DefaultListPosition            string     `json:"defaultListPosition" yaml:"defaultListPosition"`
+	DefaultListTarget              string     `json:"defaultListTarget" yaml:"defaultListTarget"`
+	DefaultPaginateMode            string     `json:"defaultPaginateMode" yaml:"defaultPaginateMode"`
+	SupressPaginationDeduplication bool       `json:"supressPaginationDeduplication" yaml:"supressPaginationDeduplication"`
+	LogLevel                       string     `json:"logLevel" yaml:"logLevel"`
+	DefaultFragmentMasking         string     `json:"defaultFragmentMasking" yaml:"defaultFragmentMasking"`
+	DefaultKeys                    []string   `json:"defaultKeys" yaml:"defaultKeys"`
+	PersistedQueriesPath           string     `json:"persistedQueriesPath" yaml:"persistedQueriesPath"`
+	DocumentUsage                  string     `json:"documentUsage" yaml:"documentUsage"`
+	// an empty string turns the artifact cache off (like false does in javascript)
+	CacheDir        *string                     `json:"cacheDir" yaml:"cacheDir"`
+	ReadIgnoreFiles IgnoreFileList              `json:"readIgnoreFiles" yaml:"readIgnoreFiles"`
+	FollowSymlinks  bool                        `json:"followSymlinks" yaml:"followSymlinks"`
+	Subscriptions   ConfigFileSubscriptions     `json:"subscriptions" yaml:"subscriptions"`
+	Scalars         map[string]ConfigFileScalar `json:"scalars" yaml:"scalars"`
+	RuntimeScalars  map[string]ConfigFileScalar `json:"runtimeScalars" yaml:"runtimeScalars"`
+	Types           map[string]ConfigFileType   `json:"types" yaml:"types"`
+	Plugins         map[string]map[string]any   `json:"plugins" yaml:"plugins"`
+	Router          ConfigFileRouter            `json:"router" yaml:"router"`
+
+	// the directory that holds the config file. Every path in the file is relative to it.
+	RootDir string `json:"-" yaml:"-"`
+	// the config file itself
+	Filepath string `json:"-" yaml:"-"`
+}
+
+type ConfigFileSubscriptions struct {
+	Transport  string            `json:"transport" yaml:"transport"`
+	Operations map[string]string `json:"operations" yaml:"operations"`
+}
+
+// ConfigFileRouter holds the values of the router that codegen needs. Node reads the endpoint
+// from src/server/+config, which needs javascript, so the file holds it here instead. Auth
+// stays with the server config since it carries secrets.
+type ConfigFileRouter struct {
+	// locale routing, checked by houdini-react
+	I18n     any    `json:"i18n" yaml:"i18n"`
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+}
+
+type ConfigFileScalar struct {
+	Type       string   `json:"type" yaml:"type"`
+	InputTypes []string `json:"inputTypes" yaml:"inputTypes"`
+	Module     string   `json:"module" yaml:"module"`
+	Default    bool     `json:"default" yaml:"default"`
+}
+
+type ConfigFileType struct {
+	Keys    []string `json:"keys" yaml:"keys"`
+	Resolve struct {
+		QueryField string `json:"queryField" yaml:"queryField"`
+	} `json:"resolve" yaml:"resolve"`
+	Cache struct {
+		Policy   string         `json:"policy" yaml:"policy"`
+		Lifetime *int           `json:"lifetime" yaml:"lifetime"`
+		Fields   map[string]any `json:"fields" yaml:"fields"`
+	} `json:"cache" yaml:"cache"`
+}
+
+// StringList is a value that can be a single string or a list of them (like include)
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = StringList{value.Value}
+		return nil
+	}
+	return value.Decode((*[]string)(l))
+}
+
+// IgnoreFileList is the value of readIgnoreFiles: true reads the default ignore files (an
+// empty list), a list reads the files with those names, and false leaves it nil
+type IgnoreFileList []string
+
+func (l *IgnoreFileList) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*l = nil
+		if enabled {
+			*l = IgnoreFileList{}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+func (l *IgnoreFileList) UnmarshalYAML(value *yaml.Node) error {
+	var enabled bool
+	if value.Kind == yaml.ScalarNode && value.Decode(&enabled) == nil {
+		*l = nil
+		if enabled {
+			*l = IgnoreFileList{}
+		}
+		return nil
+	}
+	return value.Decode((*[]string)(l))
+}
+
+// ConfigFileNames are the names LoadConfigFile looks for when it's given a directory
+var ConfigFileNames = []string{"houdini.config.json", "houdini.config.yaml", "houdini.config.yml"}
+
+// LoadConfigFile reads a config file and fills in the same defaults as the javascript
+// config. The path can also point to the directory that holds one of ConfigFileNames.
+func LoadConfigFile(path string) (ConfigFile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		found := ""
+		for _, name := range ConfigFileNames {
+			if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+				found = filepath.Join(path, name)
+				break
+			}
+		}
+		if found == "" {
+			return ConfigFile{}, fmt.Errorf(
+				"could not find a config file in %s. looked for %s",
+				path,
+				strings.Join(ConfigFileNames, ", "),
+			)
+		}
+		path = found
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+
+	config := ConfigFile{}
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(contents, &config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contents, &config)
+	default:
+		return config, fmt.Errorf("%s should be a .json, .yaml, or .yml file", path)
+	}
+	if err != nil {
+		return config, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	config.RootDir = filepath.Dir(path)
+	config.Filepath = path
+
+	// the defaults match default_config in the javascript package
+	if config.SchemaPath == "" {
+		config.SchemaPath = ".houdini/schema.graphql"
+	}
+	if len(config.Include) == 0 {
+		config.Include = StringList{"src/**/*"}
+	}
+	if config.Exclude == nil {
+		config.Exclude = StringList{}
+	}
+	if config.RuntimeDir == "" {
+		config.RuntimeDir = ".houdini"
+	}
+	if config.CacheBufferSize == nil {
+		size := 10
+		config.CacheBufferSize = &size
+	}
+	if config.DefaultKeys == nil {
+		config.DefaultKeys = []string{"id"}
+	}
+	if config.DefaultPaginateMode == "" {
+		config.DefaultPaginateMode = "Infinite"
+	}
+	if config.DefaultFragmentMasking == "" {
+		config.DefaultFragmentMasking = "enable"
+	}
+	if config.DefaultCachePolicy == "" {
+		config.DefaultCachePolicy = "CacheOrNetwork"
+	}
+	if config.PersistedQueriesPath == "" {
+		config.PersistedQueriesPath = filepath.Join(config.RuntimeDir, "queries.json")
+	}
+	if config.CacheDir == nil {
+		cacheDir := filepath.Join("node_modules", ".cache", "houdini")
+		config.CacheDir = &cacheDir
+	}
+
+	return config, nil
+}
+
+// PluginNames returns the plugins the config file asks for in the order they should run
+func (c ConfigFile) PluginNames() []string {
+	names := []string{}
+	for name := range c.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteConfigFile replaces the config in the database with the values of the file, the same
+// way the orchestrator writes houdini.config.js. Plugins get a row with their config so
+// it's waiting for them when they register.
+func WriteConfigFile[PluginConfig any](
+	ctx context.Context,
+	db DatabasePool[PluginConfig],
+	file ConfigFile,
+) error {
+	conn, err := db.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Put(conn)
+
+	commit := db.Transaction(conn)
+	err = writeConfigFile(conn, db, file)
+	commit(&err)
+	return err
+}
+
+func writeConfigFile[PluginConfig any](conn Conn, db DatabasePool[PluginConfig], file ConfigFile) error {
+	for _, table := range []string{
+		"config", "router_config", "scalar_config", "type_configs", "runtime_scalar_definitions",
+	} {
+		if err := ExecScript(conn, "DELETE FROM "+table); err != nil {
+			return err
+		}
+	}
+
+	include, err := json.Marshal(file.Include)
+	if err != nil {
+		return err
+	}
+	exclude, err := json.Marshal(file.Exclude)
+	if err != nil {
+		return err
+	}
+	defaultKeys, err := json.Marshal(file.DefaultKeys)
+	if err != nil {
+		return err
+	}
+	operations := file.Subscriptions.Operations
+	if operations == nil {
+		operations = map[string]string{}
+	}
+	transports, err := json.Marshal(operations)
+	if err != nil {
+		return err
+	}
+	var ignoreFiles any
+	if file.ReadIgnoreFiles != nil {
+		marshaled, err := json.Marshal(file.ReadIgnoreFiles)
+		if err != nil {
+			return err
+		}
+		ignoreFiles = string(marshaled)
+	}
+
+	insertConfig, err := conn.Prepare(`
+		INSERT INTO config (
+			include, exclude, schema_path, definitions_path, cache_buffer_size,
+			default_cache_policy, default_partial, default_lifetime,
+			default_list_position, default_list_target, default_paginate_mode,
+			suppress_pagination_deduplication, log_level, default_fragment_masking,
+			default_keys, persisted_queries_path, project_root, runtime_dir, path,
+			document_usage, subscription_transport, subscription_transports, cache_dir,
+			ignore_files, follow_symlinks
+		) VALUES (
+			$include, $exclude, $schema_path, $definitions_path, $cache_buffer_size,
+			$default_cache_policy, $default_partial, $default_lifetime,
+			$default_list_position, $default_list_target, $default_paginate_mode,
+			$suppress_pagination_deduplication, $log_level, $default_fragment_masking,
+			$default_keys, $persisted_queries_path, $project_root, $runtime_dir, $path,
+			$document_usage, $subscription_transport, $subscription_transports, $cache_dir,
+			$ignore_files, $follow_symlinks
+		)
+	`)
+	if err != nil {
+		return err
+	}
+	defer insertConfig.Finalize()
+	err = db.ExecStatement(insertConfig, map[string]any{
+		"include":                           string(include),
+		"exclude":                           string(exclude),
+		"schema_path":                       file.SchemaPath,
+		"definitions_path":                  file.DefinitionsPath,
+		"cache_buffer_size":                 optionalInt(file.CacheBufferSize),
+		"default_cache_policy":              optionalString(file.DefaultCachePolicy),
+		"default_partial":                   file.DefaultPartial,
+		"default_lifetime":                  optionalInt(file.DefaultLifetime),
+		"default_list_position":             optionalString(file.DefaultListPosition),
+		"default_list_target":               optionalString(file.DefaultListTarget),
+		"default_paginate_mode":             file.DefaultPaginateMode,
+		"suppress_pagination_deduplication": file.SupressPaginationDeduplication,
+		"log_level":                         optionalString(strings.ReplaceAll(strings.ToUpper(file.LogLevel), "-", "_")),
+		"default_fragment_masking":          file.DefaultFragmentMasking == "enable",
+		"default_keys":                      string(defaultKeys),
+		"persisted_queries_path":            file.PersistedQueriesPath,
+		"project_root":                      file.RootDir,
+		"runtime_dir":                       file.RuntimeDir,
+		"path":                              file.Filepath,
+		"document_usage":                    optionalString(file.DocumentUsage),
+		"subscription_transport":            optionalString(file.Subscriptions.Transport),
+		"subscription_transports":           string(transports),
+		"cache_dir":                         *file.CacheDir,
+		"ignore_files":                      ignoreFiles,
+		"follow_symlinks":                   file.FollowSymlinks,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the same row the orchestrator writes for a project without auth
+	var i18n any
+	if file.Router.I18n != nil {
+		marshaled, err := json.Marshal(file.Router.I18n)
+		if err != nil {
+			return err
+		}
+		i18n = string(marshaled)
+	}
+	insertRouter, err := conn.Prepare(`
+		INSERT INTO router_config (api_endpoint, redirect, session_keys, url, mutation, providers, i18n)
+		VALUES ($api_endpoint, NULL, '', NULL, NULL, NULL, $i18n)
+	`)
+	if err != nil {
+		return err
+	}
+	defer insertRouter.Finalize()
+	err = db.ExecStatement(insertRouter, map[string]any{
+		"api_endpoint": optionalString(file.Router.Endpoint),
+		"i18n":         i18n,
+	})
+	if err != nil {
+		return err
+	}
+
+	insertRuntimeScalar, err := conn.Prepare(
+		`INSERT INTO runtime_scalar_definitions (name, type) VALUES ($name, $type)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer insertRuntimeScalar.Finalize()
+	for name, scalar := range file.RuntimeScalars {
+		err = db.ExecStatement(insertRuntimeScalar, map[string]any{"name": name, "type": scalar.Type})
+		if err != nil {
+			return err
+		}
+	}
+
+	insertScalar, err := conn.Prepare(`
+		INSERT INTO scalar_config (name, type, input_types, module, default_import)
+		VALUES ($name, $type, $input_types, $module, $default_import)
+	`)
+	if err != nil {
+		return err
+	}
+	defer insertScalar.Finalize()
+	for name, scalar := range file.Scalars {
+		inputTypes, err := json.Marshal(append(scalar.InputTypes, name))
+		if err != nil {
+			return err
+		}
+		var defaultImport any
+		if scalar.Default {
+			defaultImport = true
+		}
+		err = db.ExecStatement(insertScalar, map[string]any{
+			"name":           name,
+			"type":           scalar.Type,
+			"input_types":    string(inputTypes),
+			"module":         optionalString(scalar.Module),
+			"default_import": defaultImport,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	insertType, err := conn.Prepare(`
+		INSERT INTO type_configs (name, keys, resolve_query, cache_policy, cache_lifetime, field_cache)
+		VALUES ($name, $keys, $resolve_query, $cache_policy, $cache_lifetime, $field_cache)
+	`)
+	if err != nil {
+		return err
+	}
+	defer insertType.Finalize()
+	for name, typeConfig := range file.Types {
+		keys := typeConfig.Keys
+		if len(keys) == 0 {
+			keys = file.DefaultKeys
+		}
+		keysJSON, err := json.Marshal(keys)
+		if err != nil {
+			return err
+		}
+		var fieldCache any
+		if typeConfig.Cache.Fields != nil {
+			marshaled, err := json.Marshal(typeConfig.Cache.Fields)
+			if err != nil {
+				return err
+			}
+			fieldCache = string(marshaled)
+		}
+		err = db.ExecStatement(insertType, map[string]any{
+			"name":           name,
+			"keys":           string(keysJSON),
+			"resolve_query":  optionalString(typeConfig.Resolve.QueryField),
+			"cache_policy":   optionalString(typeConfig.Cache.Policy),
+			"cache_lifetime": optionalInt(typeConfig.Cache.Lifetime),
+			"field_cache":    fieldCache,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// registering a plugin leaves its config alone so we can write it ahead of time
+	insertPlugin, err := conn.Prepare(`
+		INSERT INTO plugins (name, hooks, port, config) VALUES ($name, '[]', 0, $config)
+		ON CONFLICT(name) DO UPDATE SET config = excluded.config
+	`)
+	if err != nil {
+		return err
+	}
+	defer insertPlugin.Finalize()
+	for name, pluginConfig := range file.Plugins {
+		if pluginConfig == nil {
+			pluginConfig = map[string]any{}
+		}
+		marshaled, err := json.Marshal(pluginConfig)
+		if err != nil {
+			return err
+		}
+		err = db.ExecStatement(insertPlugin, map[string]any{"name": name, "config": string(marshaled)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func optionalString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func optionalInt(value *int) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
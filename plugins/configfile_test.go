@@ -0,0 +1,202 @@
+//go:build !wasip1
+
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	table := []struct {
+		name     string
+		filename string
+		content  string
+		check    func(t *testing.T, config ConfigFile)
+		error    string
+	}{
+		{
+			name:     "json",
+			filename: "houdini.config.json",
+			content: `{
+				"schemaPath": "api/schema.graphql",
+				"include": ["src/**/*.gql", "src/**/*.ts"],
+				"defaultKeys": ["uid"],
+				"readIgnoreFiles": [".gitignore", ".houdiniignore"],
+				"plugins": { "houdini-react": {} }
+			}`,
+			check: func(t *testing.T, config ConfigFile) {
+				require.Equal(t, "api/schema.graphql", config.SchemaPath)
+				require.Equal(t, StringList{"src/**/*.gql", "src/**/*.ts"}, config.Include)
+				require.Equal(t, []string{"uid"}, config.DefaultKeys)
+				require.Equal(t, IgnoreFileList{".gitignore", ".houdiniignore"}, config.ReadIgnoreFiles)
+				require.Equal(t, []string{"houdini-react"}, config.PluginNames())
+			},
+		},
+		{
+			name:     "yaml with a single include",
+			filename: "houdini.config.yaml",
+			content: `
+include: src/**/*
+runtimeDir: generated
+readIgnoreFiles: false
+plugins:
+  houdini-svelte:
+    framework: kit
+  houdini-react:
+`,
+			check: func(t *testing.T, config ConfigFile) {
+				require.Equal(t, StringList{"src/**/*"}, config.Include)
+				require.Equal(t, "generated", config.RuntimeDir)
+				require.Nil(t, config.ReadIgnoreFiles)
+				require.Equal(t, filepath.Join("generated", "queries.json"), config.PersistedQueriesPath)
+				require.Equal(t, map[string]any{"framework": "kit"}, config.Plugins["houdini-svelte"])
+				require.Equal(t, []string{"houdini-react", "houdini-svelte"}, config.PluginNames())
+			},
+		},
+		{
+			name:     "defaults",
+			filename: "houdini.config.yml",
+			content:  "{}",
+			check: func(t *testing.T, config ConfigFile) {
+				require.Equal(t, ".houdini/schema.graphql", config.SchemaPath)
+				require.Equal(t, StringList{"src/**/*"}, config.Include)
+				require.Equal(t, StringList{}, config.Exclude)
+				require.Equal(t, []string{"id"}, config.DefaultKeys)
+				require.Equal(t, "Infinite", config.DefaultPaginateMode)
+				require.Equal(t, "enable", config.DefaultFragmentMasking)
+				require.Equal(t, filepath.Join("node_modules", ".cache", "houdini"), *config.CacheDir)
+			},
+		},
+		{
+			name:     "unknown extension",
+			filename: "houdini.config.toml",
+			content:  "",
+			error:    "should be a .json, .yaml, or .yml file",
+		},
+		{
+			name:     "invalid content",
+			filename: "houdini.config.json",
+			content:  "{",
+			error:    "could not parse",
+		},
+	}
+
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, row.filename)
+			require.NoError(t, os.WriteFile(path, []byte(row.content), 0o644))
+
+			// the file is found when we only know the directory
+			target := dir
+			if filepath.Ext(row.filename) == ".toml" {
+				target = path
+			}
+
+			config, err := LoadConfigFile(target)
+			if row.error != "" {
+				require.ErrorContains(t, err, row.error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, dir, config.RootDir)
+			require.Equal(t, path, config.Filepath)
+			row.check(t, config)
+		})
+	}
+
+	_, err := LoadConfigFile(t.TempDir())
+	require.ErrorContains(t, err, "could not find a config file")
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "houdini.config.yaml"), []byte(`
+schemaPath: schema.graphql
+exclude: src/ignored/**
+defaultFragmentMasking: disable
+cacheDir: ""
+readIgnoreFiles: true
+followSymlinks: true
+subscriptions:
+  transport: sse
+  operations:
+    Live: graphql-ws
+runtimeScalars:
+  ViewerID:
+    type: ID
+types:
+  User:
+    keys: [uid]
+    resolve:
+      queryField: user
+router:
+  endpoint: /api/graphql
+  i18n:
+    locales: [en, fr]
+    strategy: prefix
+plugins:
+  houdini-svelte:
+    framework: kit
+`), 0o644)
+	require.NoError(t, err)
+	file, err := LoadConfigFile(dir)
+	require.NoError(t, err)
+
+	db, err := OpenMemoryPool[struct{}]()
+	require.NoError(t, err)
+	defer db.Close()
+	conn, err := db.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, CreateSchema(conn))
+	db.Put(conn)
+
+	// writing twice replaces the first config
+	require.NoError(t, WriteConfigFile(ctx, db, file))
+	require.NoError(t, WriteConfigFile(ctx, db, file))
+
+	config, err := db.ProjectConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, dir, config.ProjectRoot)
+	require.Equal(t, "schema.graphql", config.SchemaPath)
+	require.Equal(t, []string{"src/**/*"}, config.Include)
+	require.Equal(t, []string{"src/ignored/**"}, config.Exclude)
+	require.Equal(t, ".houdini", config.RuntimeDir)
+	require.False(t, config.DefaultFragmentMasking)
+	require.Equal(t, "", config.ArtifactCacheDirectory())
+	require.True(t, config.ReadIgnoreFiles)
+	require.Empty(t, config.IgnoreFiles)
+	require.True(t, config.FollowSymlinks)
+	require.Equal(t, "graphql-ws", config.TransportFor("Live"))
+	require.Equal(t, "sse", config.TransportFor("Other"))
+	require.Equal(t, map[string]string{"ViewerID": "ID"}, config.RuntimeScalars)
+	require.Equal(t, []string{"uid"}, config.TypeConfig["User"].Keys)
+	require.Equal(t, "user", config.TypeConfig["User"].ResolveQuery)
+
+	// the router's values end up where houdini-react reads them
+	routerRows := 0
+	endpoint, i18n := "", ""
+	err = db.StepQuery(ctx, `SELECT api_endpoint, i18n FROM router_config`, nil, func(row Row) {
+		routerRows++
+		endpoint, i18n = row.ColumnText(0), row.ColumnText(1)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, routerRows)
+	require.Equal(t, "/api/graphql", endpoint)
+	require.JSONEq(t, `{"locales": ["en", "fr"], "strategy": "prefix"}`, i18n)
+
+	// the plugin's config is waiting for it
+	pluginConfig := ""
+	err = db.StepQuery(ctx, `SELECT config FROM plugins WHERE name = 'houdini-svelte'`, nil, func(row Row) {
+		pluginConfig = row.ColumnText(0)
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"framework": "kit"}`, pluginConfig)
+}
@@ -324,13 +324,19 @@ func (db *DatabasePool[PC]) SetPluginConfig(config PC) {
 }
 
 func NewPool[PC any]() (DatabasePool[PC], error) {
+	return OpenPool[PC](databasePath)
+}
+
+// OpenPool connects to the database at the given path. Plugins get their path from the
+// -database flag (see NewPool); commands that inspect a project pass it explicitly.
+func OpenPool[PC any](path string) (DatabasePool[PC], error) {
 	// wasip1 has no file-locking support; nolock=1 tells SQLite to skip locking.
 	// Node.js skips WAL mode in stdio transport, so no shared-memory file is needed.
 	// foreign_keys is a per-connection pragma set through the driver's _pragma DSN
 	// parameter so it applies to every connection the pool opens, not just the
 	// first — without it the deferral set at each transaction start has nothing
 	// to defer.
-	uri := fmt.Sprintf("file:%s?nolock=1&_pragma=foreign_keys(1)", databasePath)
+	uri := fmt.Sprintf("file:%s?nolock=1&_pragma=foreign_keys(1)", path)
 	db, err := sql.Open("sqlite3", uri)
 	if err != nil {
 		return DatabasePool[PC]{}, err
@@ -338,7 +344,13 @@ func NewPool[PC any]() (DatabasePool[PC], error) {
 	// Single connection ensures SAVEPOINTs and PRAGMA defer_foreign_keys are
 	// visible to all subsequent queries on the same connection.
 	db.SetMaxOpenConns(1)
-	return DatabasePool[PC]{db: db}, nil
+	pool := DatabasePool[PC]{db: db}
+	// a database that's older than the plugin gets migrated, one we can't migrate is refused
+	if err := pool.Migrate(context.Background(), path); err != nil {
+		pool.Close()
+		return DatabasePool[PC]{}, err
+	}
+	return pool, nil
 }
 
 func NewTestPool[PC any]() (DatabasePool[PC], error) {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitex"
@@ -120,7 +121,13 @@ func prepareConn(conn *sqlite.Conn) error {
 }
 
 func NewPool[PC any]() (DatabasePool[PC], error) {
-	pool, err := sqlitex.NewPool(databasePath, sqlitex.PoolOptions{
+	return OpenPool[PC](databasePath)
+}
+
+// OpenPool connects to the database at the given path. Plugins get their path from the
+// -database flag (see NewPool); commands that inspect a project pass it explicitly.
+func OpenPool[PC any](path string) (DatabasePool[PC], error) {
+	pool, err := sqlitex.NewPool(path, sqlitex.PoolOptions{
 		Flags:       sqlite.OpenWAL | sqlite.OpenReadWrite,
 		PrepareConn: prepareConn,
 	})
@@ -128,6 +135,32 @@ func NewPool[PC any]() (DatabasePool[PC], error) {
 		return DatabasePool[PC]{}, err
 	}
 
+	db := DatabasePool[PC]{Pool: pool}
+	// a database that's older than the plugin gets migrated, one we can't migrate is refused
+	if err := db.Migrate(context.Background(), path); err != nil {
+		db.Close()
+		return DatabasePool[PC]{}, err
+	}
+	return db, nil
+}
+
+// memoryDatabases names the in-memory databases so pools opened by the same process don't
+// share one by accident
+var memoryDatabases atomic.Int64
+
+// OpenMemoryPool creates an empty database that lives as long as the pool. Every connection
+// in the pool sees the same database so it can be shared by plugins that run in the same
+// process (see Host). Nothing is written to disk.
+func OpenMemoryPool[PC any]() (DatabasePool[PC], error) {
+	uri := fmt.Sprintf("file:houdini-%d?mode=memory&cache=shared", memoryDatabases.Add(1))
+	pool, err := sqlitex.NewPool(uri, sqlitex.PoolOptions{
+		Flags:       sqlite.OpenReadWrite | sqlite.OpenCreate | sqlite.OpenMemory | sqlite.OpenURI,
+		PrepareConn: prepareConn,
+	})
+	if err != nil {
+		return DatabasePool[PC]{}, err
+	}
+
 	return DatabasePool[PC]{Pool: pool}, nil
 }
 
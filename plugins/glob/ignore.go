@@ -0,0 +1,144 @@
+package glob
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultIgnoreFiles are the files that list paths a project doesn't want tools to look at
+var DefaultIgnoreFiles = []string{".gitignore", ".ignore"}
+
+// ignoreRule is a single line of an ignore file
+type ignoreRule struct {
+	tree *patternTree
+	// the rule brings back a path that an earlier rule ignored
+	negate bool
+	// the rule only applies to directories (it ended with a slash)
+	dirOnly bool
+}
+
+// ignoreScope holds the rules of the ignore files in one directory. Rules apply to everything
+// below the directory and the rules of a nested directory win over the ones of its parents.
+type ignoreScope struct {
+	parent *ignoreScope
+	// the directory the rules were read from, split into tokens relative to the root of the walk
+	base  []string
+	rules []ignoreRule
+}
+
+// ignored returns true if the last rule that matches the path (from the outermost directory
+// to the innermost) ignores it. The tokens are relative to the root of the walk.
+func (s *ignoreScope) ignored(tokens []string, isDir bool) bool {
+	chain := []*ignoreScope{}
+	for scope := s; scope != nil; scope = scope.parent {
+		chain = append(chain, scope)
+	}
+
+	result := false
+	for i := len(chain) - 1; i >= 0; i-- {
+		scope := chain[i]
+		relative := tokens[len(scope.base):]
+		for _, rule := range scope.rules {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if matchHelper(rule.tree, relative) {
+				result = !rule.negate
+			}
+		}
+	}
+	return result
+}
+
+// readIgnoreFiles adds the rules of the ignore files in the directory to the scope of its
+// parent. The parent is returned as-is when the directory doesn't have any rules.
+func readIgnoreFiles(
+	fs afero.Fs,
+	dir string,
+	base []string,
+	names []string,
+	parent *ignoreScope,
+) (*ignoreScope, error) {
+	rules := []ignoreRule{}
+	for _, name := range names {
+		fp := filepath.Join(dir, name)
+		file, err := fs.Open(fp)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for line := 1; scanner.Scan(); line++ {
+			rule, ok, err := parseIgnoreRule(scanner.Text())
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("%s:%d: %w", fp, line, err)
+			}
+			if ok {
+				rules = append(rules, rule)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rules) == 0 {
+		return parent, nil
+	}
+	return &ignoreScope{parent: parent, base: base, rules: rules}, nil
+}
+
+// parseIgnoreRule turns a line of an ignore file into a rule using the same rules as git:
+// blank lines and comments are skipped, a leading ! negates the rule, a trailing slash only
+// matches directories and a pattern without a slash (other than a trailing one) matches at
+// any depth.
+func parseIgnoreRule(line string) (ignoreRule, bool, error) {
+	line = strings.TrimRight(line, "\r")
+	// trailing spaces are ignored unless they're escaped
+	if strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimSuffix(line, "\\ ") + " "
+	} else {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false, nil
+	}
+
+	rule := ignoreRule{tree: newPatternTree()}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false, nil
+	}
+
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+
+	if err := addPattern(rule.tree, line); err != nil {
+		return ignoreRule{}, false, err
+	}
+	return rule, true, nil
+}
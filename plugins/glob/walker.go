@@ -2,10 +2,13 @@ package glob
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
@@ -17,6 +20,10 @@ import (
 type Walker struct {
 	includeTree *patternTree
 	excludeTree *patternTree
+	// the names of the ignore files that Walk reads in every directory
+	ignoreFiles []string
+	// true if Walk goes through symbolic links
+	followSymlinks bool
 }
 
 // NewWalker creates a new walker with empty include and exclude pattern trees.
@@ -51,11 +58,122 @@ func (w *Walker) AddExclude(pattern string) error {
 	return nil
 }
 
+// ReadIgnoreFiles makes Walk honor ignore files with the given names (DefaultIgnoreFiles
+// when there are none). Like git, the rules of a file apply to the directory it's in and
+// everything below it, and a nested file can bring back what its parents ignored. Matches,
+// Excludes, and MayContain don't look at the filesystem so they don't know about these rules.
+func (w *Walker) ReadIgnoreFiles(names ...string) {
+	if len(names) == 0 {
+		names = DefaultIgnoreFiles
+	}
+	w.ignoreFiles = names
+}
+
+// FollowSymlinks makes Walk go through symbolic links. A link that points to one of the
+// directories it's in is skipped so cycles end. Every file is reported by its real path
+// (relative to the real root, so it can start with ..) and only once, no matter how many
+// links lead to it.
+func (w *Walker) FollowSymlinks() {
+	w.followSymlinks = true
+}
+
+// FollowsSymlinks returns true if Walk goes through symbolic links
+func (w *Walker) FollowsSymlinks() bool {
+	return w.followSymlinks
+}
+
+// Ignores returns true if the ignore files that Walk reads leave out the path (relative to
+// root) or one of the directories it's in. The files are read on every call so this is meant
+// for the odd path that changed, not for walking a tree.
+func (w *Walker) Ignores(fs afero.Fs, root string, fp string, isDir bool) (bool, error) {
+	if len(w.ignoreFiles) == 0 {
+		return false, nil
+	}
+
+	tokens := strings.Split(fp, "/")
+	var scope *ignoreScope
+	for i := range tokens {
+		dir := filepath.Join(root, filepath.FromSlash(strings.Join(tokens[:i], "/")))
+		var err error
+		scope, err = readIgnoreFiles(fs, dir, tokens[:i], w.ignoreFiles, scope)
+		if err != nil {
+			return false, err
+		}
+		// like Walk, nothing brings back a path inside of an ignored directory
+		if scope != nil && scope.ignored(tokens[:i+1], i < len(tokens)-1 || isDir) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ReportedPath turns a path relative to root into the one Walk reports for the same file. That's
+// the path itself unless the walker follows links. A file that's gone is resolved through its
+// directory and, if that's gone too, the path is returned as it is.
+func (w *Walker) ReportedPath(fs afero.Fs, root string, fp string) (string, error) {
+	if !w.followSymlinks {
+		return fp, nil
+	}
+
+	realRoot, err := RealPath(fs, root)
+	if err != nil {
+		return "", err
+	}
+	full := filepath.Join(root, filepath.FromSlash(fp))
+	real, err := RealPath(fs, full)
+	if errors.Is(err, os.ErrNotExist) {
+		dir, err := RealPath(fs, filepath.Dir(full))
+		if errors.Is(err, os.ErrNotExist) {
+			return fp, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		real = filepath.Join(dir, filepath.Base(full))
+	} else if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(realRoot, real)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
 func (w *Walker) Matches(fp string) bool {
 	target := strings.Split(fp, "/")
 	return matchHelper(w.includeTree, target) && !matchHelper(w.excludeTree, target)
 }
 
+// Excludes returns true if the path matches the exclude tree. Walk skips the whole subtree
+// of an excluded directory.
+func (w *Walker) Excludes(fp string) bool {
+	return matchHelper(w.excludeTree, strings.Split(fp, "/"))
+}
+
+// MayContain returns true if a file inside the directory could match the include tree.
+// It doesn't look at the exclude tree.
+func (w *Walker) MayContain(dir string) bool {
+	if dir == "" || dir == "." {
+		return len(w.includeTree.children) > 0
+	}
+	return prefixHelper(w.includeTree, strings.Split(dir, "/"))
+}
+
+// walkTask is a directory that's waiting to be read
+type walkTask struct {
+	// the path of the directory on the filesystem
+	dir string
+	// the path split into tokens relative to the root (nil for the root itself)
+	tokens []string
+	// the rules of the ignore files in the directories above
+	ignore *ignoreScope
+	// the real path of the directory and the ones above it. only tracked when following links
+	real      string
+	ancestors []string
+}
+
 // Walk traverses the filesystem in parallel starting at root.
 // for each file, it splits the relative path into tokens and
 // calls onFile if the path matches the include tree and does not match the exclude tree.
@@ -70,8 +188,8 @@ func (w *Walker) Walk(
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// workCh carries directory paths to process
-	workCh := make(chan string, 100000)
+	// workCh carries directories to process
+	workCh := make(chan walkTask, 100000)
 
 	// taskWG tracks the number of directories pending processing
 	var taskWG sync.WaitGroup
@@ -91,6 +209,115 @@ func (w *Walker) Walk(
 		})
 	}
 
+	// when following links, files are reported relative to the real root and the same
+	// file can be reached more than once
+	rootTask := walkTask{dir: root}
+	var reported sync.Map
+	if w.followSymlinks {
+		realRoot, err := RealPath(fs, root)
+		if err != nil {
+			return err
+		}
+		rootTask.real = realRoot
+		rootTask.ancestors = []string{realRoot}
+	}
+
+	// enqueue adds a directory to the queue
+	enqueue := func(task walkTask) {
+		taskWG.Add(1)
+		select {
+		case workCh <- task:
+			// Successfully enqueued, nothing more to do.
+		case <-ctx.Done():
+			// The context was canceled, so we need to balance the Add(1)
+			taskWG.Done()
+		}
+	}
+
+	// process reads a single directory
+	process := func(task walkTask) error {
+		ignore := task.ignore
+		if len(w.ignoreFiles) > 0 {
+			var err error
+			ignore, err = readIgnoreFiles(fs, task.dir, task.tokens, w.ignoreFiles, ignore)
+			if err != nil {
+				return err
+			}
+		}
+
+		// use afero.ReadDir to list directory entries
+		entries, err := afero.ReadDir(fs, task.dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			fullPath := filepath.Join(task.dir, entry.Name())
+			// the tokens of the path relative to the root
+			tokens := append(slices.Clip(task.tokens), entry.Name())
+
+			isDir := entry.IsDir()
+			real := ""
+			if w.followSymlinks {
+				real = filepath.Join(task.real, entry.Name())
+				if entry.Mode()&os.ModeSymlink != 0 {
+					real, err = RealPath(fs, fullPath)
+					if err != nil {
+						// a link that points nowhere doesn't have anything to walk
+						continue
+					}
+					info, err := fs.Stat(fullPath)
+					if err != nil {
+						continue
+					}
+					isDir = info.IsDir()
+				}
+			}
+
+			// an excluded or ignored directory is skipped along with everything inside of it
+			if matchHelper(w.excludeTree, tokens) {
+				continue
+			}
+			if ignore != nil && ignore.ignored(tokens, isDir) {
+				continue
+			}
+
+			// for directories: enqueue it for further processing.
+			if isDir {
+				child := walkTask{dir: fullPath, tokens: tokens, ignore: ignore}
+				if w.followSymlinks {
+					// a directory that we're already inside of would never end
+					if slices.Contains(task.ancestors, real) {
+						continue
+					}
+					child.real = real
+					child.ancestors = append(slices.Clip(task.ancestors), real)
+				}
+				enqueue(child)
+				continue
+			}
+
+			// for files: if the include tree matches, call onFile.
+			if !matchHelper(w.includeTree, tokens) {
+				continue
+			}
+			reportedPath := strings.Join(tokens, "/")
+			if w.followSymlinks {
+				if _, seen := reported.LoadOrStore(real, true); seen {
+					continue
+				}
+				rel, err := filepath.Rel(rootTask.real, real)
+				if err != nil {
+					return err
+				}
+				reportedPath = filepath.ToSlash(rel)
+			}
+			if err := onFile(reportedPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// worker is run by each goroutine; it processes directories from workCh.
 	worker := func() {
 		defer workerWG.Done()
@@ -98,56 +325,12 @@ func (w *Walker) Walk(
 			select {
 			case <-ctx.Done():
 				return
-			case dir, ok := <-workCh:
+			case task, ok := <-workCh:
 				if !ok {
 					return
 				}
-				// use afero.ReadDir to list directory entries
-				entries, err := afero.ReadDir(fs, dir)
-				if err != nil {
+				if err := process(task); err != nil {
 					setErr(err)
-					taskWG.Done()
-					continue
-				}
-				for _, entry := range entries {
-					fullPath := filepath.Join(dir, entry.Name())
-					rel, err := filepath.Rel(root, fullPath)
-					if err != nil {
-						setErr(err)
-						continue
-					}
-					if rel == "." {
-						rel = ""
-					}
-					// normalize path to use forward slashes and split into tokens
-					rel = filepath.ToSlash(rel)
-					tokens := strings.Split(rel, "/")
-					// for directories: if the exclude tree matches, skip this directory;
-					// otherwise, enqueue it for further processing.
-					if entry.IsDir() {
-						if matchHelper(w.excludeTree, tokens) {
-							continue
-						}
-						taskWG.Add(1)
-						select {
-						case workCh <- fullPath:
-							// Successfully enqueued, nothing more to do.
-						case <-ctx.Done():
-							// The context was canceled, so we need to balance the Add(1)
-							taskWG.Done()
-						}
-					} else {
-						// for files: if the exclude tree matches, skip;
-						// if the include tree matches, call onFile.
-						if matchHelper(w.excludeTree, tokens) {
-							continue
-						}
-						if matchHelper(w.includeTree, tokens) {
-							if err := onFile(rel); err != nil {
-								setErr(err)
-							}
-						}
-					}
 				}
 				taskWG.Done()
 			}
@@ -165,7 +348,7 @@ func (w *Walker) Walk(
 	taskWG.Add(1)
 	go func() {
 		select {
-		case workCh <- root:
+		case workCh <- rootTask:
 		case <-ctx.Done():
 		}
 	}()
@@ -181,6 +364,44 @@ func (w *Walker) Walk(
 	return finalErr
 }
 
+// RealPath resolves every symbolic link in the path. Filesystems that can't hold links
+// (like the in-memory one) return the path as it is.
+func RealPath(fs afero.Fs, fp string) (string, error) {
+	if _, ok := fs.(*afero.OsFs); ok {
+		abs, err := filepath.Abs(fp)
+		if err != nil {
+			return "", err
+		}
+		return filepath.EvalSymlinks(abs)
+	}
+
+	linker, ok := fs.(afero.Symlinker)
+	if !ok {
+		return filepath.Clean(fp), nil
+	}
+	// only the last part of the path can be a link we haven't seen, so follow that until
+	// we land on something else
+	current := filepath.Clean(fp)
+	for range 255 {
+		info, _, err := linker.LstatIfPossible(current)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return current, nil
+		}
+		target, err := linker.ReadlinkIfPossible(current)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(current), target)
+		}
+		current = filepath.Clean(target)
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", fp)
+}
+
 // -----------------------------------------------------------------------------
 // tokenMatcher interface and its implementations
 // -----------------------------------------------------------------------------
@@ -483,6 +704,24 @@ func matchHelper(tree *patternTree, tokens []string) bool {
 	return false
 }
 
+// prefixHelper returns true if the tokens can be the start of a path that the tree matches
+// with at least one more token.
+func prefixHelper(tree *patternTree, tokens []string) bool {
+	if len(tokens) == 0 {
+		return len(tree.children) > 0
+	}
+	for _, child := range tree.children {
+		// a globstar can swallow every remaining token
+		if child.matcher.String() == "**" {
+			return true
+		}
+		if child.matcher.Match(tokens[0]) && prefixHelper(child.node, tokens[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
 // -----------------------------------------------------------------------------
 // brace expansion helper
 // -----------------------------------------------------------------------------
@@ -2,6 +2,7 @@ package glob
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -363,3 +364,180 @@ func TestWalker_FileMatching(t *testing.T) {
 		})
 	}
 }
+
+// TestWalker_MayContain verifies that only directories that can hold an included file are
+// reported as worth looking inside.
+func TestWalker_MayContain(t *testing.T) {
+	walker := NewWalker()
+	for _, pattern := range []string{"src/{routes,lib}/**/*.gql", "schema/*.graphql"} {
+		if err := walker.AddInclude(pattern); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dirs := map[string]bool{
+		"":                     true,
+		"src":                  true,
+		"src/routes":           true,
+		"src/routes/deep/path": true,
+		"src/components":       false,
+		"schema":               true,
+		"schema/nested":        false,
+		"node_modules":         false,
+	}
+	for dir, expected := range dirs {
+		if got := walker.MayContain(dir); got != expected {
+			t.Errorf("MayContain(%q) = %v, expected %v", dir, got, expected)
+		}
+	}
+}
+
+// TestWalker_IgnoreFiles verifies that the rules of ignore files apply to their directory and
+// everything below it, and that nested files win over their parents.
+func TestWalker_IgnoreFiles(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	root := "/project"
+	paths := map[string]bool{
+		"src/index.gql":                   true,
+		"src/debug.log":                   false,
+		"src/generated/types.gql":         false,
+		"src/generated/keep.gql":          false, // the directory is ignored so it can't come back
+		"build/output.gql":                false,
+		"lib/build/nested.gql":            true, // only the build at the root is ignored
+		"lib/cache/cached.gql":            false,
+		"lib/cache.gql":                   true, // cache/ only matches directories
+		"packages/a/src/index.gql":        true,
+		"packages/a/src/fixtures.gql":     false,
+		"packages/a/src/draft.gql":        true, // brought back by the nested file
+		"packages/a/node_modules/lib.gql": false,
+	}
+	createTestFiles(t, memFs, root, paths)
+
+	ignoreFiles := map[string]string{
+		".gitignore": `
+# comments and blank lines are skipped
+
+*.log
+/build
+cache/
+src/generated
+!src/generated/keep.gql
+node_modules
+`,
+		"packages/a/.ignore":    "*.gql\n!index.gql\n!draft.gql",
+		"packages/a/.gitignore": "fixtures.gql\n",
+	}
+	for name, content := range ignoreFiles {
+		if err := afero.WriteFile(memFs, filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	walker := NewWalker()
+	walker.AddInclude("**/*")
+	walker.ReadIgnoreFiles()
+
+	visited := collectVisitedFiles(t, walker, memFs, root)
+	for path, expected := range paths {
+		if _, ok := visited.Load(path); ok != expected {
+			t.Errorf("file %q: got visited=%v, expected %v", path, ok, expected)
+		}
+		// a single path gets the same answer as the walk
+		ignored, err := walker.Ignores(memFs, root, path, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ignored == expected {
+			t.Errorf("file %q: got ignored=%v, expected %v", path, ignored, !expected)
+		}
+	}
+
+	// without asking for them, ignore files are just files
+	walker = NewWalker()
+	walker.AddInclude("**/*.log")
+	visited = collectVisitedFiles(t, walker, memFs, root)
+	if _, ok := visited.Load("src/debug.log"); !ok {
+		t.Error("expected ignore files to be skipped unless they're asked for")
+	}
+}
+
+// TestWalker_Symlinks verifies that links are followed, that cycles end, and that every file
+// is reported once by its real path.
+func TestWalker_Symlinks(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+	osFs := afero.NewOsFs()
+	createTestFiles(t, osFs, dir, map[string]bool{
+		"project/src/index.gql":        true,
+		"shared/src/fragments.gql":     true,
+		"project/src/nested/query.gql": true,
+	})
+
+	links := map[string]string{
+		// a workspace package that's linked twice
+		"project/node_modules/shared": "../../shared",
+		"project/src/shared":          "../../shared/src",
+		// a link back to a directory we're in
+		"project/src/nested/loop": "..",
+		// a link that points nowhere
+		"project/src/broken": "missing",
+		// a link to a single file
+		"project/src/linked.gql": "index.gql",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skipf("symlinks aren't supported: %v", err)
+		}
+	}
+
+	walker := NewWalker()
+	walker.AddInclude("**/*.gql")
+	walker.FollowSymlinks()
+
+	counts := map[string]int{}
+	var mu sync.Mutex
+	err := walker.Walk(context.Background(), osFs, root, func(fp string) error {
+		mu.Lock()
+		counts[fp]++
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{
+		"src/index.gql":               1,
+		"src/nested/query.gql":        1,
+		"../shared/src/fragments.gql": 1,
+	}
+	if len(counts) != len(expected) {
+		t.Errorf("got %v, expected %v", counts, expected)
+	}
+	for fp, count := range expected {
+		if counts[fp] != count {
+			t.Errorf("file %q: reported %d times, expected %d", fp, counts[fp], count)
+		}
+	}
+
+	// a path through a link turns into the one the walk reports, even once the file is gone
+	if err := os.Remove(filepath.Join(dir, "shared/src/fragments.gql")); err != nil {
+		t.Fatal(err)
+	}
+	reported := map[string]string{
+		"src/index.gql":                       "src/index.gql",
+		"src/linked.gql":                      "src/index.gql",
+		"src/shared/fragments.gql":            "../shared/src/fragments.gql",
+		"node_modules/shared/src/missing.gql": "../shared/src/missing.gql",
+		"src/gone/query.gql":                  "src/gone/query.gql",
+	}
+	for fp, expected := range reported {
+		got, err := walker.ReportedPath(osFs, root, fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("file %q: got reported path %q, expected %q", fp, got, expected)
+		}
+	}
+}
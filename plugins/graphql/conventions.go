@@ -60,6 +60,8 @@ const EndpointDirective = "endpoint"
 
 const SessionDirective = "session"
 
+const TransportDirective = "transport"
+
 const ListOperationSuffixInsert = "_insert"
 
 const ListOperationSuffixRemove = "_remove"
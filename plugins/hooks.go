@@ -23,6 +23,9 @@ func TriggerHookSerial[PluginConfig any](
 	if transportMode == "stdio" {
 		return StdioInvoke(ctx, hook, payload, false)
 	}
+	if transportMode == "inprocess" {
+		return hostInvoke(ctx, hook, payload, false)
+	}
 
 	// build up the result of invoking the hook on matching plugins
 	result := map[string]any{}
@@ -81,6 +84,9 @@ func TriggerHookParallel[PluginConfig any](
 	if transportMode == "stdio" {
 		return StdioInvoke(ctx, hook, payload, true)
 	}
+	if transportMode == "inprocess" {
+		return hostInvoke(ctx, hook, payload, true)
+	}
 
 	// build up the result of invoking the hook on matching plugins
 	result := map[string]any{}
@@ -151,6 +157,9 @@ func TriggerHookParallelWithConn[PluginConfig any](
 	if transportMode == "stdio" {
 		return StdioInvoke(ctx, hook, payload, true)
 	}
+	if transportMode == "inprocess" {
+		return hostInvoke(ctx, hook, payload, true)
+	}
 
 	// build up the result of invoking the hook on matching plugins
 	result := map[string]any{}
@@ -0,0 +1,391 @@
+//go:build !wasip1
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// PipelineHooks are the hooks of a full build in the order the orchestrator triggers them
+var PipelineHooks = []string{
+	"Config",
+	"AfterLoad",
+	"Schema",
+	"ExtractDocuments",
+	"AfterExtract",
+	"BeforeValidate",
+	"Validate",
+	"AfterValidate",
+	"BeforeGenerate",
+	"GenerateDocuments",
+	"GenerateRuntime",
+	"AfterGenerate",
+}
+
+// Host runs plugins inside the current process instead of spawning one process per plugin.
+// Every plugin shares one connection pool and hooks are called directly, so nothing goes
+// through the network or stdio. Together with an in-memory database this is the fastest way
+// to run a one-shot build (CI, for example) since nothing has to outlive the process.
+//
+// Only one host can be open at a time: while it's open, TriggerHookSerial and friends call
+// the hosted plugins instead of looking for plugin processes.
+type Host struct {
+	db      DatabasePool[struct{}]
+	plugins []*hostedPlugin
+}
+
+type hostedPlugin struct {
+	name      string
+	directory string
+	handlers  map[string]HookHandler
+}
+
+var (
+	activeHost   *Host
+	activeHostMu sync.Mutex
+)
+
+// NewHost opens the database that the hosted plugins share. An empty path keeps the
+// database in memory. Either way, the database is ready to use: a file is migrated like it
+// is when a plugin opens it and an in-memory database starts with the full schema.
+func NewHost(path string) (*Host, error) {
+	activeHostMu.Lock()
+	defer activeHostMu.Unlock()
+	if activeHost != nil {
+		return nil, errors.New("another host is already running in this process")
+	}
+
+	var db DatabasePool[struct{}]
+	var err error
+	if path == "" {
+		db, err = OpenMemoryPool[struct{}]()
+	} else {
+		// plugins never create the database but a host can run without the orchestrator
+		var file *os.File
+		file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err == nil {
+			file.Close()
+			db, err = OpenPool[struct{}](path)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := createSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	host := &Host{db: db}
+	activeHost = host
+	transportMode = "inprocess"
+	return host, nil
+}
+
+// createSchema adds whatever tables the database is missing. The schema only uses
+// IF NOT EXISTS so this is safe on a database that the orchestrator already built.
+func createSchema(db DatabasePool[struct{}]) error {
+	conn, err := db.Take(context.Background())
+	if err != nil {
+		return err
+	}
+	defer db.Put(conn)
+	return CreateSchema(conn)
+}
+
+// Database is the pool that every hosted plugin uses. The caller is responsible for
+// writing the project's config before the pipeline runs.
+func (h *Host) Database() DatabasePool[struct{}] {
+	return h.db
+}
+
+// Close releases the database. An in-memory database is gone after this.
+func (h *Host) Close() error {
+	activeHostMu.Lock()
+	if activeHost == h {
+		activeHost = nil
+		transportMode = "websocket"
+	}
+	activeHostMu.Unlock()
+
+	return h.db.Close()
+}
+
+// HostPlugin adds a plugin to the host and registers it in the database the same way a
+// plugin process does when it starts. The directory is where the plugin's package lives
+// (its runtime is copied from there). Hooks are triggered in the order plugins are added.
+func HostPlugin[PluginConfig any](
+	h *Host,
+	plugin HoudiniPlugin[PluginConfig],
+	directory string,
+) error {
+	ctx := context.Background()
+
+	db, hosted, hooks := attachPlugin(h, plugin, directory)
+	hooksStr, err := json.Marshal(hooks)
+	if err != nil {
+		return err
+	}
+
+	// the rest of the registration mirrors Run
+	var includeRuntime any
+	if includer, ok := plugin.(IncludeRuntime); ok {
+		includeRuntime, err = includer.IncludeRuntime(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var includeStaticRuntime any
+	if staticRuntime, ok := plugin.(StaticRuntime); ok {
+		includeStaticRuntime, err = staticRuntime.StaticRuntime(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var configModule any
+	if configurer, ok := plugin.(Config); ok {
+		configModule, err = configurer.Config(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var clientPlugins any
+	if clientProvider, ok := plugin.(ClientPlugins); ok {
+		pluginConfig, err := clientProvider.ClientPlugins(ctx)
+		if err != nil {
+			return err
+		}
+		stringified, err := json.Marshal(pluginConfig)
+		if err != nil {
+			return err
+		}
+		clientPlugins = string(stringified)
+	}
+
+	// there's no port since nothing has to reach the plugin over the network
+	err = db.ExecQuery(ctx,
+		`INSERT INTO plugins (
+			name, hooks, port, plugin_order, include_runtime, include_static_runtime, config_module, client_plugins
+		) VALUES
+			($name, $hooks, 0, $plugin_order, $include_runtime, $include_static_runtime, $config_module, $client_plugins)
+		ON CONFLICT(name) DO UPDATE SET
+			hooks = excluded.hooks,
+			port = excluded.port,
+			plugin_order = excluded.plugin_order,
+			include_runtime = excluded.include_runtime,
+			include_static_runtime = excluded.include_static_runtime,
+			config_module = excluded.config_module,
+			client_plugins = excluded.client_plugins`,
+		map[string]any{
+			"name":                   plugin.Name(),
+			"hooks":                  string(hooksStr),
+			"plugin_order":           string(plugin.Order()),
+			"include_runtime":        includeRuntime,
+			"include_static_runtime": includeStaticRuntime,
+			"config_module":          configModule,
+			"client_plugins":         clientPlugins,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	h.plugins = append(h.plugins, hosted)
+	return nil
+}
+
+// AttachPlugin adds a plugin to the host without registering it. It's meant for a database
+// that a full build already registered the plugin in: the rows describe that build and stay
+// as they are.
+func AttachPlugin[PluginConfig any](
+	h *Host,
+	plugin HoudiniPlugin[PluginConfig],
+	directory string,
+) {
+	_, hosted, _ := attachPlugin(h, plugin, directory)
+	h.plugins = append(h.plugins, hosted)
+}
+
+// attachPlugin points the plugin at the host's database and collects its hooks
+func attachPlugin[PluginConfig any](
+	h *Host,
+	plugin HoudiniPlugin[PluginConfig],
+	directory string,
+) (DatabasePool[PluginConfig], *hostedPlugin, []string) {
+	db := DatabasePool[PluginConfig]{Pool: h.db.Pool, PluginName: plugin.Name()}
+	plugin.SetDatabase(db)
+
+	hosted := &hostedPlugin{
+		name:      plugin.Name(),
+		directory: directory,
+		handlers:  map[string]HookHandler{},
+	}
+	hooks := registerPluginHooks(plugin, func(hookName string, handler HookHandler) {
+		hosted.handlers[hookName] = handler
+	})
+	return db, hosted, hooks
+}
+
+// TriggerHook calls the hook on every hosted plugin that implements it and returns their
+// results keyed by plugin name. Results go through json like they would over the wire so
+// callers see the same values in both modes.
+func (h *Host) TriggerHook(
+	ctx context.Context,
+	hook string,
+	payload map[string]any,
+	parallel bool,
+) (map[string]any, error) {
+	errs := &ErrorList{}
+	result := h.trigger(ctx, hook, payload, parallel, errs)
+	if errs.Len() > 0 {
+		return result, errs
+	}
+	return result, nil
+}
+
+func (h *Host) trigger(
+	ctx context.Context,
+	hook string,
+	payload map[string]any,
+	parallel bool,
+	errs *ErrorList,
+) map[string]any {
+	result := map[string]any{}
+	var resultMu sync.Mutex
+
+	invoke := func(plugin *hostedPlugin, handler HookHandler) bool {
+		var decoded any
+		value, err := handler(ContextWithPluginDir(ctx, plugin.directory), payload)
+		if err == nil {
+			var marshaled []byte
+			marshaled, err = json.Marshal(value)
+			if err == nil {
+				err = json.Unmarshal(marshaled, &decoded)
+			}
+		}
+		if err != nil {
+			appendHookError(errs, plugin.name, hook, err)
+			return false
+		}
+
+		resultMu.Lock()
+		result[plugin.name] = decoded
+		resultMu.Unlock()
+		return true
+	}
+
+	var wg sync.WaitGroup
+	for _, plugin := range h.plugins {
+		handler, ok := plugin.handlers[hook]
+		if !ok {
+			continue
+		}
+		if !parallel {
+			// like the orchestrator, a failing plugin stops the rest
+			if !invoke(plugin, handler) {
+				break
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			invoke(plugin, handler)
+		}()
+	}
+	wg.Wait()
+
+	return result
+}
+
+// appendHookError keeps the errors a plugin reports as they are so their locations survive.
+// Anything else gets the name of the plugin and hook so it can be traced back.
+func appendHookError(errs *ErrorList, plugin string, hook string, err error) {
+	var list *ErrorList
+	if errors.As(err, &list) {
+		for _, item := range list.GetItems() {
+			errs.Append(item)
+		}
+		return
+	}
+	prefixed := WrapError(err).WithPrefix(fmt.Sprintf("%s (%s)", plugin, hook))
+	errs.Append(&prefixed)
+}
+
+// PipelineOptions picks the part of the pipeline to run. The zero value runs everything.
+type PipelineOptions struct {
+	TaskID string
+	// the first hook to run
+	Start string
+	// the last hook to run
+	Through string
+}
+
+// RunPipeline triggers the pipeline hooks the same way the orchestrator does: Validate and
+// GenerateDocuments run every plugin at once and GenerateDocuments runs next to
+// GenerateRuntime since they don't depend on each other.
+func (h *Host) RunPipeline(ctx context.Context, options PipelineOptions) error {
+	start, end := 0, len(PipelineHooks)-1
+	for i, hook := range PipelineHooks {
+		if hook == options.Start {
+			start = i
+		}
+		if hook == options.Through {
+			end = i
+		}
+	}
+	if options.Start != "" && PipelineHooks[start] != options.Start {
+		return fmt.Errorf("unknown hook: %s", options.Start)
+	}
+	if options.Through != "" && PipelineHooks[end] != options.Through {
+		return fmt.Errorf("unknown hook: %s", options.Through)
+	}
+
+	ctx = ContextWithTaskID(ctx, options.TaskID)
+	for i := start; i <= end; i++ {
+		hook := PipelineHooks[i]
+
+		if hook == "GenerateDocuments" && i < end && PipelineHooks[i+1] == "GenerateRuntime" {
+			errs := &ErrorList{}
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				h.trigger(ctx, "GenerateDocuments", nil, true, errs)
+			}()
+			h.trigger(ctx, "GenerateRuntime", nil, false, errs)
+			wg.Wait()
+			if errs.Len() > 0 {
+				return errs
+			}
+			i++
+			continue
+		}
+
+		parallel := hook == "Validate" || hook == "GenerateDocuments"
+		if _, err := h.TriggerHook(ctx, hook, nil, parallel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hostInvoke is how plugins trigger hooks on each other while they're hosted
+func hostInvoke(ctx context.Context, hook string, payload map[string]any, parallel bool) (map[string]any, error) {
+	activeHostMu.Lock()
+	host := activeHost
+	activeHostMu.Unlock()
+	if host == nil {
+		return nil, fmt.Errorf("no host to trigger %s", hook)
+	}
+	return host.TriggerHook(ctx, hook, payload, parallel)
+}
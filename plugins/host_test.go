@@ -0,0 +1,176 @@
+//go:build !wasip1
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPlugin writes down every hook it sees
+type recordingPlugin struct {
+	Plugin[struct{}]
+	name  string
+	calls *[]string
+	mu    *sync.Mutex
+	// the hook that fails, if any
+	fail string
+}
+
+func (p *recordingPlugin) Name() string       { return p.name }
+func (p *recordingPlugin) Order() PluginOrder { return PluginOrderCore }
+
+func (p *recordingPlugin) record(ctx context.Context, hook string) error {
+	p.mu.Lock()
+	entry := p.name + " " + hook
+	if taskID := TaskIDFromContext(ctx); taskID != nil {
+		entry += " " + *taskID
+	}
+	*p.calls = append(*p.calls, entry)
+	p.mu.Unlock()
+
+	if hook == p.fail {
+		return errors.New("something went wrong")
+	}
+	return nil
+}
+
+func (p *recordingPlugin) Schema(ctx context.Context) error {
+	return p.record(ctx, "Schema")
+}
+
+func (p *recordingPlugin) Validate(ctx context.Context) error {
+	return p.record(ctx, "Validate")
+}
+
+func (p *recordingPlugin) AfterGenerate(ctx context.Context) error {
+	return p.record(ctx, "AfterGenerate")
+}
+
+func (p *recordingPlugin) Environment(ctx context.Context, mode string) (map[string]string, error) {
+	return map[string]string{"PLUGIN": p.name, "MODE": mode}, p.record(ctx, "Environment")
+}
+
+// triggeringPlugin asks the other plugins for their environment like a plugin process would
+type triggeringPlugin struct {
+	recordingPlugin
+	environment map[string]any
+}
+
+func (p *triggeringPlugin) AfterExtract(ctx context.Context) error {
+	result, err := TriggerHookParallel(ctx, p.DB, "Environment", map[string]any{"mode": "production"})
+	p.environment = result
+	return err
+}
+
+func TestHost(t *testing.T) {
+	for _, mode := range []string{"memory", "file"} {
+		t.Run(mode, func(t *testing.T) {
+			ctx := context.Background()
+
+			path := ""
+			if mode == "file" {
+				path = filepath.Join(t.TempDir(), "db.sqlite")
+			}
+			host, err := NewHost(path)
+			require.NoError(t, err)
+			defer host.Close()
+
+			// only one host can be open at a time
+			_, err = NewHost("")
+			require.Error(t, err)
+
+			calls := []string{}
+			mu := &sync.Mutex{}
+			first := &triggeringPlugin{
+				recordingPlugin: recordingPlugin{name: "first", calls: &calls, mu: mu},
+			}
+			second := &recordingPlugin{name: "second", calls: &calls, mu: mu}
+			require.NoError(t, HostPlugin[struct{}](host, first, "/first"))
+			require.NoError(t, HostPlugin[struct{}](host, second, "/second"))
+
+			// both plugins are registered in the database
+			registered := []string{}
+			err = host.Database().StepQuery(ctx, `SELECT name FROM plugins ORDER BY name`, nil, func(row Row) {
+				registered = append(registered, row.ColumnText(0))
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{"first", "second"}, registered)
+
+			// serial hooks run in the order the plugins were added
+			require.NoError(t, host.RunPipeline(ctx, PipelineOptions{Start: "Schema", Through: "Schema"}))
+			require.Equal(t, []string{"first Schema", "second Schema"}, calls)
+
+			// plugins can trigger hooks on each other and the results look like they came
+			// over the wire
+			calls = calls[:0]
+			require.NoError(t, host.RunPipeline(ctx, PipelineOptions{
+				TaskID:  "1",
+				Start:   "AfterExtract",
+				Through: "AfterExtract",
+			}))
+			require.ElementsMatch(t, []string{"first Environment 1", "second Environment 1"}, calls)
+			require.Equal(t, map[string]any{
+				"first":  map[string]any{"PLUGIN": "first", "MODE": "production"},
+				"second": map[string]any{"PLUGIN": "second", "MODE": "production"},
+			}, first.environment)
+
+			// the full pipeline reaches every hook
+			calls = calls[:0]
+			require.NoError(t, host.RunPipeline(ctx, PipelineOptions{}))
+			require.Contains(t, calls, "first Validate")
+			require.Contains(t, calls, "second Validate")
+			require.Equal(t, []string{"first AfterGenerate", "second AfterGenerate"}, calls[len(calls)-2:])
+
+			// a failing hook stops the pipeline and says where it came from
+			calls = calls[:0]
+			first.fail = "Schema"
+			err = host.RunPipeline(ctx, PipelineOptions{Start: "Schema"})
+			require.ErrorContains(t, err, "first (Schema): something went wrong")
+			require.Equal(t, []string{"first Schema"}, calls)
+
+			_, err = host.TriggerHook(ctx, "Unknown", nil, false)
+			require.NoError(t, err)
+			require.Error(t, host.RunPipeline(ctx, PipelineOptions{Through: "Unknown"}))
+		})
+	}
+}
+
+func TestAttachPlugin(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite")
+
+	// a full build registers the plugin with the port of its process
+	host, err := NewHost(path)
+	require.NoError(t, err)
+	require.NoError(t, host.Database().ExecQuery(ctx,
+		`INSERT INTO plugins (name, hooks, port, plugin_order) VALUES ('first', '["Schema"]', 4000, 'core')`,
+		nil,
+	))
+	require.NoError(t, host.Close())
+
+	host, err = NewHost(path)
+	require.NoError(t, err)
+	defer host.Close()
+
+	calls := []string{}
+	AttachPlugin[struct{}](host, &recordingPlugin{name: "first", calls: &calls, mu: &sync.Mutex{}}, "/first")
+
+	// the hooks run in this process
+	_, err = host.TriggerHook(ctx, "Schema", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"first Schema"}, calls)
+
+	// and the registration is left alone
+	var port int64
+	err = host.Database().StepQuery(ctx, `SELECT port FROM plugins WHERE name = 'first'`, nil, func(row Row) {
+		port = row.ColumnInt64(0)
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 4000, port)
+}
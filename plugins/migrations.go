@@ -0,0 +1,254 @@
+package plugins
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schema is the canonical orchestration database schema, generated from `create_schema` in
+// packages/houdini/src/lib/database.ts via `pnpm sync-schema`. Do not edit schema.sql by hand.
+//
+//go:embed schema.sql
+var schema string
+
+// migrationFiles are the migrations in packages/houdini/src/lib/database.ts, copied by
+// `pnpm sync-schema`. Do not edit them by hand.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// unversionedChecksum mirrors unversioned_checksum in database.ts: the PRAGMA user_version
+// that the compiler stamped before the schema_version table existed. A database with this
+// stamp is at version 0.
+const unversionedChecksum = 0x6443be8e
+
+// Migration brings a database from the version before it to its own
+type Migration struct {
+	Version     int
+	Description string
+	SQL         string
+}
+
+var migrations = loadMigrations()
+
+// Migrations returns every migration in the order they're applied
+func Migrations() []Migration {
+	return migrations
+}
+
+// SchemaVersion is the version of the database that the orchestrator creates
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersionError is returned when a database can't be brought up to the version that
+// the plugin understands
+type SchemaVersionError struct {
+	Path string
+	// the version of the database. -1 means it was written by a compiler too old to migrate
+	Version int
+}
+
+func (e *SchemaVersionError) Error() string {
+	if e.Version < 0 {
+		return fmt.Sprintf(
+			"the database at %s was created by a version of houdini that can't be migrated. run houdini generate to rebuild it",
+			e.Path,
+		)
+	}
+	return fmt.Sprintf(
+		"the database at %s is at schema version %d but this plugin only understands version %d. make sure every houdini package is on the same version",
+		e.Path, e.Version, SchemaVersion(),
+	)
+}
+
+// ReadSchemaVersion returns the version of the database. A database without any tables is
+// reported as the current version since there's nothing in it to be out of date. It returns
+// -1 if the database was written by a compiler too old to migrate from.
+func (db DatabasePool[PC]) ReadSchemaVersion(ctx context.Context) (int, error) {
+	tables := map[string]bool{}
+	err := db.StepQuery(ctx, `SELECT name FROM sqlite_master WHERE type = 'table'`, nil, func(row Row) {
+		tables[row.ColumnText(0)] = true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(tables) == 0 {
+		return SchemaVersion(), nil
+	}
+
+	version := -1
+	if tables["schema_version"] {
+		err = db.StepQuery(ctx, `SELECT MAX(version) FROM schema_version`, nil, func(row Row) {
+			if row.ColumnType(0) != ColumnKindNull {
+				version = row.ColumnInt(0)
+			}
+		})
+		return version, err
+	}
+
+	err = db.StepQuery(ctx, `PRAGMA user_version`, nil, func(row Row) {
+		if row.ColumnInt64(0) == unversionedChecksum {
+			version = 0
+		}
+	})
+	return version, err
+}
+
+// Migrate brings the database up to the current schema version. The orchestrator migrates
+// the database before it starts any plugins so this only does work when a plugin opens a
+// database on its own.
+func (db DatabasePool[PC]) Migrate(ctx context.Context, path string) error {
+	version, err := db.ReadSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < 0 || version > SchemaVersion() {
+		return &SchemaVersionError{Path: path, Version: version}
+	}
+	if version == SchemaVersion() {
+		return nil
+	}
+
+	conn, err := db.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Put(conn)
+
+	commit := db.Transaction(conn)
+	err = applyMigrations(conn, version)
+	commit(&err)
+	if err != nil {
+		return fmt.Errorf("failed to migrate the database at %s from version %d: %w", path, version, err)
+	}
+	return nil
+}
+
+func applyMigrations(conn Conn, from int) error {
+	for _, migration := range migrations {
+		if migration.Version <= from {
+			continue
+		}
+		if err := ExecScript(conn, migration.SQL); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		if err := recordMigration(conn, migration, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSchema builds every table of the orchestration database and marks it as up to date.
+// The orchestrator does this when it opens the database so it's only needed when the plugins
+// run without one (see Host).
+func CreateSchema(conn Conn) error {
+	if err := ExecScript(conn, schema); err != nil {
+		return err
+	}
+	return StampSchemaVersion(conn)
+}
+
+// StampSchemaVersion records that a database built from the current schema doesn't need any
+// of the migrations
+func StampSchemaVersion(conn Conn) error {
+	appliedAt := time.Now()
+	for _, migration := range migrations {
+		if err := recordMigration(conn, migration, appliedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordMigration(conn Conn, migration Migration, appliedAt time.Time) error {
+	// the table doesn't exist until the first migration has run so the statement can't be
+	// prepared ahead of time
+	stmt, err := conn.Prepare(`
+		INSERT OR IGNORE INTO schema_version (version, description, applied_at)
+		VALUES ($version, $description, $applied_at)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Finalize()
+	stmt.SetInt64("$version", int64(migration.Version))
+	stmt.SetText("$description", migration.Description)
+	stmt.SetInt64("$applied_at", appliedAt.UnixMilli())
+	_, err = stmt.Step()
+	return err
+}
+
+// ExecScript runs every statement in a SQL script
+func ExecScript(conn Conn, script string) error {
+	// strip -- line comments before splitting so semicolons inside comments
+	// (eg. "large table; index needed") don't split statements incorrectly.
+	for _, sql := range strings.Split(stripSQLComments(script), ";") {
+		sql = strings.TrimSpace(sql)
+		if sql == "" {
+			continue
+		}
+		stmt, err := conn.Prepare(sql)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Step(); err != nil {
+			stmt.Finalize()
+			return err
+		}
+		if err := stmt.Finalize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripSQLComments removes -- line comments from a SQL script.
+func stripSQLComments(s string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// loadMigrations reads the embedded migrations. Their names start with the version and the
+// first line is a comment with the description.
+func loadMigrations() []Migration {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		panic(err)
+	}
+
+	result := []Migration{}
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.SplitN(entry.Name(), "_", 2)[0])
+		if err != nil {
+			panic(fmt.Sprintf("migration %s doesn't start with its version", entry.Name()))
+		}
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		description, sql, _ := strings.Cut(string(content), "\n")
+		result = append(result, Migration{
+			Version:     version,
+			Description: strings.TrimPrefix(description, "-- "),
+			SQL:         sql,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result
+}
@@ -0,0 +1,4 @@
+-- read ignore files and follow symlinks
+
+ALTER TABLE config ADD COLUMN ignore_files JSON;
+ALTER TABLE config ADD COLUMN follow_symlinks BOOLEAN;
@@ -0,0 +1,11 @@
+-- version file fingerprints
+
+DROP TABLE IF EXISTS file_fingerprints;
+CREATE TABLE file_fingerprints (
+    filepath TEXT NOT NULL PRIMARY KEY,
+    mtime INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    documents INTEGER NOT NULL,
+    version INTEGER NOT NULL
+);
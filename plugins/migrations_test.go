@@ -0,0 +1,102 @@
+//go:build !wasip1
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+func TestOpenPool_SchemaVersion(t *testing.T) {
+	table := []struct {
+		name string
+		// the statements that build the database before it's opened
+		setup []string
+		// the version the database is at once it's open
+		expected int
+		// true if the database can't be opened
+		refused bool
+		// the version in the error when the database is refused
+		errorVersion int
+	}{
+		{
+			name:     "empty database",
+			expected: SchemaVersion(),
+		},
+		{
+			name: "current database",
+			setup: []string{
+				schema,
+				fmt.Sprintf(
+					"INSERT INTO schema_version (version, description, applied_at) VALUES (%d, 'current', 0)",
+					SchemaVersion(),
+				),
+			},
+			expected: SchemaVersion(),
+		},
+		{
+			name: "database from before versioning",
+			setup: []string{
+				"CREATE TABLE plugins (name TEXT)",
+				"CREATE TABLE config (include JSON NOT NULL)",
+				fmt.Sprintf("PRAGMA user_version = %d", unversionedChecksum),
+			},
+			expected: SchemaVersion(),
+		},
+		{
+			name: "database too old to migrate",
+			setup: []string{
+				"CREATE TABLE plugins (name TEXT)",
+				"PRAGMA user_version = 1234",
+			},
+			refused:      true,
+			errorVersion: -1,
+		},
+		{
+			name: "database from a newer compiler",
+			setup: []string{
+				schema,
+				fmt.Sprintf(
+					"INSERT INTO schema_version (version, description, applied_at) VALUES (%d, 'future', 0)",
+					SchemaVersion()+1,
+				),
+			},
+			refused:      true,
+			errorVersion: SchemaVersion() + 1,
+		},
+	}
+
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "db.sqlite")
+			conn, err := sqlite.OpenConn(path, sqlite.OpenReadWrite|sqlite.OpenCreate)
+			require.NoError(t, err)
+			for _, statement := range row.setup {
+				require.NoError(t, sqlitex.ExecuteScript(conn, statement, nil))
+			}
+			require.NoError(t, conn.Close())
+
+			db, err := OpenPool[struct{}](path)
+			if row.refused {
+				var versionErr *SchemaVersionError
+				require.True(t, errors.As(err, &versionErr), "expected a version error, got %v", err)
+				require.Equal(t, row.errorVersion, versionErr.Version)
+				require.Contains(t, err.Error(), path)
+				return
+			}
+			require.NoError(t, err)
+			defer db.Close()
+
+			version, err := db.ReadSchemaVersion(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, row.expected, version)
+		})
+	}
+}
@@ -1,4 +1,11 @@
 
+-- the migrations that brought the database to its current version (see migrations below)
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER NOT NULL PRIMARY KEY,
+    description TEXT NOT NULL,
+    applied_at INTEGER NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS plugins (
     name TEXT NOT NULL PRIMARY KEY UNIQUE,
     port INTEGER NOT NULL,
@@ -8,7 +15,8 @@ CREATE TABLE IF NOT EXISTS plugins (
     include_static_runtime TEXT,
     config JSON,
 	  config_module TEXT,
-		client_plugins JSON
+		client_plugins JSON,
+    version TEXT
 );
 
 -- Watch Schema Config
@@ -26,7 +34,8 @@ CREATE TABLE IF NOT EXISTS router_config (
     session_keys TEXT NOT NULL UNIQUE,
     url TEXT,
     mutation TEXT UNIQUE,
-    providers TEXT
+    providers TEXT,
+    i18n JSON
 );
 
 -- Runtime Scalar Definition
@@ -68,7 +77,13 @@ CREATE TABLE IF NOT EXISTS config (
     persisted_queries_path TEXT NOT NULL,
     project_root TEXT,
     runtime_dir TEXT,
-		path TEXT
+		path TEXT,
+    document_usage TEXT CHECK (document_usage IS NULL OR document_usage IN ('error', 'warn')),
+    subscription_transport TEXT,
+    subscription_transports JSON,
+    cache_dir TEXT,
+    ignore_files JSON,
+    follow_symlinks BOOLEAN
 );
 
 CREATE TABLE IF NOT EXISTS scalar_config (
@@ -83,7 +98,10 @@ CREATE TABLE IF NOT EXISTS scalar_config (
 CREATE TABLE IF NOT EXISTS type_configs (
     name TEXT NOT NULL,
     keys JSON NOT NULL,
-	resolve_query TEXT
+	resolve_query TEXT,
+	cache_policy TEXT,
+	cache_lifetime INTEGER,
+	field_cache JSON
 );
 
 -- A table of original document contents (to be populated by plugins)
@@ -97,6 +115,18 @@ CREATE TABLE IF NOT EXISTS raw_documents (
     loaded_with TEXT
 );
 
+-- What every file looked like the last time its documents were extracted. Files whose mtime and
+-- size (or content hash) still match are skipped by the next walk. A row only counts when its
+-- version matches the extractor that reads it.
+CREATE TABLE IF NOT EXISTS file_fingerprints (
+    filepath TEXT NOT NULL PRIMARY KEY,
+    mtime INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    documents INTEGER NOT NULL,
+    version INTEGER NOT NULL
+);
+
 -----------------------------------------------------------
 -- Schema Definition Tables
 -----------------------------------------------------------
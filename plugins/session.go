@@ -0,0 +1,241 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins/graphql"
+)
+
+// SessionWrite is a @session mutation along with the keys it writes to the session
+type SessionWrite struct {
+	Mutation string
+	Filepath string
+	Row      int
+	Column   int
+	// Path is the dotted @session path split into the result keys that lead to the session
+	Path  []string
+	Merge bool
+	// InTask is true when the mutation is part of the current task
+	InTask bool
+	// Fields are the keys of the session object, sorted by name
+	Fields []SessionField
+}
+
+// SessionField is a single key of the session and the GraphQL type that fills it
+type SessionField struct {
+	Name      string
+	Type      string
+	Modifiers string
+}
+
+// TypeString is the field's type in SDL form (e.g. [String!]!)
+func (f SessionField) TypeString() string {
+	opening := strings.Count(f.Modifiers, "]")
+	return strings.Repeat("[", opening) + f.Type + f.Modifiers
+}
+
+// LoadSessionWrites returns every @session mutation whose path resolves to a selection, sorted
+// by mutation name. Mutations with an invalid path are left out since validation reports them.
+func LoadSessionWrites[PluginConfig any](
+	ctx context.Context,
+	db DatabasePool[PluginConfig],
+) ([]SessionWrite, error) {
+	type selectionNode struct {
+		id        int
+		name      string
+		typeName  string
+		modifiers string
+	}
+
+	writes := []SessionWrite{}
+	documentIDs := []int{}
+	err := db.StepQuery(ctx, `
+		SELECT d.id, d.name, rd.filepath, dd.row, dd.column, av_path.raw, av_merge.raw,
+			(rd.current_task = $task_id OR $task_id IS NULL)
+		FROM documents d
+			JOIN raw_documents rd ON rd.id = d.raw_document
+			JOIN document_directives dd ON dd.document = d.id AND dd.directive = $session_directive
+			JOIN document_directive_arguments dda_path ON dda_path.parent = dd.id AND dda_path.name = 'path'
+			JOIN argument_values av_path ON av_path.id = dda_path.value
+			LEFT JOIN document_directive_arguments dda_merge ON dda_merge.parent = dd.id AND dda_merge.name = 'merge'
+			LEFT JOIN argument_values av_merge ON av_merge.id = dda_merge.value
+		WHERE d.kind = 'mutation'
+		ORDER BY d.name
+	`, map[string]any{"session_directive": graphql.SessionDirective}, func(row Row) {
+		path := []string{}
+		for _, segment := range strings.Split(row.ColumnText(5), ".") {
+			path = append(path, strings.TrimSpace(segment))
+		}
+		documentIDs = append(documentIDs, int(row.ColumnInt(0)))
+		writes = append(writes, SessionWrite{
+			Mutation: row.ColumnText(1),
+			Filepath: row.ColumnText(2),
+			Row:      int(row.ColumnInt(3)),
+			Column:   int(row.ColumnInt(4)),
+			Path:     path,
+			Merge:    row.ColumnText(6) == "true",
+			InTask:   row.ColumnBool(7),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the user's field selections of every @session document, indexed like the validator's
+	// tree: document then parent selection id (0 = root)
+	children := map[int]map[int][]selectionNode{}
+	err = db.StepQuery(ctx, `
+		SELECT
+			sr.document,
+			COALESCE(sr.parent_id, 0),
+			s.id,
+			COALESCE(s.alias, s.field_name),
+			COALESCE(tf.type, ''),
+			COALESCE(tf.type_modifiers, '')
+		FROM selection_refs sr
+			JOIN selections s ON s.id = sr.child_id
+			JOIN document_directives dd ON dd.document = sr.document AND dd.directive = $session_directive
+			LEFT JOIN type_fields tf ON s.type = tf.id
+		WHERE s.kind = 'field' AND NOT sr.internal
+		ORDER BY COALESCE(s.alias, s.field_name)
+	`, map[string]any{"session_directive": graphql.SessionDirective}, func(row Row) {
+		document := int(row.ColumnInt(0))
+		parent := int(row.ColumnInt(1))
+		if children[document] == nil {
+			children[document] = map[int][]selectionNode{}
+		}
+		children[document][parent] = append(children[document][parent], selectionNode{
+			id:        int(row.ColumnInt(2)),
+			name:      row.ColumnText(3),
+			typeName:  row.ColumnText(4),
+			modifiers: row.ColumnText(5),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []SessionWrite{}
+	for i, write := range writes {
+		tree := children[documentIDs[i]]
+		parent := 0
+		found := true
+		for _, segment := range write.Path {
+			found = false
+			for _, child := range tree[parent] {
+				if child.name == segment {
+					parent = child.id
+					found = true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		write.Fields = []SessionField{}
+		for _, child := range tree[parent] {
+			write.Fields = append(write.Fields, SessionField{
+				Name:      child.name,
+				Type:      child.typeName,
+				Modifiers: child.modifiers,
+			})
+		}
+		result = append(result, write)
+	}
+	return result, nil
+}
+
+// GenerateSessionType writes the Session type of a framework's runtime to targetPath. It has
+// a key for every field that a @session mutation writes to the session, typed from the result
+// of the mutation.
+func GenerateSessionType[PluginConfig any](
+	ctx context.Context,
+	db DatabasePool[PluginConfig],
+	fs afero.Fs,
+	targetPath string,
+) ([]string, error) {
+	writes, err := LoadSessionWrites(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	content := SessionTypeContent(writes)
+
+	existing, _ := afero.ReadFile(fs, targetPath)
+	if string(existing) == content {
+		return nil, nil
+	}
+	if err := fs.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := WriteFile(fs, targetPath, []byte(content), 0644); err != nil {
+		return nil, err
+	}
+	return []string{targetPath}, nil
+}
+
+// SessionTypeContent renders the module that exports the Session type
+func SessionTypeContent(writes []SessionWrite) string {
+	var b strings.Builder
+	b.WriteString("// this file is generated by houdini — do not edit\n")
+	if len(writes) == 0 {
+		b.WriteString("\n// no mutation writes to the session with @session\nexport type Session = {}\n")
+		return b.String()
+	}
+
+	for _, write := range writes {
+		fmt.Fprintf(&b, "import type { %s$result } from '$houdini/artifacts/%s'\n", write.Mutation, write.Mutation)
+	}
+	b.WriteString("\n")
+
+	// the value each mutation writes, found by following its path through the result
+	for _, write := range writes {
+		value := write.Mutation + "$result"
+		for _, segment := range write.Path {
+			value = fmt.Sprintf("NonNullable<%s['%s']>", value, segment)
+		}
+		how := "replaces"
+		if write.Merge {
+			how = "merges into"
+		}
+		fmt.Fprintf(&b, "// %s %s the session\ntype %sSession = %s\n", write.Mutation, how, write.Mutation, value)
+	}
+
+	// validation makes sure every mutation agrees on the type of a key so we can read it from
+	// whichever one writes it first
+	keys := map[string]string{}
+	names := []string{}
+	for _, write := range writes {
+		for _, field := range write.Fields {
+			if _, ok := keys[field.Name]; ok {
+				continue
+			}
+			keys[field.Name] = fmt.Sprintf("%sSession['%s']", write.Mutation, field.Name)
+			names = append(names, field.Name)
+		}
+	}
+
+	b.WriteString(`
+// Session holds every key a @session mutation can write. App.Session is an interface that
+// extends it and an interface can only extend an object type, so Session can't be a union of
+// the shape each mutation leaves behind. A mutation that replaces the session drops the keys
+// it doesn't write and the session starts out empty, so every key is optional.
+export type Session = {
+`)
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\t%s?: %s\n", name, keys[name])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
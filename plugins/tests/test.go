@@ -4,7 +4,6 @@ package tests
 
 import (
 	"bytes"
-	_ "embed"
 	"fmt"
 	"strings"
 	"code.houdinigraphql.com/plugins"
@@ -14,27 +13,7 @@ import (
 
 // WriteDatabaseSchema creates the database schema.
 func WriteDatabaseSchema(conn plugins.Conn) error {
-	// strip -- line comments before splitting so semicolons inside comments
-	// (eg. "large table; index needed") don't split statements incorrectly.
-	statements := strings.Split(stripSQLComments(schema), ";")
-	for _, sql := range statements {
-		sql = strings.TrimSpace(sql)
-		if sql == "" {
-			continue
-		}
-		s, err := conn.Prepare(sql)
-		if err != nil {
-			return err
-		}
-		if _, err := s.Step(); err != nil {
-			s.Finalize()
-			return err
-		}
-		if err := s.Finalize(); err != nil {
-			return err
-		}
-	}
-	return nil
+	return plugins.CreateSchema(conn)
 }
 
 // InsertRawDocument inserts a raw_documents row with the given id so fixtures
@@ -55,26 +34,6 @@ func InsertRawDocument(conn plugins.Conn, id int, filepath string, content strin
 	return err
 }
 
-// schema is the canonical orchestration database schema, generated from
-// `create_schema` in packages/houdini/src/lib/database.ts via `pnpm sync-schema`.
-// Do not edit schema.sql by hand.
-//
-//go:embed schema.sql
-var schema string
-
-// stripSQLComments removes -- line comments from a SQL script.
-func stripSQLComments(s string) string {
-	var b strings.Builder
-	for _, line := range strings.Split(s, "\n") {
-		if i := strings.Index(line, "--"); i >= 0 {
-			line = line[:i]
-		}
-		b.WriteString(line)
-		b.WriteByte('\n')
-	}
-	return b.String()
-}
-
 // expectedDocument represents an operation or fragment definition.
 type ExpectedDocument struct {
 	Name          string
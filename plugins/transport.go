@@ -2,6 +2,11 @@
 
 package plugins
 
+import (
+	"context"
+	"fmt"
+)
+
 // wasip1 always uses stdio — no flag parsing occurs in the WASM runtime.
 var transportMode = "stdio"
 
@@ -15,3 +20,8 @@ func cmp(a, b string) string {
 	}
 	return b
 }
+
+// hostInvoke is never called on wasip1 since plugins can't be hosted there (see Host).
+func hostInvoke(ctx context.Context, hook string, payload map[string]any, parallel bool) (map[string]any, error) {
+	return nil, fmt.Errorf("plugins can't be hosted on wasip1: could not trigger %s", hook)
+}
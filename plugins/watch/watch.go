@@ -0,0 +1,347 @@
+//go:build !wasip1
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+
+	"code.houdinigraphql.com/plugins/glob"
+)
+
+// Batch holds the files that changed while the watcher waited for things to settle. Paths are
+// relative to the watched root (through the links the walker follows) and use forward slashes.
+type Batch struct {
+	// the files that were created or written
+	Changed []string
+	// the files that were removed or moved away
+	Deleted []string
+}
+
+// Empty returns true when nothing in the batch changed
+func (b Batch) Empty() bool {
+	return len(b.Changed) == 0 && len(b.Deleted) == 0
+}
+
+// Tree is another directory whose files are watched along with the root's, like a dependency
+// that ships documents. Its walker matches paths relative to the directory.
+type Tree struct {
+	// the directory relative to the root, with forward slashes. It can start with ..
+	Directory string
+	Walker    *glob.Walker
+}
+
+// tree is a watched directory and the walker that decides which of its files are included
+type tree struct {
+	// the absolute path of the directory
+	dir string
+	// the directory relative to the root. Files are reported with it in front of their path
+	prefix string
+	walker *glob.Walker
+}
+
+// Watcher reports changes to the files under a directory that a glob.Walker includes. Events
+// are collected until nothing has happened for the debounce window so a save that touches a
+// file a few times, or a checkout that touches hundreds, shows up as a single batch.
+type Watcher struct {
+	debounce time.Duration
+	notify   *fsnotify.Watcher
+	// the root comes first, then the other trees
+	trees []*tree
+
+	// the included files we know about, so removing a directory can report what was inside
+	known map[string]bool
+	// the state of every file that changed since the last batch. true means it's gone
+	pending map[string]bool
+	// the real paths of the linked directories we watch, so two links to the same directory
+	// are only watched once
+	linked map[string]bool
+}
+
+// New starts watching every directory under root that could hold an included file, and every
+// directory of the other trees that could hold one of theirs
+func New(root string, walker *glob.Walker, debounce time.Duration, trees ...Tree) (*Watcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		debounce: debounce,
+		notify:   notify,
+		trees:    []*tree{{dir: filepath.Clean(root), walker: walker}},
+		known:    map[string]bool{},
+		pending:  map[string]bool{},
+	}
+	for _, t := range trees {
+		w.trees = append(w.trees, &tree{
+			dir:    filepath.Join(root, filepath.FromSlash(t.Directory)),
+			prefix: t.Directory,
+			walker: t.Walker,
+		})
+	}
+	if err := w.watchRoot(false); err != nil {
+		notify.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching the filesystem
+func (w *Watcher) Close() error {
+	return w.notify.Close()
+}
+
+// Run calls onBatch with every batch of changes until the context is canceled. Changes that
+// happen while onBatch runs are reported in the next batch.
+func (w *Watcher) Run(ctx context.Context, onBatch func(context.Context, Batch)) error {
+	settle := time.NewTimer(w.debounce)
+	settle.Stop()
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return nil
+			}
+			if w.handle(event) {
+				settle.Reset(w.debounce)
+			}
+
+		case err, ok := <-w.notify.Errors:
+			if !ok {
+				return nil
+			}
+			// the kernel dropped events so we can't know what changed. every included file
+			// goes into the next batch and the pipeline skips the ones that are the same
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return err
+			}
+			if err := w.watchRoot(true); err != nil {
+				return err
+			}
+			settle.Reset(w.debounce)
+
+		case <-settle.C:
+			batch := w.flush()
+			if !batch.Empty() {
+				onBatch(ctx, batch)
+			}
+		}
+	}
+}
+
+// handle records a single event and returns true if it touched an included file
+func (w *Watcher) handle(event fsnotify.Event) bool {
+	t, rel, ok := w.locate(event.Name)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := os.Stat(event.Name)
+		if err != nil {
+			return false
+		}
+		if info.IsDir() {
+			// the directory could have been filled before we started watching it
+			before := len(w.pending)
+			if t.walker.Excludes(rel) || !t.walker.MayContain(rel) || t.ignores(rel, true) {
+				return false
+			}
+			if link, err := os.Lstat(event.Name); err == nil && link.Mode()&fs.ModeSymlink != 0 {
+				if !t.walker.FollowsSymlinks() {
+					return false
+				}
+				_ = w.watchLink(t, event.Name, true)
+			} else {
+				_ = w.watchTree(t, event.Name, true)
+			}
+			return len(w.pending) != before
+		}
+		return w.changed(t, rel)
+
+	case event.Has(fsnotify.Write):
+		return w.changed(t, rel)
+
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// a rename only tells us about the old name. the new one shows up as a create
+		reported := t.reported(rel)
+		touched := false
+		if w.known[reported] {
+			w.deleted(reported)
+			touched = true
+		}
+		// the path could have been a directory
+		prefix := reported + "/"
+		for fp := range w.known {
+			if strings.HasPrefix(fp, prefix) {
+				w.deleted(fp)
+				touched = true
+			}
+		}
+		return touched
+	}
+
+	return false
+}
+
+func (w *Watcher) changed(t *tree, rel string) bool {
+	if !t.walker.Matches(rel) || t.ignores(rel, false) {
+		return false
+	}
+	reported := t.reported(rel)
+	w.known[reported] = true
+	w.pending[reported] = false
+	return true
+}
+
+func (w *Watcher) deleted(rel string) {
+	delete(w.known, rel)
+	w.pending[rel] = true
+}
+
+// flush turns the pending changes into a batch
+func (w *Watcher) flush() Batch {
+	batch := Batch{}
+	for fp, gone := range w.pending {
+		if gone {
+			batch.Deleted = append(batch.Deleted, fp)
+		} else {
+			batch.Changed = append(batch.Changed, fp)
+		}
+	}
+	sort.Strings(batch.Changed)
+	sort.Strings(batch.Deleted)
+	w.pending = map[string]bool{}
+	return batch
+}
+
+// watchRoot watches every directory under the root, and under the other trees, that could
+// hold an included file
+func (w *Watcher) watchRoot(report bool) error {
+	w.linked = map[string]bool{}
+	for _, t := range w.trees {
+		if err := w.watchTree(t, t.dir, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchTree watches every directory under dir that could hold a file the tree includes. When
+// report is true, the included files it finds are added to the next batch.
+func (w *Watcher) watchTree(t *tree, dir string, report bool) error {
+	return filepath.WalkDir(dir, func(fp string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// the tree can change while we walk it
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, ok := t.relative(fp)
+		if !ok && fp != t.dir {
+			return nil
+		}
+
+		if entry.IsDir() {
+			if fp != t.dir && (t.walker.Excludes(rel) || !t.walker.MayContain(rel) || t.ignores(rel, true)) {
+				return filepath.SkipDir
+			}
+			return w.notify.Add(filepath.Clean(fp))
+		}
+
+		// WalkDir doesn't go through links so we walk the directories they point to ourselves
+		if entry.Type()&fs.ModeSymlink != 0 && t.walker.FollowsSymlinks() {
+			if info, err := os.Stat(fp); err == nil && info.IsDir() {
+				if t.walker.Excludes(rel) || !t.walker.MayContain(rel) || t.ignores(rel, true) {
+					return nil
+				}
+				return w.watchLink(t, fp, report)
+			}
+		}
+
+		if t.walker.Matches(rel) && !t.ignores(rel, false) {
+			reported := t.reported(rel)
+			w.known[reported] = true
+			if report {
+				w.pending[reported] = false
+			}
+		}
+		return nil
+	})
+}
+
+// watchLink watches the directory that a link points to unless another link already led
+// there or it's one of the directories the link is in
+func (w *Watcher) watchLink(t *tree, fp string, report bool) error {
+	real, err := filepath.EvalSymlinks(fp)
+	if err != nil {
+		return nil
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(fp))
+	if err != nil {
+		return nil
+	}
+	if w.linked[real] || parent == real || strings.HasPrefix(parent, real+string(filepath.Separator)) {
+		return nil
+	}
+	w.linked[real] = true
+
+	// the trailing separator makes WalkDir go through the link
+	return w.watchTree(t, fp+string(filepath.Separator), report)
+}
+
+// locate finds the tree that a file belongs to and its path inside of it. A tree inside of
+// another one (a package in the project's node_modules) owns its files.
+func (w *Watcher) locate(fp string) (*tree, string, bool) {
+	var found *tree
+	var inside string
+	for _, t := range w.trees {
+		rel, ok := t.relative(fp)
+		if ok && (found == nil || len(t.dir) > len(found.dir)) {
+			found, inside = t, rel
+		}
+	}
+	return found, inside, found != nil
+}
+
+// ignores returns true if the ignore files leave out the path. An ignore file that can't be
+// read doesn't stop the watcher, the path is treated like any other.
+func (t *tree) ignores(rel string, isDir bool) bool {
+	ignored, err := t.walker.Ignores(afero.NewOsFs(), t.dir, rel, isDir)
+	return err == nil && ignored
+}
+
+// relative computes the path of a file inside the tree the way its walker expects it
+func (t *tree) relative(fp string) (string, bool) {
+	rel, err := filepath.Rel(t.dir, fp)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// reported is the path a batch uses for a file of the tree: relative to the root
+func (t *tree) reported(rel string) string {
+	if t.prefix == "" {
+		return rel
+	}
+	return path.Join(t.prefix, rel)
+}
@@ -0,0 +1,170 @@
+//go:build !wasip1
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code.houdinigraphql.com/plugins/glob"
+)
+
+func TestWatcher(t *testing.T) {
+	table := []struct {
+		name string
+		// files that exist before the watcher starts
+		existing []string
+		// prepares the project and the walker before the watcher starts
+		setup func(t *testing.T, root string, walker *glob.Walker)
+		// the other directories to watch
+		trees    func(t *testing.T, root string) []Tree
+		change   func(t *testing.T, root string)
+		expected Batch
+	}{
+		{
+			name: "writes are collected into one batch",
+			change: func(t *testing.T, root string) {
+				writeFile(t, root, "src/query.gql", "query A { version }")
+				writeFile(t, root, "src/query.gql", "query B { version }")
+				writeFile(t, root, "src/fragment.gql", "fragment C on User { id }")
+			},
+			expected: Batch{Changed: []string{"src/fragment.gql", "src/query.gql"}},
+		},
+		{
+			name: "files the walker doesn't include are ignored",
+			change: func(t *testing.T, root string) {
+				writeFile(t, root, "src/notes.txt", "hello")
+				writeFile(t, root, "src/generated/query.gql", "query A { version }")
+				writeFile(t, root, "other/query.gql", "query A { version }")
+				writeFile(t, root, "src/query.gql", "query A { version }")
+			},
+			expected: Batch{Changed: []string{"src/query.gql"}},
+		},
+		{
+			name: "files in new directories are reported",
+			change: func(t *testing.T, root string) {
+				writeFile(t, root, "src/routes/nested/query.gql", "query A { version }")
+			},
+			expected: Batch{Changed: []string{"src/routes/nested/query.gql"}},
+		},
+		{
+			name: "files the ignore files leave out are skipped",
+			setup: func(t *testing.T, root string, walker *glob.Walker) {
+				writeFile(t, root, ".gitignore", "src/drafts\n*.local.gql\n")
+				walker.ReadIgnoreFiles()
+			},
+			change: func(t *testing.T, root string) {
+				writeFile(t, root, "src/drafts/query.gql", "query A { version }")
+				writeFile(t, root, "src/query.local.gql", "query A { version }")
+				writeFile(t, root, "src/query.gql", "query A { version }")
+			},
+			expected: Batch{Changed: []string{"src/query.gql"}},
+		},
+		{
+			name: "files in linked directories",
+			setup: func(t *testing.T, root string, walker *glob.Walker) {
+				shared := filepath.Join(filepath.Dir(root), "shared")
+				require.NoError(t, os.MkdirAll(shared, 0755))
+				if err := os.Symlink(shared, filepath.Join(root, "src", "shared")); err != nil {
+					t.Skipf("symlinks aren't supported: %v", err)
+				}
+				walker.FollowSymlinks()
+			},
+			change: func(t *testing.T, root string) {
+				writeFile(t, filepath.Dir(root), "shared/fragment.gql", "fragment C on User { id }")
+			},
+			expected: Batch{Changed: []string{"src/shared/fragment.gql"}},
+		},
+		{
+			name: "files in other trees are reported relative to the root",
+			trees: func(t *testing.T, root string) []Tree {
+				writeFile(t, filepath.Dir(root), "node_modules/design-system/package.json", "{}")
+				walker := glob.NewWalker()
+				require.NoError(t, walker.AddInclude("src/**/*.gql"))
+				return []Tree{{Directory: "../node_modules/design-system", Walker: walker}}
+			},
+			change: func(t *testing.T, root string) {
+				writeFile(t, filepath.Dir(root), "node_modules/design-system/src/avatar.gql", "fragment A on User { id }")
+				writeFile(t, filepath.Dir(root), "node_modules/design-system/README.md", "hello")
+				writeFile(t, root, "src/query.gql", "query A { version }")
+			},
+			expected: Batch{Changed: []string{"../node_modules/design-system/src/avatar.gql", "src/query.gql"}},
+		},
+		{
+			name:     "removed files",
+			existing: []string{"src/query.gql", "src/other.gql"},
+			change: func(t *testing.T, root string) {
+				require.NoError(t, os.Remove(filepath.Join(root, "src", "query.gql")))
+			},
+			expected: Batch{Deleted: []string{"src/query.gql"}},
+		},
+		{
+			name:     "moved directories",
+			existing: []string{"src/routes/query.gql", "src/routes/nested/fragment.gql"},
+			change: func(t *testing.T, root string) {
+				require.NoError(t, os.Rename(
+					filepath.Join(root, "src", "routes"),
+					filepath.Join(root, "src", "pages"),
+				))
+			},
+			expected: Batch{
+				Changed: []string{"src/pages/nested/fragment.gql", "src/pages/query.gql"},
+				Deleted: []string{"src/routes/nested/fragment.gql", "src/routes/query.gql"},
+			},
+		},
+	}
+
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "project")
+			require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0755))
+			for _, fp := range row.existing {
+				writeFile(t, root, fp, "# existing")
+			}
+
+			walker := glob.NewWalker()
+			require.NoError(t, walker.AddInclude("src/**/*.gql"))
+			require.NoError(t, walker.AddExclude("src/generated/**"))
+			if row.setup != nil {
+				row.setup(t, root, walker)
+			}
+
+			trees := []Tree{}
+			if row.trees != nil {
+				trees = row.trees(t, root)
+			}
+
+			watcher, err := New(root, walker, 50*time.Millisecond, trees...)
+			require.NoError(t, err)
+			defer watcher.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			batches := make(chan Batch, 10)
+			go watcher.Run(ctx, func(ctx context.Context, batch Batch) {
+				batches <- batch
+			})
+
+			row.change(t, root)
+
+			select {
+			case batch := <-batches:
+				require.Equal(t, row.expected, batch)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for a batch")
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, root string, fp string, content string) {
+	t.Helper()
+	full := filepath.Join(root, filepath.FromSlash(fp))
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+	require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+}